@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/core"
+	"agentic/commerce/internal/domains/apikey"
+	"agentic/commerce/internal/infrastructure/auth"
+	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/internal/infrastructure/tenancy"
+	"agentic/commerce/pkg/specs/api"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+// apiKeyActor is the created_by and updated_by of the keys managed from the CLI.
+const apiKeyActor = "cli"
+
+var (
+	apiKeyTenant  string
+	apiKeyName    string
+	apiKeyScopes  []string
+	apiKeyTTL     time.Duration
+	apiKeyRevoked bool
+
+	apiKeyCMD = &cobra.Command{
+		Use:   "apikey",
+		Short: "Manage the API keys of machine clients",
+		Long:  `Issue, list, rotate and revoke the API keys batch jobs send in the X-API-Key header, as an administrator of the tenant`,
+	}
+
+	apiKeyIssueCMD = &cobra.Command{
+		Use:   "issue",
+		Short: "Issue an API key and print it, it is not shown again",
+		Args:  cobra.NoArgs,
+		RunE:  apiKeyIssue,
+	}
+
+	apiKeyListCMD = &cobra.Command{
+		Use:   "list",
+		Short: "List the API keys of the tenant",
+		Args:  cobra.NoArgs,
+		RunE:  apiKeyList,
+	}
+
+	apiKeyRotateCMD = &cobra.Command{
+		Use:   "rotate <id>",
+		Short: "Issue a replacement for a key, the old one keeps working for the rotation grace",
+		Args:  cobra.ExactArgs(1),
+		RunE:  apiKeyRotate,
+	}
+
+	apiKeyRevokeCMD = &cobra.Command{
+		Use:   "revoke <id>",
+		Short: "Revoke a key at once",
+		Args:  cobra.ExactArgs(1),
+		RunE:  apiKeyRevoke,
+	}
+)
+
+func init() {
+	apiKeyCMD.PersistentFlags().StringVar(&apiKeyTenant, "tenant", "", "Tenant of the keys (the default tenant if not specified)")
+
+	apiKeyIssueCMD.Flags().StringVar(&apiKeyName, "name", "", "Name of the client the key is for")
+	apiKeyIssueCMD.Flags().StringSliceVar(&apiKeyScopes, "scope", nil, "Scope to grant, repeatable")
+	apiKeyIssueCMD.Flags().DurationVar(&apiKeyTTL, "ttl", 0, "How long the key is valid (the configured default if not specified)")
+	_ = apiKeyIssueCMD.MarkFlagRequired("name")
+
+	apiKeyListCMD.Flags().BoolVar(&apiKeyRevoked, "all", false, "Include the revoked keys")
+
+	apiKeyCMD.AddCommand(apiKeyIssueCMD, apiKeyListCMD, apiKeyRotateCMD, apiKeyRevokeCMD)
+	rootCMD.AddCommand(apiKeyCMD)
+}
+
+func apiKeyIssue(cmd *cobra.Command, _ []string) error {
+	return withAPIKeyService(cmd.Context(), func(ctx context.Context, service apikey.IAPIKeyService) error {
+		req := &api.APIKeyIssueRequest{Name: apiKeyName, Scopes: apiKeyScopes}
+		if apiKeyTTL > 0 {
+			req.ExpiresAt = lo.ToPtr(time.Now().Add(apiKeyTTL))
+		}
+		resp, err := service.IssueAPIKey(ctx, req)
+		if err != nil {
+			return err
+		}
+		printIssuedAPIKey(resp)
+		return nil
+	})
+}
+
+func apiKeyList(cmd *cobra.Command, _ []string) error {
+	return withAPIKeyService(cmd.Context(), func(ctx context.Context, service apikey.IAPIKeyService) error {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tSCOPES\tEXPIRES\tLAST USED\tSTATUS")
+		for page := 1; ; page++ {
+			resp, err := service.ListAPIKeys(ctx, &api.APIKeyListRequest{IncludeRevoked: apiKeyRevoked, Page: page, Size: 100})
+			if err != nil {
+				return err
+			}
+			for _, key := range resp.Items {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", key.ID, key.Name, strings.Join(key.Scopes, " "),
+					formatAPIKeyTime(key.ExpiresAt, "never"), formatAPIKeyTime(key.LastUsedAt, "-"), apiKeyStatus(key))
+			}
+			if page >= int(resp.TotalPage) {
+				break
+			}
+		}
+		return w.Flush()
+	})
+}
+
+func apiKeyRotate(cmd *cobra.Command, args []string) error {
+	return withAPIKeyService(cmd.Context(), func(ctx context.Context, service apikey.IAPIKeyService) error {
+		resp, err := service.RotateAPIKey(ctx, &api.APIKeyIDAwareRequest{ID: args[0]})
+		if err != nil {
+			return err
+		}
+		printIssuedAPIKey(resp)
+		return nil
+	})
+}
+
+func apiKeyRevoke(cmd *cobra.Command, args []string) error {
+	return withAPIKeyService(cmd.Context(), func(ctx context.Context, service apikey.IAPIKeyService) error {
+		if err := service.RevokeAPIKey(ctx, &api.APIKeyIDAwareRequest{ID: args[0]}); err != nil {
+			return err
+		}
+		fmt.Printf("🚫 Revoked %s\n", args[0])
+		return nil
+	})
+}
+
+// printIssuedAPIKey prints the key alone on stdout, for scripts, and the rest on stderr.
+func printIssuedAPIKey(resp *api.APIKeyIssueResponse) {
+	fmt.Fprintf(os.Stderr, "🔑 Issued %s (%s), expires %s; store it now, it is not shown again\n",
+		resp.ID, resp.Name, formatAPIKeyTime(resp.ExpiresAt, "never"))
+	fmt.Println(resp.Key)
+}
+
+func formatAPIKeyTime(t *time.Time, none string) string {
+	if t == nil {
+		return none
+	}
+	return t.Local().Format(time.DateTime)
+}
+
+func apiKeyStatus(key api.APIKeyItemResponse) string {
+	switch {
+	case key.RevokedAt != nil:
+		return "revoked"
+	case key.ExpiresAt != nil && !time.Now().Before(*key.ExpiresAt):
+		return "expired"
+	case key.ReplacedBy != "":
+		return "rotated to " + key.ReplacedBy
+	default:
+		return "active"
+	}
+}
+
+// withAPIKeyService builds the graph like seed and hands fn the service with a context
+// acting as the system administrator of the tenant.
+func withAPIKeyService(ctx context.Context, fn func(ctx context.Context, service apikey.IAPIKeyService) error) error {
+	cfg, err := readCommandConfig()
+	if err != nil {
+		return err
+	}
+
+	var db *gorm.DB
+	var service apikey.IAPIKeyService
+
+	bootstrap := fx.New(commandModules(ctx, cfg,
+		withDomains,
+		fx.Populate(&db, &service),
+	))
+	if err := bootstrap.Err(); err != nil {
+		return err
+	}
+	defer func() {
+		_ = database.ShutdownGormDB(db)
+	}()
+
+	ctx = auth.WithPrincipal(core.WithActor(ctx, apiKeyActor), auth.System())
+	if tenant := apiKeyTenantOf(cfg); tenant != "" {
+		ctx = tenancy.WithTenant(ctx, tenant)
+	}
+	return fn(ctx, service)
+}
+
+func apiKeyTenantOf(cfg *config.Config) string {
+	if apiKeyTenant != "" {
+		return apiKeyTenant
+	}
+	if cfg.Tenancy != nil {
+		return cfg.Tenancy.DefaultTenant
+	}
+	return ""
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"agentic/commerce/internal/infrastructure/migration"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+)
+
+var (
+	migrateUpSteps   int
+	migrateDownSteps int
+	migrateDir       string
+	migrateAsGo      bool
+
+	migrateCMD = &cobra.Command{
+		Use:   "migrate",
+		Short: "Manage the database schema",
+		Long:  `Apply, roll back and inspect the versioned migrations of the migrations package`,
+	}
+
+	migrateUpCMD = &cobra.Command{
+		Use:   "up",
+		Short: "Apply pending migrations",
+		Args:  cobra.NoArgs,
+		RunE:  migrateUp,
+	}
+
+	migrateDownCMD = &cobra.Command{
+		Use:   "down",
+		Short: "Roll back the last applied migrations",
+		Args:  cobra.NoArgs,
+		RunE:  migrateDown,
+	}
+
+	migrateStatusCMD = &cobra.Command{
+		Use:   "status",
+		Short: "List migrations and whether they are applied",
+		Args:  cobra.NoArgs,
+		RunE:  migrateStatus,
+	}
+
+	migrateCreateCMD = &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a new empty migration",
+		Args:  cobra.ExactArgs(1),
+		RunE:  migrateCreate,
+	}
+)
+
+func init() {
+	migrateUpCMD.Flags().IntVarP(&migrateUpSteps, "steps", "n", 0, "Number of migrations to apply (all when 0)")
+	migrateDownCMD.Flags().IntVarP(&migrateDownSteps, "steps", "n", 1, "Number of migrations to roll back")
+	migrateCreateCMD.Flags().StringVarP(&migrateDir, "dir", "d", "migrations", "Directory of the migrations package")
+	migrateCreateCMD.Flags().BoolVar(&migrateAsGo, "go", false, "Create a Go migration instead of SQL files")
+
+	migrateCMD.AddCommand(migrateUpCMD, migrateDownCMD, migrateStatusCMD, migrateCreateCMD)
+	rootCMD.AddCommand(migrateCMD)
+}
+
+func migrateUp(cmd *cobra.Command, _ []string) error {
+	return withMigrator(cmd.Context(), func(ctx context.Context, migrator *migration.Migrator) error {
+		applied, err := migrator.Up(ctx, migrateUpSteps)
+		for _, m := range applied {
+			fmt.Printf("⬆️  applied %s_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("Schema is up to date")
+		}
+		return err
+	})
+}
+
+func migrateDown(cmd *cobra.Command, _ []string) error {
+	return withMigrator(cmd.Context(), func(ctx context.Context, migrator *migration.Migrator) error {
+		reverted, err := migrator.Down(ctx, migrateDownSteps)
+		for _, m := range reverted {
+			fmt.Printf("⬇️  rolled back %s_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Println("Nothing to roll back")
+		}
+		return err
+	})
+}
+
+func migrateStatus(cmd *cobra.Command, _ []string) error {
+	return withMigrator(cmd.Context(), func(ctx context.Context, migrator *migration.Migrator) error {
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+		for _, s := range statuses {
+			state := "pending"
+			switch {
+			case s.Unknown:
+				state = "applied " + s.AppliedAt.Format(time.RFC3339) + " (unknown to this build)"
+			case s.AppliedAt != nil:
+				state = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", s.Version, s.Name, state)
+		}
+		return w.Flush()
+	})
+}
+
+func migrateCreate(_ *cobra.Command, args []string) error {
+	paths, err := migration.Create(migrateDir, args[0], migrateAsGo, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		fmt.Println("created", path)
+	}
+	return nil
+}
+
+// withMigrator starts the database part of the application only, runs fn and stops it.
+func withMigrator(ctx context.Context, fn func(ctx context.Context, migrator *migration.Migrator) error) error {
+	cfg, err := readCommandConfig()
+	if err != nil {
+		return err
+	}
+
+	var migrator *migration.Migrator
+	bootstrap := fx.New(commandModules(ctx, cfg, fx.Populate(&migrator)))
+	if err := bootstrap.Start(ctx); err != nil {
+		return err
+	}
+	defer func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		_ = bootstrap.Stop(stopCtx)
+	}()
+
+	return fn(ctx, migrator)
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"agentic/commerce/internal/app"
+	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/internal/infrastructure/partition"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+var (
+	partitionCMD = &cobra.Command{
+		Use:   "partition",
+		Short: "Manage the tables partitioned by month",
+		Long:  `Inspect and maintain the Postgres tables registered with partition.AsMonthly; the server does the same in the background`,
+	}
+
+	partitionListCMD = &cobra.Command{
+		Use:   "list",
+		Short: "List the partitions of every partitioned table",
+		Args:  cobra.NoArgs,
+		RunE:  partitionList,
+	}
+
+	partitionMaintainCMD = &cobra.Command{
+		Use:   "maintain",
+		Short: "Create the upcoming partitions and detach the expired ones once",
+		Args:  cobra.NoArgs,
+		RunE:  partitionMaintain,
+	}
+)
+
+func init() {
+	partitionCMD.AddCommand(partitionListCMD, partitionMaintainCMD)
+	rootCMD.AddCommand(partitionCMD)
+}
+
+func partitionList(cmd *cobra.Command, _ []string) error {
+	return withPartitionMaintainer(cmd.Context(), func(ctx context.Context, maintainer *partition.Maintainer) error {
+		partitions, err := maintainer.Partitions(ctx)
+		if err != nil {
+			return err
+		}
+		if len(partitions) == 0 {
+			fmt.Println("No partitioned tables, partitioning is Postgres only")
+			return nil
+		}
+
+		tables := make([]string, 0, len(partitions))
+		for table := range partitions {
+			tables = append(tables, table)
+		}
+		sort.Strings(tables)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TABLE\tPARTITION\tMONTH\tROWS (EST.)")
+		for _, table := range tables {
+			for _, p := range partitions[table] {
+				month := "-"
+				if p.Default {
+					month = "default"
+				} else if !p.Month.IsZero() {
+					month = p.Month.Format("2006-01")
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", table, p.Name, month, p.Rows)
+			}
+		}
+		return w.Flush()
+	})
+}
+
+func partitionMaintain(cmd *cobra.Command, _ []string) error {
+	return withPartitionMaintainer(cmd.Context(), func(ctx context.Context, maintainer *partition.Maintainer) error {
+		reports, err := maintainer.Maintain(ctx)
+		for _, report := range reports {
+			for _, name := range report.Created {
+				fmt.Printf("➕ %s: created %s\n", report.Table, name)
+			}
+			for _, name := range report.Detached {
+				fmt.Printf("📦 %s: detached %s\n", report.Table, name)
+			}
+			for _, name := range report.Dropped {
+				fmt.Printf("🗑️  %s: dropped %s\n", report.Table, name)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if len(reports) == 0 {
+			fmt.Println("No partitioned tables, partitioning is Postgres only")
+			return nil
+		}
+		fmt.Printf("✅ %d partitioned tables up to date\n", len(reports))
+		return nil
+	})
+}
+
+// withPartitionMaintainer builds the graph like schema diff, without starting the
+// background maintainer, and hands fn the maintainer.
+func withPartitionMaintainer(ctx context.Context, fn func(ctx context.Context, maintainer *partition.Maintainer) error) error {
+	cfg, err := readCommandConfig()
+	if err != nil {
+		return err
+	}
+
+	var db *gorm.DB
+	var maintainer *partition.Maintainer
+
+	bootstrap := fx.New(commandModules(ctx, cfg,
+		withDomains,
+		app.PartitionModule,
+		fx.Populate(&db, &maintainer),
+	))
+	if err := bootstrap.Err(); err != nil {
+		return err
+	}
+	defer func() {
+		_ = database.ShutdownGormDB(db)
+	}()
+
+	return fn(ctx, maintainer)
+}
@@ -1,12 +1,19 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"agentic/commerce/config"
 	"agentic/commerce/internal/app"
+	"agentic/commerce/internal/domains"
+	internalhttp "agentic/commerce/internal/interfaces/http"
 
 	"github.com/spf13/cobra"
+	"go.uber.org/fx"
 )
 
 var (
@@ -21,30 +28,81 @@ var (
 )
 
 func init() {
-	cobra.OnInitialize(initialize)
-
 	rootCMD.PersistentFlags().StringVarP(&configPath, "config", "c", "config.yml", "Path of config file (using the default config if not specified)")
 
 	rootCMD.AddCommand(serveCMD)
 }
 
-func initialize() {
-	fmt.Println(app.Banner())
-}
-
-func preRun(_ *cobra.Command, _ []string) {
-	fmt.Println("Starting up application...")
+// preRun prints the banner to the output of cmd, which a command whose stdout carries
+// data points at stderr first.
+func preRun(cmd *cobra.Command, _ []string) {
+	reserveStdout(cmd)
+	fmt.Fprintln(cmd.OutOrStdout(), app.Banner())
+	fmt.Fprintln(cmd.OutOrStdout(), "Starting up application...")
 }
 
-func postRun(_ *cobra.Command, _ []string) error {
-	fmt.Println("Shutting down application...")
+func postRun(cmd *cobra.Command, _ []string) error {
+	fmt.Fprintln(cmd.OutOrStdout(), "Shutting down application...")
 	return nil
 }
 
 // Execute executes the root command.
 func Execute() {
-	err := rootCMD.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := rootCMD.ExecuteContext(ctx)
 	if err != nil {
-		_, _ = fmt.Fprint(os.Stderr, err.Error())
+		_, _ = fmt.Fprintln(os.Stderr, err.Error())
+		stop()
+		os.Exit(1)
+	}
+}
+
+// supplyContext makes the command's context available to constructors, cancelled on
+// SIGINT/SIGTERM.
+func supplyContext(ctx context.Context) fx.Option {
+	return fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context))))
+}
+
+// commandModules is the graph of a command that doesn't serve requests: the config and
+// the database part of the application, with options added. Unless the command starts
+// it, only the database is connected.
+func commandModules(ctx context.Context, cfg *config.Config, options ...fx.Option) fx.Option {
+	return fx.Options(append([]fx.Option{
+		fx.Supply(cfg),
+		supplyContext(ctx),
+		config.Module,
+		app.LoggerModule,
+		app.MetricsModule,
+		app.DatabaseModule,
+		fx.NopLogger,
+	}, options...)...)
+}
+
+// withDomains adds the domain modules, registering the entities, and what they depend
+// on to commandModules. Such a graph is built but never started: the relay and the
+// HTTP server must not run.
+var withDomains = fx.Options(
+	app.AuthModule,
+	app.CacheModule,
+	app.EventBusModule,
+	domains.Modules,
+	app.OutboxModule,
+	fx.Provide(internalhttp.NewServer),
+)
+
+// readCommandConfig reads the config of a command that doesn't serve requests. The
+// statement timeouts are meant for requests; a migration, a seed or an export runs as
+// long as it takes.
+func readCommandConfig() (*config.Config, error) {
+	cfg, err := config.ReadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Database != nil {
+		cfg.Database.QueryTimeout = 0
+		cfg.Database.StatementTimeout = 0
 	}
+	return cfg, nil
 }
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/internal/infrastructure/migration"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+var errSchemaDrift = errors.New("database schema has drifted from the models")
+
+var (
+	schemaCMD = &cobra.Command{
+		Use:   "schema",
+		Short: "Inspect the database schema",
+	}
+
+	schemaDiffCMD = &cobra.Command{
+		Use:   "diff",
+		Short: "Compare the database with the registered models",
+		Long:  `Report missing, extra and mismatched columns and indexes of every model registered with database.AsModel, exiting non-zero on drift`,
+		Args:  cobra.NoArgs,
+		RunE:  schemaDiff,
+	}
+)
+
+func init() {
+	schemaCMD.AddCommand(schemaDiffCMD)
+	rootCMD.AddCommand(schemaCMD)
+}
+
+func schemaDiff(cmd *cobra.Command, _ []string) error {
+	cfg, err := readCommandConfig()
+	if err != nil {
+		return err
+	}
+
+	var db *gorm.DB
+	var registry database.EntityRegistry
+
+	// The graph is only built, never started: the entities are registered by the domain
+	// modules, but the relay and the HTTP server must not run.
+	bootstrap := fx.New(commandModules(cmd.Context(), cfg,
+		withDomains,
+		fx.Populate(&db),
+		fx.Invoke(func(r database.EntityRegistry) { registry = r }),
+	))
+	if err := bootstrap.Err(); err != nil {
+		return err
+	}
+	defer func() {
+		_ = database.ShutdownGormDB(db)
+	}()
+
+	models := make([]interface{}, len(registry.Models))
+	for i, model := range registry.Models {
+		models[i] = model
+	}
+
+	drifts, err := migration.Diff(db, models...)
+	if err != nil {
+		return err
+	}
+	if len(drifts) == 0 {
+		fmt.Printf("✅ Schema matches all %d models\n", len(models))
+		return nil
+	}
+	for _, drift := range drifts {
+		fmt.Println("❌", drift)
+	}
+	return fmt.Errorf("%w: %d differences", errSchemaDrift, len(drifts))
+}
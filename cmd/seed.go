@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/core"
+	"agentic/commerce/internal/domains/audit"
+	"agentic/commerce/internal/domains/metadata"
+	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/internal/infrastructure/tenancy"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+// seedActor is the created_by of the seeded rows.
+const seedActor = "seed"
+
+var (
+	seedOptions metadata.SeedOptions
+	seedUntil   string
+	seedTenant  string
+	seedAudit   bool
+
+	seedCMD = &cobra.Command{
+		Use:   "seed",
+		Short: "Fill the database with fake posts",
+		Long: `Insert fake users' posts with Persian and English descriptions, image URLs and locations, for development and load tests.
+The same seed, volume and --until always generate the same posts; --until defaults to the start of the current UTC day.`,
+		Args: cobra.NoArgs,
+		RunE: seed,
+	}
+)
+
+func init() {
+	flags := seedCMD.Flags()
+	flags.Int64Var(&seedOptions.Seed, "seed", 1, "Seed of the generated data")
+	flags.IntVar(&seedOptions.Users, "users", metadata.DefaultSeedUsers, "Number of users the posts are spread over")
+	flags.IntVar(&seedOptions.Posts, "posts", metadata.DefaultSeedPosts, "Number of posts to insert")
+	flags.IntVar(&seedOptions.BatchSize, "batch-size", metadata.DefaultSeedBatchSize, "Posts per INSERT statement")
+	flags.IntVar(&seedOptions.Workers, "workers", metadata.DefaultSeedWorkers, "Batches inserted concurrently")
+	flags.Float64Var(&seedOptions.PersianShare, "persian", 0.5, "Fraction of users writing in Persian")
+	flags.DurationVar(&seedOptions.Period, "period", metadata.DefaultSeedPeriod, "Period before --until the posts are spread over")
+	flags.StringVar(&seedUntil, "until", "", "Newest post time, RFC 3339 or YYYY-MM-DD")
+	flags.StringVar(&seedTenant, "tenant", "", "Tenant of the posts (the default tenant if not specified)")
+	flags.BoolVar(&seedAudit, "audit", false, "Record the inserts in the audit log")
+
+	rootCMD.AddCommand(seedCMD)
+}
+
+func seed(cmd *cobra.Command, _ []string) error {
+	cfg, err := readCommandConfig()
+	if err != nil {
+		return err
+	}
+
+	until, err := parseSeedUntil(seedUntil)
+	if err != nil {
+		return err
+	}
+	opts := seedOptions
+	opts.Until = until
+
+	var db *gorm.DB
+	var seeder metadata.ISeeder
+
+	// Like schema diff the graph is only built, the relay and the HTTP server never run.
+	bootstrap := fx.New(commandModules(cmd.Context(), cfg,
+		withDomains,
+		fx.Populate(&db, &seeder),
+	))
+	if err := bootstrap.Err(); err != nil {
+		return err
+	}
+	defer func() {
+		_ = database.ShutdownGormDB(db)
+	}()
+
+	ctx := core.WithActor(cmd.Context(), seedActor)
+	if tenant := seedTenantOf(cfg); tenant != "" {
+		ctx = tenancy.WithTenant(ctx, tenant)
+	}
+	if !seedAudit {
+		ctx = audit.WithoutRecording(ctx)
+	}
+
+	started := time.Now()
+	err = seeder.Seed(ctx, opts, func(inserted int) {
+		fmt.Printf("\r🌱 %d/%d posts", inserted, opts.Posts)
+	})
+	fmt.Println()
+	if err != nil {
+		return err
+	}
+
+	elapsed := time.Since(started)
+	fmt.Printf("✅ Seeded %d posts in %s (%.0f posts/s)\n", opts.Posts, elapsed.Round(time.Millisecond), float64(opts.Posts)/elapsed.Seconds())
+	return nil
+}
+
+func parseSeedUntil(value string) (time.Time, error) {
+	if value == "" {
+		return time.Now().UTC().Truncate(24 * time.Hour), nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if until, err := time.Parse(layout, value); err == nil {
+			return until, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --until %q, use RFC 3339 or YYYY-MM-DD", value)
+}
+
+func seedTenantOf(cfg *config.Config) string {
+	if seedTenant != "" {
+		return seedTenant
+	}
+	if cfg.Tenancy != nil {
+		return cfg.Tenancy.DefaultTenant
+	}
+	return ""
+}
@@ -4,8 +4,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"agentic/commerce/config"
@@ -24,9 +22,8 @@ var serveCMD = &cobra.Command{
 	Run:   serve,
 }
 
-func serve(_ *cobra.Command, _ []string) {
-	signalCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer stop()
+func serve(cmd *cobra.Command, _ []string) {
+	signalCtx := cmd.Context()
 
 	cfg, err := config.ReadConfig(configPath)
 	if err != nil {
@@ -35,10 +32,18 @@ func serve(_ *cobra.Command, _ []string) {
 
 	bootstrap := fx.New(
 		fx.Supply(cfg),
+		supplyContext(signalCtx),
 		config.Module,
 		app.LoggerModule,
+		app.MetricsModule,
 		app.DatabaseModule,
+		app.AuthModule,
+		app.VerifySchemaModule,
+		app.CacheModule,
+		app.EventBusModule,
 		domains.Modules,
+		app.OutboxModule,
+		app.PartitionModule,
 		internalhttp.Module,
 	)
 
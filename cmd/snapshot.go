@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/domains/audit"
+	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/internal/infrastructure/migration"
+	"agentic/commerce/internal/infrastructure/snapshot"
+	"agentic/commerce/pkg/logger"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+var (
+	snapshotOutput              string
+	snapshotBatchSize           int
+	snapshotAllowSchemaMismatch bool
+
+	snapshotCMD = &cobra.Command{
+		Use:   "snapshot",
+		Short: "Back up and restore the data of every registered model",
+		Long:  `Logical, database agnostic backups: a tar.gz archive with a manifest (schema version, row counts, checksums) and one NDJSON file per table`,
+	}
+
+	snapshotExportCMD = &cobra.Command{
+		Use:   "export",
+		Short: "Write every row of every registered model to an archive",
+		Args:  cobra.NoArgs,
+		RunE:  snapshotExport,
+	}
+
+	snapshotImportCMD = &cobra.Command{
+		Use:   "import <archive>",
+		Short: "Restore an archive into an empty, migrated database",
+		Long:  `Restore an archive into a database migrated to the same schema version whose tables are empty, in dependency order and in one transaction`,
+		Args:  cobra.ExactArgs(1),
+		RunE:  snapshotImport,
+	}
+)
+
+func init() {
+	snapshotExportCMD.Flags().StringVarP(&snapshotOutput, "output", "o", "", "Archive to write, - for stdout (snapshot-<time>.tar.gz if not specified)")
+	snapshotCMD.PersistentFlags().IntVar(&snapshotBatchSize, "batch-size", snapshot.DefaultBatchSize, "Rows read or inserted per statement")
+	snapshotImportCMD.Flags().BoolVar(&snapshotAllowSchemaMismatch, "allow-schema-mismatch", false, "Import an archive taken at another schema version")
+
+	snapshotCMD.AddCommand(snapshotExportCMD, snapshotImportCMD)
+	rootCMD.AddCommand(snapshotCMD)
+}
+
+func snapshotExport(cmd *cobra.Command, _ []string) error {
+	return withEntities(cmd, func(ctx context.Context, db *gorm.DB, models []interface{}, opts snapshot.Options) error {
+		path := snapshotOutput
+		if path == "" {
+			path = fmt.Sprintf("snapshot-%s.tar.gz", time.Now().UTC().Format("20060102150405"))
+		}
+
+		var out io.Writer = os.Stdout
+		if path != "-" {
+			file, err := os.Create(path)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			out = file
+		}
+
+		manifest, err := snapshot.Export(ctx, db, out, models, opts)
+		if err != nil {
+			if path != "-" {
+				_ = os.Remove(path)
+			}
+			return err
+		}
+		for _, table := range manifest.Tables {
+			fmt.Fprintf(cmd.OutOrStdout(), "📦 %s: %d rows\n", table.Table, table.Rows)
+		}
+		if path != "-" {
+			fmt.Fprintf(cmd.OutOrStdout(), "✅ Exported schema %s to %s\n", manifest.SchemaVersion, path)
+		}
+		return nil
+	})
+}
+
+func snapshotImport(cmd *cobra.Command, args []string) error {
+	return withEntities(cmd, func(ctx context.Context, db *gorm.DB, models []interface{}, opts snapshot.Options) error {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		opts.AllowSchemaMismatch = snapshotAllowSchemaMismatch
+		// the restored audit log already has the history of the restored rows
+		manifest, err := snapshot.Import(audit.WithoutRecording(ctx), db, file, models, opts)
+		if err != nil {
+			return err
+		}
+		for _, table := range manifest.Tables {
+			fmt.Fprintf(cmd.OutOrStdout(), "📥 %s: %d rows\n", table.Table, table.Rows)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "✅ Imported %s, taken %s at schema %s\n", args[0], manifest.CreatedAt.Format(time.RFC3339), manifest.SchemaVersion)
+		return nil
+	})
+}
+
+// reserveStdout keeps stdout for the archive of `snapshot export -o -`: the banner, the
+// progress and the logs print to the output of cmd, which is pointed at stderr.
+func reserveStdout(cmd *cobra.Command) {
+	if cmd == snapshotExportCMD && snapshotOutput == "-" {
+		cmd.SetOut(cmd.ErrOrStderr())
+	}
+}
+
+// withEntities builds the graph like schema diff, logging to the output of cmd, and
+// hands fn the models registered with database.AsModel and the schema version of the
+// database.
+func withEntities(cmd *cobra.Command, fn func(ctx context.Context, db *gorm.DB, models []interface{}, opts snapshot.Options) error) error {
+	ctx := cmd.Context()
+	cfg, err := readCommandConfig()
+	if err != nil {
+		return err
+	}
+
+	var db *gorm.DB
+	var migrator *migration.Migrator
+	var registry database.EntityRegistry
+
+	bootstrap := fx.New(commandModules(ctx, cfg,
+		withDomains,
+		fx.Decorate(func(cfg *config.Config) *logger.AppLogger {
+			return logger.NewAppLoggerTo(cfg, cmd.OutOrStdout())
+		}),
+		fx.Populate(&db, &migrator),
+		fx.Invoke(func(r database.EntityRegistry) { registry = r }),
+	))
+	if err := bootstrap.Err(); err != nil {
+		return err
+	}
+	defer func() {
+		_ = database.ShutdownGormDB(db)
+	}()
+
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		return err
+	}
+
+	models := make([]interface{}, len(registry.Models))
+	for i, model := range registry.Models {
+		models[i] = model
+	}
+	return fn(ctx, db, models, snapshot.Options{BatchSize: snapshotBatchSize, SchemaVersion: version})
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"agentic/commerce/config"
+	"agentic/commerce/pkg/jwt"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	tokenSubject string
+	tokenRoles   []string
+	tokenScopes  []string
+	tokenTenant  string
+	tokenTTL     time.Duration
+
+	tokenCMD = &cobra.Command{
+		Use:   "token",
+		Short: "Sign a bearer token with the configured HS256 secret",
+		Long:  `Sign a bearer token for local development and scripts with the first auth key whose secret is set, in the config, its environment variable or its file; the issuer and audience come from the config`,
+		Args:  cobra.NoArgs,
+		RunE:  signToken,
+	}
+)
+
+func init() {
+	tokenCMD.Flags().StringVarP(&tokenSubject, "subject", "s", "", "User id the token is for")
+	tokenCMD.Flags().StringSliceVar(&tokenRoles, "role", nil, "Role to grant, repeatable")
+	tokenCMD.Flags().StringSliceVar(&tokenScopes, "scope", nil, "Scope to grant, repeatable")
+	tokenCMD.Flags().StringVar(&tokenTenant, "tenant", "", "Tenant to bind the token to, the default tenant when empty")
+	tokenCMD.Flags().DurationVar(&tokenTTL, "ttl", time.Hour, "How long the token is valid")
+	_ = tokenCMD.MarkFlagRequired("subject")
+
+	rootCMD.AddCommand(tokenCMD)
+}
+
+func signToken(_ *cobra.Command, _ []string) error {
+	cfg, err := config.ReadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	if cfg.Auth == nil {
+		return errors.New("no auth section in the config")
+	}
+
+	for _, key := range cfg.Auth.Keys {
+		secret, err := key.ResolveSecret()
+		if err != nil {
+			return err
+		}
+		if secret == "" {
+			continue
+		}
+		now := time.Now()
+		token, err := jwt.Sign(jwt.Claims{
+			Issuer:    cfg.Auth.Issuer,
+			Subject:   tokenSubject,
+			Audience:  cfg.Auth.Audience,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(tokenTTL)),
+			Roles:     tokenRoles,
+			Scopes:    tokenScopes,
+			Tenant:    tokenTenant,
+		}, key.Kid, []byte(secret))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, "🔑 Signed with key", key.Kid)
+		fmt.Println(token)
+		return nil
+	}
+	return errors.New("no auth key with a secret set, tokens for public keys are signed by their issuer")
+}
@@ -2,8 +2,13 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -21,6 +26,10 @@ type Config struct {
 	Http     *HttpConfig
 	Database *DbConfig
 	Logger   *Logger
+	Cache    *CacheConfig
+	Tenancy  *TenancyConfig
+	Outbox   *OutboxConfig
+	Auth     *AuthConfig
 }
 
 type LogLevel string
@@ -41,26 +50,438 @@ type Logger struct {
 	Level LogLevel `yaml:"level"`
 }
 
+type DbDriver string
+
+const (
+	DbDriverPostgres DbDriver = "postgres"
+	DbDriverMySQL    DbDriver = "mysql"
+	// DbDriverSQLite is the pure-Go SQLite; Database is the file path, or ":memory:".
+	DbDriverSQLite DbDriver = "sqlite"
+)
+
 type DbConfig struct {
-	Host                 string        `yaml:"host"`
-	Database             string        `yaml:"database"`
-	User                 string        `yaml:"user"`
-	Password             string        `yaml:"password"`
-	Port                 int           `yaml:"port"`
-	DialTimeout          time.Duration `yaml:"dialTimeout"`
-	Timeout              time.Duration `yaml:"timeout"`
-	DialRetry            int           `yaml:"dialRetry"`
-	MaxConn              int           `yaml:"maxConn"`
-	IdleConn             int           `yaml:"idleConn"`
-	DisableWithReturning bool          `yaml:"disableWithReturning"`
-	Location             string        `yaml:"location"`
+	// Driver selects the dialect, postgres when empty.
+	Driver DbDriver `yaml:"driver"`
+	// URL is the connection URL (a postgres:// URL, a go-sql-driver DSN or a SQLite
+	// file: URI); when set it takes precedence over the separate host, port, user,
+	// password and database settings.
+	URL         string        `yaml:"url"`
+	Host        string        `yaml:"host"`
+	Database    string        `yaml:"database"`
+	User        string        `yaml:"user"`
+	Password    string        `yaml:"password"`
+	Port        int           `yaml:"port"`
+	DialTimeout time.Duration `yaml:"dialTimeout"`
+	// QueryTimeout is the default statement timeout of serve: a query still running
+	// after it is cancelled. Zero disables it; the other commands and migrations run
+	// without.
+	QueryTimeout time.Duration `yaml:"queryTimeout"`
+	// RouteTimeouts overrides QueryTimeout per route. A list rather than a map keyed by
+	// route, whose keys viper would lowercase and so never match a path like /:postId.
+	RouteTimeouts []RouteTimeout `yaml:"routeTimeouts"`
+	// StatementTimeout sets the Postgres statement_timeout of every session of serve, a
+	// server side limit that also covers queries the client can no longer cancel.
+	StatementTimeout time.Duration `yaml:"statementTimeout"`
+	ConnMaxLifetime  time.Duration `yaml:"connMaxLifetime"`
+	ConnMaxIdleTime  time.Duration `yaml:"connMaxIdleTime"`
+	// ApplicationName tags our sessions in pg_stat_activity.
+	ApplicationName      string             `yaml:"applicationName"`
+	DialRetry            int                `yaml:"dialRetry"`
+	RetryBackoff         time.Duration      `yaml:"retryBackoff"`
+	MaxRetryBackoff      time.Duration      `yaml:"maxRetryBackoff"`
+	MaxConn              int                `yaml:"maxConn"`
+	IdleConn             int                `yaml:"idleConn"`
+	DisableWithReturning bool               `yaml:"disableWithReturning"`
+	Location             string             `yaml:"location"`
+	Replicas             []*DbReplica       `yaml:"replicas"`
+	ReplicaCheckInterval time.Duration      `yaml:"replicaCheckInterval"`
+	TLS                  *DbTLSConfig       `yaml:"tls"`
+	QueryStats           DbQueryStatsConfig `yaml:"queryStats"`
+	Partitioning         DbPartitionConfig  `yaml:"partitioning"`
+}
+
+// DbQueryStatsConfig tunes the slow query log and the per-fingerprint query statistics
+// behind /admin/database/slow-queries; zero values take the defaults of the database
+// package.
+type DbQueryStatsConfig struct {
+	// SlowThreshold is the duration from which a query is logged and counted as slow.
+	SlowThreshold time.Duration `yaml:"slowThreshold"`
+	// Window is how far back the statistics reach.
+	Window time.Duration `yaml:"window"`
+	// MaxFingerprints bounds the memory used; the least recently seen of the lock shard a
+	// new fingerprint hashes to is dropped first.
+	MaxFingerprints int `yaml:"maxFingerprints"`
+	// MaxSamples is how many of the latest durations per fingerprint the percentiles use.
+	MaxSamples int `yaml:"maxSamples"`
+}
+
+// DbPartitionConfig drives the maintenance of the tables partitioned by month, Postgres
+// only; zero values take the defaults of the partition package.
+type DbPartitionConfig struct {
+	// Interval is how often the partitions are checked, from startup on.
+	Interval time.Duration `yaml:"interval"`
+	// Premake is how many months ahead of the current one get a partition.
+	Premake int `yaml:"premake"`
+	// Retention is how many months before the current one stay attached, zero keeps all.
+	Retention int `yaml:"retention"`
+	// ArchiveSchema receives the expired partitions once detached; they stay where they
+	// are when empty.
+	ArchiveSchema string `yaml:"archiveSchema"`
+	// Drop deletes the expired partitions instead of keeping them detached.
+	Drop bool `yaml:"drop"`
+}
+
+// DbTLSConfig maps to the libpq sslmode, sslrootcert, sslcert and sslkey parameters;
+// for MySQL the mode is translated to the closest tls setting of go-sql-driver.
+type DbTLSConfig struct {
+	// Mode is one of disable, allow, prefer, require, verify-ca or verify-full.
+	Mode     string `yaml:"mode"`
+	RootCert string `yaml:"rootCert"`
+	Cert     string `yaml:"cert"`
+	Key      string `yaml:"key"`
+}
+
+// RouteTimeout is the statement timeout of the route with Method and Path as
+// registered, like GET /metadata/:id.
+type RouteTimeout struct {
+	Method  string        `yaml:"method"`
+	Path    string        `yaml:"path"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// DbReplica is a read-only copy of the primary; credentials and database are shared.
+type DbReplica struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+}
+
+// ForReplica returns a copy of the config pointing at the replica.
+func (c *DbConfig) ForReplica(r *DbReplica) *DbConfig {
+	replica := *c
+	replica.Host = r.Host
+	replica.Port = r.Port
+	replica.Replicas = nil
+	if c.URL != "" {
+		address := net.JoinHostPort(r.Host, strconv.Itoa(r.Port))
+		switch c.Dialect() {
+		case DbDriverMySQL:
+			replica.URL = mysqlAddress.ReplaceAllLiteralString(c.URL, "@tcp("+address+")")
+		default:
+			if u, err := url.Parse(c.URL); err == nil {
+				u.Host = address
+				replica.URL = u.String()
+			}
+		}
+	}
+	return &replica
+}
+
+// Dialect returns the configured driver, defaulting to postgres.
+func (c *DbConfig) Dialect() DbDriver {
+	if c.Driver == "" {
+		return DbDriverPostgres
+	}
+	return c.Driver
+}
+
+// DSN builds the connection string of the configured driver.
+func (c *DbConfig) DSN() string {
+	switch c.Dialect() {
+	case DbDriverMySQL:
+		return c.MySQLDSN()
+	case DbDriverSQLite:
+		return c.SQLiteDSN()
+	default:
+		return c.PostgresDSN()
+	}
 }
 
+// PostgresDSN builds the connection string, from URL when it is set. TLS, timezone and
+// connect timeout settings are only added when the URL doesn't already carry them.
 func (c *DbConfig) PostgresDSN() string {
-	return fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable TimeZone=%s",
-		c.Host, c.Port, c.User, c.Password, c.Database, c.Location,
-	)
+	params := c.postgresParams()
+
+	if c.URL != "" {
+		u, err := url.Parse(c.URL)
+		if err != nil {
+			return c.URL
+		}
+		query := u.Query()
+		for _, p := range params {
+			// Without an explicit mode the URL keeps the libpq default.
+			if p[0] == "sslmode" && (c.TLS == nil || c.TLS.Mode == "") {
+				continue
+			}
+			if !query.Has(p[0]) {
+				query.Set(p[0], p[1])
+			}
+		}
+		u.RawQuery = query.Encode()
+		return u.String()
+	}
+
+	parts := []string{
+		"host=" + quoteDSNValue(c.Host),
+		"port=" + strconv.Itoa(c.Port),
+		"user=" + quoteDSNValue(c.User),
+		"password=" + quoteDSNValue(c.Password),
+		"dbname=" + quoteDSNValue(c.Database),
+	}
+	for _, p := range params {
+		parts = append(parts, p[0]+"="+quoteDSNValue(p[1]))
+	}
+	return strings.Join(parts, " ")
+}
+
+var mysqlAddress = regexp.MustCompile(`@tcp\([^)]*\)`)
+
+// MySQLTLSConfigName is the name the custom TLS config is registered under with the
+// MySQL driver when a root CA or client certificate is configured.
+const MySQLTLSConfigName = "custom"
+
+// MySQLDSN builds a go-sql-driver DSN, from URL when it is set. Times are parsed into
+// time.Time in Location.
+func (c *DbConfig) MySQLDSN() string {
+	if c.URL != "" {
+		return c.URL
+	}
+
+	query := url.Values{}
+	query.Set("parseTime", "true")
+	query.Set("charset", "utf8mb4")
+	// SQL migrations are run as a single Exec of the whole file.
+	query.Set("multiStatements", "true")
+	if c.Location != "" {
+		query.Set("loc", c.Location)
+	}
+	if c.DialTimeout > 0 {
+		query.Set("timeout", c.DialTimeout.String())
+	}
+	if tls := c.mysqlTLS(); tls != "" {
+		query.Set("tls", tls)
+	}
+	return fmt.Sprintf("%s:%s@tcp(%s)/%s?%s",
+		c.User, c.Password, net.JoinHostPort(c.Host, strconv.Itoa(c.Port)), c.Database, query.Encode())
+}
+
+// mysqlTLS maps the libpq style sslmode onto the tls parameter of go-sql-driver.
+func (c *DbConfig) mysqlTLS() string {
+	if c.TLS == nil {
+		return ""
+	}
+	if c.TLS.RootCert != "" || c.TLS.Cert != "" {
+		return MySQLTLSConfigName
+	}
+	switch c.TLS.Mode {
+	case "", "disable":
+		return ""
+	case "allow", "prefer":
+		return "preferred"
+	case "require":
+		return "skip-verify"
+	default:
+		return "true"
+	}
+}
+
+// SQLiteDSN builds a file: URI for Database with foreign keys on, WAL journaling and
+// a busy timeout, from URL when it is set.
+func (c *DbConfig) SQLiteDSN() string {
+	if c.URL != "" {
+		return c.URL
+	}
+
+	path := c.Database
+	if path == "" || path == ":memory:" {
+		// a shared cache keeps one in-memory database across the connections of the pool
+		return "file::memory:?cache=shared&_pragma=foreign_keys(1)"
+	}
+	busyTimeout := 5 * time.Second
+	if c.DialTimeout > 0 {
+		busyTimeout = c.DialTimeout
+	}
+	return fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(%d)",
+		path, busyTimeout.Milliseconds())
+}
+
+// Redacted describes the database for logs without the password.
+func (c *DbConfig) Redacted() string {
+	if c.Dialect() == DbDriverSQLite {
+		return "sqlite:" + c.SQLiteDSN()
+	}
+	if c.URL != "" {
+		if u, err := url.Parse(c.URL); err == nil && u.Scheme != "" {
+			return u.Redacted()
+		}
+		return fmt.Sprintf("%s://<dsn>", c.Dialect())
+	}
+	return fmt.Sprintf("%s://%s@%s/%s", c.Dialect(), c.User, net.JoinHostPort(c.Host, strconv.Itoa(c.Port)), c.Database)
+}
+
+func (c *DbConfig) postgresParams() [][2]string {
+	sslMode := "disable"
+	var params [][2]string
+	if c.TLS != nil {
+		if c.TLS.Mode != "" {
+			sslMode = c.TLS.Mode
+		}
+		for _, p := range [][2]string{{"sslrootcert", c.TLS.RootCert}, {"sslcert", c.TLS.Cert}, {"sslkey", c.TLS.Key}} {
+			if p[1] != "" {
+				params = append(params, p)
+			}
+		}
+	}
+	params = append([][2]string{{"sslmode", sslMode}}, params...)
+	if c.Location != "" {
+		params = append(params, [2]string{"TimeZone", c.Location})
+	}
+	if c.ApplicationName != "" {
+		params = append(params, [2]string{"application_name", c.ApplicationName})
+	}
+	if c.DialTimeout > 0 {
+		seconds := max(int(c.DialTimeout.Round(time.Second).Seconds()), 1)
+		params = append(params, [2]string{"connect_timeout", strconv.Itoa(seconds)})
+	}
+	if c.StatementTimeout > 0 {
+		params = append(params, [2]string{"statement_timeout", strconv.FormatInt(c.StatementTimeout.Milliseconds(), 10)})
+	}
+	return params
+}
+
+// quoteDSNValue quotes a keyword/value DSN value when it is empty or has spaces or quotes.
+func quoteDSNValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+type CacheDriver string
+
+const (
+	CacheDisabled CacheDriver = ""
+	CacheMemory   CacheDriver = "memory"
+	CacheRedis    CacheDriver = "redis"
+)
+
+type CacheConfig struct {
+	Driver CacheDriver   `yaml:"driver"`
+	Size   int           `yaml:"size"`
+	TTL    time.Duration `yaml:"ttl"`
+	Prefix string        `yaml:"prefix"`
+	Redis  *RedisConfig  `yaml:"redis"`
+}
+
+type RedisConfig struct {
+	Addr     string `yaml:"addr"`
+	Password string `yaml:"password"`
+	DB       int    `yaml:"db"`
+}
+
+type TenancyConfig struct {
+	Header        string `yaml:"header"`
+	BaseDomain    string `yaml:"baseDomain"`
+	DefaultTenant string `yaml:"defaultTenant"`
+}
+
+// AuthConfig sets how bearer tokens are verified; without any key every request that
+// needs authentication is rejected.
+type AuthConfig struct {
+	// Issuer, when set, is the only iss accepted.
+	Issuer string `yaml:"issuer"`
+	// Audience, when set, must share a value with the aud of the token.
+	Audience []string `yaml:"audience"`
+	// ClockSkew is the tolerance of the exp, nbf and iat checks.
+	ClockSkew time.Duration `yaml:"clockSkew"`
+	Keys      []AuthKey     `yaml:"keys"`
+	// JWKSFile is a local JSON Web Key Set read at startup, on top of Keys.
+	JWKSFile string       `yaml:"jwksFile"`
+	APIKeys  APIKeyConfig `yaml:"apiKeys"`
+	// Roles maps a role to its permissions, "resource:action:scope" with scope own or
+	// any. Without roles the built-in ones of authz.DefaultRoles apply.
+	Roles map[string][]string `yaml:"roles"`
+	// DefaultRoles are held by every signed-in user, on top of the roles of its token.
+	DefaultRoles []string `yaml:"defaultRoles"`
+}
+
+// APIKeyConfig sets the lifetime of the API keys machine clients send in X-API-Key.
+type APIKeyConfig struct {
+	// DefaultTTL is the lifetime of a key issued without an expiry, 0 for none.
+	DefaultTTL time.Duration `yaml:"defaultTTL"`
+	// RotationGrace is how long a rotated key keeps working next to its replacement; 0
+	// ends it at once.
+	RotationGrace time.Duration `yaml:"rotationGrace"`
+	// LastUsedInterval throttles the last_used_at writes of a busy key.
+	LastUsedInterval time.Duration `yaml:"lastUsedInterval"`
+}
+
+// AuthKey is an HS256 secret or the PEM file of an RS256 or EdDSA public key. Secrets
+// don't belong in a committed config: name the environment variable or the file, such
+// as a mounted secret, holding it instead.
+type AuthKey struct {
+	Kid        string `yaml:"kid"`
+	Secret     string `yaml:"secret"`
+	SecretEnv  string `yaml:"secretEnv"`
+	SecretFile string `yaml:"secretFile"`
+	PublicKey  string `yaml:"publicKey"`
+}
+
+// HasSecret reports whether the key is an HS256 one, whether or not its secret is set.
+func (k AuthKey) HasSecret() bool {
+	return k.Secret != "" || k.SecretEnv != "" || k.SecretFile != ""
+}
+
+// ResolveSecret returns the HS256 secret from the config, the environment or the file;
+// empty when the variable or the file is.
+func (k AuthKey) ResolveSecret() (string, error) {
+	switch {
+	case k.Secret != "":
+		return k.Secret, nil
+	case k.SecretEnv != "":
+		return os.Getenv(k.SecretEnv), nil
+	case k.SecretFile != "":
+		data, err := os.ReadFile(k.SecretFile)
+		if err != nil {
+			return "", fmt.Errorf("auth key %s: %w", k.Kid, err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	default:
+		return "", nil
+	}
+}
+
+type OutboxPublisher string
+
+const (
+	OutboxPublisherMemory OutboxPublisher = "memory"
+	OutboxPublisherNATS   OutboxPublisher = "nats"
+	OutboxPublisherKafka  OutboxPublisher = "kafka"
+)
+
+type OutboxConfig struct {
+	Publisher       OutboxPublisher `yaml:"publisher"`
+	PollInterval    time.Duration   `yaml:"pollInterval"`
+	BatchSize       int             `yaml:"batchSize"`
+	MaxAttempts     int             `yaml:"maxAttempts"`
+	RetryBackoff    time.Duration   `yaml:"retryBackoff"`
+	MaxBackoff      time.Duration   `yaml:"maxBackoff"`
+	Retention       time.Duration   `yaml:"retention"`
+	CleanupInterval time.Duration   `yaml:"cleanupInterval"`
+	Lease           time.Duration   `yaml:"lease"`
+	NATS            *NATSConfig     `yaml:"nats"`
+	Kafka           *KafkaConfig    `yaml:"kafka"`
+}
+
+type NATSConfig struct {
+	URL           string `yaml:"url"`
+	SubjectPrefix string `yaml:"subjectPrefix"`
+}
+
+type KafkaConfig struct {
+	Brokers []string `yaml:"brokers"`
+	Topic   string   `yaml:"topic"`
 }
 
 type HttpClientConfig struct {
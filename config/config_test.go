@@ -0,0 +1,35 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadConfigKeepsTheCaseOfRouteTimeouts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	content := `
+database:
+  queryTimeout: "10s"
+  routeTimeouts:
+    - method: "get"
+      path: "/metadata/:postId"
+      timeout: "30s"
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := ReadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Database.QueryTimeout != 10*time.Second {
+		t.Fatalf("query timeout %s", cfg.Database.QueryTimeout)
+	}
+	want := RouteTimeout{Method: "get", Path: "/metadata/:postId", Timeout: 30 * time.Second}
+	if len(cfg.Database.RouteTimeouts) != 1 || cfg.Database.RouteTimeouts[0] != want {
+		t.Fatalf("route timeouts %+v", cfg.Database.RouteTimeouts)
+	}
+}
@@ -11,6 +11,10 @@ type configSupply struct {
 	HTTP     *HttpConfig
 	Database *DbConfig
 	Logger   *Logger
+	Cache    *CacheConfig
+	Tenancy  *TenancyConfig
+	Outbox   *OutboxConfig
+	Auth     *AuthConfig
 }
 
 func provideNestedConfigs(cfg *Config) configSupply {
@@ -19,6 +23,10 @@ func provideNestedConfigs(cfg *Config) configSupply {
 		HTTP:     cfg.Http,
 		Database: cfg.Database,
 		Logger:   cfg.Logger,
+		Cache:    cfg.Cache,
+		Tenancy:  cfg.Tenancy,
+		Outbox:   cfg.Outbox,
+		Auth:     cfg.Auth,
 	}
 }
 
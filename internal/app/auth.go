@@ -0,0 +1,14 @@
+package app
+
+import (
+	"agentic/commerce/internal/infrastructure/auth"
+	"agentic/commerce/internal/infrastructure/authz"
+
+	"go.uber.org/fx"
+)
+
+var AuthModule = fx.Module(
+	"auth",
+	fx.Provide(auth.NewAuthenticator),
+	fx.Provide(authz.NewPolicy),
+)
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/infrastructure/cache"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+)
+
+// NewCache builds the configured cache backend, or returns nil when caching is disabled.
+func NewCache(lc fx.Lifecycle, cfg *config.CacheConfig, registerer prometheus.Registerer) (cache.Cache, error) {
+	if cfg == nil || cfg.Driver == config.CacheDisabled {
+		return nil, nil
+	}
+
+	metrics, err := cache.NewMetrics(registerer, string(cfg.Driver))
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.Driver {
+	case config.CacheMemory:
+		evictions, err := cache.NewEvictionCounter(registerer, string(cfg.Driver))
+		if err != nil {
+			return nil, err
+		}
+		backend := cache.NewMemoryCache(cfg.Size, evictions.Inc)
+		return cache.Instrument(backend, metrics), nil
+
+	case config.CacheRedis:
+		if cfg.Redis == nil {
+			return nil, fmt.Errorf("cache driver %q needs a redis section", cfg.Driver)
+		}
+		client := redis.NewClient(&redis.Options{
+			Addr:     cfg.Redis.Addr,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+		if err := registerer.Register(cache.NewRedisEvictionCollector(client)); err != nil {
+			return nil, err
+		}
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				return client.Ping(ctx).Err()
+			},
+			OnStop: func(ctx context.Context) error {
+				fmt.Println("🛑 Gracefully stopping cache...")
+				return client.Close()
+			},
+		})
+		return cache.Instrument(cache.NewRedisCache(client, cfg.Prefix), metrics), nil
+
+	default:
+		return nil, fmt.Errorf("unknown cache driver %q", cfg.Driver)
+	}
+}
+
+var CacheModule = fx.Module(
+	"cache",
+	fx.Provide(NewCache),
+)
@@ -4,13 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"time"
 
 	"agentic/commerce/config"
 	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/internal/infrastructure/tenancy"
 	"agentic/commerce/pkg/logger"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"go.uber.org/fx"
 	"gorm.io/gorm"
 	gormLogger "gorm.io/gorm/logger"
@@ -30,89 +32,103 @@ func registerDBShutdown(lc fx.Lifecycle, db *gorm.DB) {
 	})
 }
 
+func NewReplicaSet(lc fx.Lifecycle, db *gorm.DB, cfg *config.DbConfig, appLogger *logger.AppLogger) (*database.ReplicaSet, error) {
+	replicas, err := database.RegisterReplicas(db, cfg, appLogger)
+	if err != nil {
+		return nil, err
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			replicas.Close()
+			return nil
+		},
+	})
+	return replicas, nil
+}
+
+// registerPoolMetrics exports sql.DBStats of the primary and every replica, sampled on
+// each scrape and labelled by db_name.
+func registerPoolMetrics(db *gorm.DB, replicas *database.ReplicaSet, registerer prometheus.Registerer) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if err := registerer.Register(collectors.NewDBStatsCollector(sqlDB, "primary")); err != nil {
+		return err
+	}
+	for name, pool := range replicas.Pools() {
+		if err := registerer.Register(collectors.NewDBStatsCollector(pool, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 var DatabaseModule = fx.Module(
 	"database",
+	fx.Provide(database.NewQueryStats),
 	fx.Provide(NewDatabase),
-	fx.Invoke(autoMigrate),
+	fx.Provide(NewReplicaSet),
+	fx.Provide(NewMigrator),
+	fx.Invoke(registerPoolMetrics),
 	fx.Invoke(registerDBShutdown),
 )
 
 type DB struct{}
 
-func NewDatabase(cfg *config.DbConfig, appLogger *logger.AppLogger) (*gorm.DB, error) {
+// NewDatabase connects to the primary. ctx is the command's context, so a shutdown signal
+// aborts the connection retries.
+func NewDatabase(ctx context.Context, cfg *config.DbConfig, tenancyCfg *config.TenancyConfig, appLogger *logger.AppLogger, stats *database.QueryStats) (*gorm.DB, error) {
 	lo := appLogger.WithScope(DB{})
 
-	customLogger := NewZerologGormLogger(lo, gormLogger.LogLevel(lo.GetLogLevel()))
+	customLogger := NewZerologGormLogger(lo, gormLogger.LogLevel(lo.GetLogLevel()), stats)
 
-	db, err := database.NewGormPostgresConnection(cfg)
+	db, err := database.NewGormConnection(ctx, cfg, appLogger.Output())
 
 	if err != nil {
-		lo.Fatal("error in new connection %v\n", err)
+		lo.Error("error in new connection", err)
 		return nil, err
 	}
 
 	db.Logger = customLogger
 
-	return db, nil
-}
-
-func AutoMigrate(db *gorm.DB, models ...interface{}) {
-	var err error
-
-	nonConstraintGorm, err := gorm.Open(db.Dialector, &gorm.Config{DisableForeignKeyConstraintWhenMigrating: true})
-	if err != nil {
-		panic(fmt.Errorf("cannot access db in auto-migrating %w", err))
+	tenantPlugin := &tenancy.Plugin{}
+	if tenancyCfg != nil {
+		tenantPlugin.DefaultTenant = tenancyCfg.DefaultTenant
 	}
-	defer func() {
-		nonConstraintDB, _ := nonConstraintGorm.DB()
-		_ = nonConstraintDB.Close()
-	}()
-
-	err = nonConstraintGorm.AutoMigrate(models...)
-	if err != nil {
-		panic(fmt.Errorf("error in non-constraint auto-migrating %w", err))
-	}
-
-	err = db.AutoMigrate(models...)
-	if err != nil {
-		panic(fmt.Errorf("error in auto-migrating %w", err))
+	if err := db.Use(tenantPlugin); err != nil {
+		return nil, err
 	}
-}
-
-// AutoMigrate performs database migration for all registered models.
-func autoMigrate(db *gorm.DB, registry database.EntityRegistry) {
-
-	db = db.Debug()
-
-	// Convert models to []interface{} for GORM
-	models := make([]interface{}, len(registry.Models))
-	for i, m := range registry.Models {
-		models[i] = m
+	if err := db.Use(&database.TimeoutPlugin{Default: cfg.QueryTimeout}); err != nil {
+		return nil, err
 	}
 
-	// Execute migration
-	AutoMigrate(db, models...)
-
-	log.Println("Auto migration completed successfully")
+	return db, nil
 }
 
+// ZerologGormLogger logs failed and slow queries and feeds every query into the
+// per-fingerprint statistics.
 type ZerologGormLogger struct {
-	log        *logger.AppLogger
-	level      gormLogger.LogLevel
-	slowThresh time.Duration
+	log   *logger.AppLogger
+	level gormLogger.LogLevel
+	stats *database.QueryStats
 }
 
-func NewZerologGormLogger(appLogger *logger.AppLogger, level gormLogger.LogLevel) *ZerologGormLogger {
+func NewZerologGormLogger(appLogger *logger.AppLogger, level gormLogger.LogLevel, stats *database.QueryStats) *ZerologGormLogger {
 	return &ZerologGormLogger{
-		log:        appLogger,
-		level:      level,
-		slowThresh: 200 * time.Millisecond,
+		log:   appLogger,
+		level: level,
+		stats: stats,
 	}
 }
 
+// LogMode returns a copy, sessions that silence their queries must not silence the
+// shared logger.
 func (l *ZerologGormLogger) LogMode(level gormLogger.LogLevel) gormLogger.Interface {
-	l.level = level
-	return l
+	clone := *l
+	clone.level = level
+	return &clone
 }
 
 func (l *ZerologGormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
@@ -134,22 +150,24 @@ func (l *ZerologGormLogger) Error(ctx context.Context, msg string, data ...inter
 }
 
 func (l *ZerologGormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+	sql, rows := fc()
+	fingerprint := l.stats.Record(sql, elapsed, rows)
+
 	if l.level == gormLogger.Silent {
 		return
 	}
 
-	elapsed := time.Since(begin)
-	sql, rows := fc()
-
 	e := l.log.With("file", utils.FileWithLineNum()).
 		With("sql", sql).
+		With("fingerprint", fingerprint).
 		With("rows", rows).
 		With("elapsed", elapsed)
 
 	switch {
 	case err != nil && !errors.Is(err, gormLogger.ErrRecordNotFound):
 		e.Error("gorm query error", err)
-	case elapsed > l.slowThresh && l.level >= gormLogger.Warn:
+	case elapsed >= l.stats.SlowThreshold() && l.level >= gormLogger.Warn:
 		e.Warn("slow query")
 	case l.level >= gormLogger.Info:
 		e.Debug("gorm query")
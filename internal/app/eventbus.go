@@ -0,0 +1,28 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"agentic/commerce/internal/infrastructure/eventbus"
+
+	"go.uber.org/fx"
+)
+
+func registerEventBusShutdown(lc fx.Lifecycle, bus *eventbus.Bus) {
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			fmt.Println("🛑 Gracefully stopping event bus...")
+			return bus.Wait(ctx)
+		},
+	})
+}
+
+var EventBusModule = fx.Module(
+	"eventbus",
+	fx.Provide(
+		eventbus.NewEventBus,
+		func(bus *eventbus.Bus) eventbus.IEventBus { return bus },
+	),
+	fx.Invoke(registerEventBusShutdown),
+)
@@ -0,0 +1,24 @@
+package app
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"go.uber.org/fx"
+)
+
+func NewMetricsRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
+
+var MetricsModule = fx.Module(
+	"metrics",
+	fx.Provide(
+		NewMetricsRegistry,
+		func(registry *prometheus.Registry) prometheus.Registerer { return registry },
+	),
+)
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"context"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/infrastructure/migration"
+	"agentic/commerce/migrations"
+
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+func NewMigrator(db *gorm.DB, cfg *config.DbConfig) (*migration.Migrator, error) {
+	all, err := migrations.All(string(cfg.Dialect()))
+	if err != nil {
+		return nil, err
+	}
+	return migration.NewMigrator(db, all), nil
+}
+
+// verifySchema refuses to start against a database that isn't migrated to exactly
+// the migrations of this build; schema changes only happen through `migrate up`.
+func verifySchema(lc fx.Lifecycle, migrator *migration.Migrator) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return migrator.Verify(ctx)
+		},
+	})
+}
+
+// VerifySchemaModule is added by serve, the migrate commands need the database
+// without it.
+var VerifySchemaModule = fx.Module(
+	"verify-schema",
+	fx.Invoke(verifySchema),
+)
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/internal/infrastructure/outbox"
+	"agentic/commerce/pkg/logger"
+
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+func NewOutboxPublisher(lc fx.Lifecycle, cfg *config.OutboxConfig) (outbox.Publisher, error) {
+	var publisher outbox.Publisher
+
+	driver := config.OutboxPublisherMemory
+	if cfg != nil && cfg.Publisher != "" {
+		driver = cfg.Publisher
+	}
+
+	switch driver {
+	case config.OutboxPublisherMemory:
+		publisher = outbox.NewInMemoryPublisher()
+
+	case config.OutboxPublisherNATS:
+		if cfg.NATS == nil {
+			return nil, fmt.Errorf("outbox publisher %q needs a nats section", driver)
+		}
+		natsPublisher, err := outbox.NewNATSPublisher(cfg.NATS.URL, cfg.NATS.SubjectPrefix)
+		if err != nil {
+			return nil, fmt.Errorf("cannot connect to nats %s: %w", cfg.NATS.URL, err)
+		}
+		publisher = natsPublisher
+
+	case config.OutboxPublisherKafka:
+		if cfg.Kafka == nil {
+			return nil, fmt.Errorf("outbox publisher %q needs a kafka section", driver)
+		}
+		publisher = outbox.NewKafkaPublisher(cfg.Kafka.Brokers, cfg.Kafka.Topic)
+
+	default:
+		return nil, fmt.Errorf("unknown outbox publisher %q", driver)
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return publisher.Close()
+		},
+	})
+	return publisher, nil
+}
+
+func NewOutboxRelay(db *gorm.DB, publisher outbox.Publisher, cfg *config.OutboxConfig, appLogger *logger.AppLogger) *outbox.Relay {
+	var opts outbox.RelayOptions
+	if cfg != nil {
+		opts = outbox.RelayOptions{
+			PollInterval:    cfg.PollInterval,
+			BatchSize:       cfg.BatchSize,
+			MaxAttempts:     cfg.MaxAttempts,
+			RetryBackoff:    cfg.RetryBackoff,
+			MaxBackoff:      cfg.MaxBackoff,
+			Retention:       cfg.Retention,
+			CleanupInterval: cfg.CleanupInterval,
+			Lease:           cfg.Lease,
+		}
+	}
+	return outbox.NewRelay(db, publisher, opts, appLogger)
+}
+
+func registerOutboxRelay(lc fx.Lifecycle, relay *outbox.Relay) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			relay.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			fmt.Println("🛑 Gracefully stopping outbox relay...")
+			return relay.Stop(ctx)
+		},
+	})
+}
+
+var OutboxModule = fx.Module(
+	"outbox",
+	fx.Provide(NewOutboxPublisher),
+	fx.Provide(NewOutboxRelay),
+	fx.Provide(outbox.NewOutbox),
+	fx.Invoke(registerOutboxRelay),
+	database.AsModel(&outbox.MessageModel{}),
+)
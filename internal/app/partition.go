@@ -0,0 +1,45 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/infrastructure/partition"
+	"agentic/commerce/pkg/logger"
+
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+func NewPartitionMaintainer(db *gorm.DB, cfg *config.DbConfig, registry partition.Registry, appLogger *logger.AppLogger) *partition.Maintainer {
+	opts := partition.Options{
+		Interval:      cfg.Partitioning.Interval,
+		Premake:       cfg.Partitioning.Premake,
+		Retention:     cfg.Partitioning.Retention,
+		ArchiveSchema: cfg.Partitioning.ArchiveSchema,
+		Drop:          cfg.Partitioning.Drop,
+	}
+	return partition.NewMaintainer(db, registry.Tables, opts, appLogger)
+}
+
+func registerPartitionMaintainer(lc fx.Lifecycle, maintainer *partition.Maintainer) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			maintainer.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			fmt.Println("🛑 Gracefully stopping partition maintainer...")
+			return maintainer.Stop(ctx)
+		},
+	})
+}
+
+// PartitionModule keeps the tables registered with partition.AsMonthly partitioned
+// ahead of time while the server runs.
+var PartitionModule = fx.Module(
+	"partition",
+	fx.Provide(NewPartitionMaintainer),
+	fx.Invoke(registerPartitionMaintainer),
+)
@@ -0,0 +1,23 @@
+package core
+
+import "context"
+
+// SystemActor is recorded when a write happens outside an authenticated request.
+const SystemActor = "system"
+
+type actorContextKey struct{}
+
+// WithActor stores the identity performing the current operation.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the actor stored by WithActor, or SystemActor.
+func ActorFromContext(ctx context.Context) string {
+	if ctx != nil {
+		if actor, ok := ctx.Value(actorContextKey{}).(string); ok && actor != "" {
+			return actor
+		}
+	}
+	return SystemActor
+}
@@ -9,6 +9,7 @@ import (
 
 type BaseModel struct {
 	ID        uint64                `gorm:"primarykey"`
+	TenantID  string                `gorm:"Column:tenant_id;size:64;not null;default:'default';index"`
 	DeletedAt soft_delete.DeletedAt `gorm:"index"`
 	CreatedAt time.Time             `gorm:"Column:created_at"`
 	CreatedBy string                `gorm:"Column:created_by"`
@@ -16,13 +17,15 @@ type BaseModel struct {
 	UpdatedBy string                `gorm:"Column:updated_by"`
 }
 
-// autofil audit fields
-func (b *BaseModel) BeforeCreate(tx *gorm.DB) {
-	b.CreatedBy = "1"
-	b.UpdatedBy = "1"
-
+// autofil audit fields from the actor of the request
+func (b *BaseModel) BeforeCreate(tx *gorm.DB) error {
+	actor := ActorFromContext(tx.Statement.Context)
+	tx.Statement.SetColumn("CreatedBy", actor)
+	tx.Statement.SetColumn("UpdatedBy", actor)
+	return nil
 }
 
-func (b *BaseModel) BeforeUpdate(tx *gorm.DB) {
-	b.UpdatedBy = "1"
+func (b *BaseModel) BeforeUpdate(tx *gorm.DB) error {
+	tx.Statement.SetColumn("UpdatedBy", ActorFromContext(tx.Statement.Context))
+	return nil
 }
@@ -9,8 +9,17 @@ import (
 )
 
 type IBaseRepository[TModel any] interface {
+	FindByID(ctx context.Context, id uint64, specs ...Specification) (*TModel, error)
+	FindOne(ctx context.Context, specs ...Specification) (*TModel, error)
+	FindAll(ctx context.Context, specs ...Specification) ([]TModel, error)
+	Count(ctx context.Context, specs ...Specification) (int64, error)
+	Exists(ctx context.Context, specs ...Specification) (bool, error)
+	Paginate(ctx context.Context, page PageRequest, specs ...Specification) (*Page[TModel], error)
+
 	Save(ctx context.Context, model *TModel) error
 	Create(ctx context.Context, model *TModel) error
+	// CreateInBatches inserts models with one statement per batchSize rows.
+	CreateInBatches(ctx context.Context, models []TModel, batchSize int) error
 	Update(ctx context.Context, model *TModel) error
 	Upsert(ctx context.Context, model *TModel) error
 	Delete(ctx context.Context, model *TModel) error
@@ -25,6 +34,83 @@ func NewBaseRepository[TModel any](database database.GormDB) IBaseRepository[TMo
 	}
 }
 
+func (db *baseRepository[TModel]) query(ctx context.Context, specs []Specification) (*gorm.DB, error) {
+	tx := db.database(ctx)
+	if err := validatePreloads(tx, new(TModel), specs); err != nil {
+		return nil, err
+	}
+	return applySpecs(tx.Model(new(TModel)), specs), nil
+}
+
+func (db *baseRepository[TModel]) FindByID(ctx context.Context, id uint64, specs ...Specification) (*TModel, error) {
+	return db.FindOne(ctx, append([]Specification{Eq("id", id)}, specs...)...)
+}
+
+func (db *baseRepository[TModel]) FindOne(ctx context.Context, specs ...Specification) (*TModel, error) {
+	query, err := db.query(ctx, specs)
+	if err != nil {
+		return nil, err
+	}
+	var result TModel
+	tx := query.Take(&result)
+	return ResolveDBResult(&result, tx)
+}
+
+func (db *baseRepository[TModel]) FindAll(ctx context.Context, specs ...Specification) ([]TModel, error) {
+	query, err := db.query(ctx, specs)
+	if err != nil {
+		return nil, err
+	}
+	var result []TModel
+	tx := query.Find(&result)
+	return ResolveDBSliceResult(result, tx)
+}
+
+func (db *baseRepository[TModel]) Count(ctx context.Context, specs ...Specification) (int64, error) {
+	query, err := db.query(ctx, withoutOrdering(specs))
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	tx := query.Count(&count)
+	return count, tx.Error
+}
+
+func (db *baseRepository[TModel]) Exists(ctx context.Context, specs ...Specification) (bool, error) {
+	query, err := db.query(ctx, withoutOrdering(specs))
+	if err != nil {
+		return false, err
+	}
+	var found int
+	tx := query.Select("1").Limit(1).Find(&found)
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+	return tx.RowsAffected > 0, nil
+}
+
+func (db *baseRepository[TModel]) Paginate(ctx context.Context, page PageRequest, specs ...Specification) (*Page[TModel], error) {
+	page = page.normalize()
+
+	total, err := db.Count(ctx, specs...)
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := db.query(ctx, specs)
+	if err != nil {
+		return nil, err
+	}
+	var items []TModel
+	tx := query.Offset(page.offset()).Limit(page.Size).Find(&items)
+	items, err = ResolveDBSliceResult(items, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPage(items, page, total), nil
+}
+
 func (db *baseRepository[TModel]) Save(ctx context.Context, model *TModel) error {
 	tx := db.database(ctx).
 		Session(&gorm.Session{FullSaveAssociations: true}).
@@ -38,6 +124,12 @@ func (db *baseRepository[TModel]) Create(ctx context.Context, model *TModel) err
 	return tx.Error
 }
 
+func (db *baseRepository[TModel]) CreateInBatches(ctx context.Context, models []TModel, batchSize int) error {
+	tx := db.database(ctx).
+		CreateInBatches(&models, batchSize)
+	return tx.Error
+}
+
 func (db *baseRepository[TModel]) Update(ctx context.Context, model *TModel) error {
 	tx := db.database(ctx).
 		Session(&gorm.Session{FullSaveAssociations: true}).
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/go-json-experiment/json/v1"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// JSON is a JSON object column, stored as jsonb on Postgres and json on MySQL and SQLite.
+type JSON map[string]interface{}
+
+func (JSON) GormDataType() string {
+	return "json"
+}
+
+func (JSON) GormDBDataType(db *gorm.DB, _ *schema.Field) string {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return "jsonb"
+	default:
+		return "json"
+	}
+}
+
+// Scan accepts []byte (Postgres, MySQL) and string (SQLite).
+func (j *JSON) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*j = JSON{}
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("invalid JSON value: %T", value)
+	}
+
+	if len(bytes) == 0 {
+		*j = JSON{}
+		return nil
+	}
+	return json.Unmarshal(bytes, j)
+}
+
+// Value returns a string, MySQL rejects JSON sent as binary.
+func (j JSON) Value() (driver.Value, error) {
+	if j == nil {
+		return nil, nil
+	}
+	bytes, err := json.Marshal(j)
+	if err != nil {
+		return nil, err
+	}
+	return string(bytes), nil
+}
@@ -0,0 +1,582 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"agentic/commerce/internal/infrastructure/tenancy"
+
+	"github.com/go-json-experiment/json/v1"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"gorm.io/plugin/soft_delete"
+)
+
+var ErrUnsupportedSpecification = errors.New("specification is not supported by the in-memory repository")
+
+var softDeleteType = reflect.TypeOf(soft_delete.DeletedAt(0))
+
+// inMemoryRepository keeps models in a map, for tests that should not need a database.
+// It mirrors the GORM repository closely enough for service tests: soft-deleted rows are
+// hidden, rows are scoped to the tenant tenancy.Plugin would scope them to and models are
+// stored as JSON copies, so what comes back has the same shape as a row read from the
+// database. Raw Scope specifications can't be evaluated and fail with
+// ErrUnsupportedSpecification.
+type inMemoryRepository[TModel any] struct {
+	mu            sync.RWMutex
+	schema        *schema.Schema
+	rows          map[uint64][]byte
+	nextID        uint64
+	now           func() time.Time
+	defaultTenant string
+}
+
+// NewInMemoryRepository scopes contexts without a tenant to defaultTenant, the
+// DefaultTenant of tenancy.Plugin.
+func NewInMemoryRepository[TModel any](defaultTenant string) IBaseRepository[TModel] {
+	parsed, err := schema.Parse(new(TModel), &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		panic(fmt.Errorf("cannot parse schema of %T: %w", new(TModel), err))
+	}
+	return &inMemoryRepository[TModel]{
+		schema:        parsed,
+		rows:          make(map[uint64][]byte),
+		now:           time.Now,
+		defaultTenant: defaultTenant,
+	}
+}
+
+// tenantScope is the tenant the statements of a context are scoped to, see
+// tenancy.Resolve; field is nil for models that aren't tenant-scoped.
+type tenantScope struct {
+	field    *schema.Field
+	tenantID string
+	scoped   bool
+}
+
+func (r *inMemoryRepository[TModel]) tenantScope(ctx context.Context) (tenantScope, error) {
+	field := r.schema.LookUpField(tenancy.FieldName)
+	if field == nil {
+		return tenantScope{}, nil
+	}
+	tenantID, scoped, err := tenancy.Resolve(ctx, r.defaultTenant)
+	if err != nil {
+		return tenantScope{}, err
+	}
+	return tenantScope{field: field, tenantID: tenantID, scoped: scoped}, nil
+}
+
+func (r *inMemoryRepository[TModel]) FindByID(ctx context.Context, id uint64, specs ...Specification) (*TModel, error) {
+	return r.FindOne(ctx, append([]Specification{Eq(r.primaryField().DBName, id)}, specs...)...)
+}
+
+func (r *inMemoryRepository[TModel]) FindOne(ctx context.Context, specs ...Specification) (*TModel, error) {
+	items, err := r.FindAll(ctx, specs...)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+func (r *inMemoryRepository[TModel]) FindAll(ctx context.Context, specs ...Specification) ([]TModel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.find(ctx, specs)
+}
+
+func (r *inMemoryRepository[TModel]) Count(ctx context.Context, specs ...Specification) (int64, error) {
+	items, err := r.FindAll(ctx, withoutOrdering(specs)...)
+	return int64(len(items)), err
+}
+
+func (r *inMemoryRepository[TModel]) Exists(ctx context.Context, specs ...Specification) (bool, error) {
+	count, err := r.Count(ctx, specs...)
+	return count > 0, err
+}
+
+func (r *inMemoryRepository[TModel]) Paginate(ctx context.Context, page PageRequest, specs ...Specification) (*Page[TModel], error) {
+	page = page.normalize()
+
+	items, err := r.FindAll(ctx, specs...)
+	if err != nil {
+		return nil, err
+	}
+	total := int64(len(items))
+
+	start := min(page.offset(), len(items))
+	end := min(start+page.Size, len(items))
+	return newPage(items[start:end], page, total), nil
+}
+
+func (r *inMemoryRepository[TModel]) Save(ctx context.Context, model *TModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	scope, err := r.tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+	id := r.idOf(ctx, model)
+	if _, ok := r.visible(ctx, scope, id); id == 0 || !ok {
+		// the update misses the row of another tenant and the insert conflicts with it
+		if _, exists := r.rows[id]; exists {
+			return gorm.ErrDuplicatedKey
+		}
+		return r.insert(ctx, model)
+	}
+	return r.store(ctx, model)
+}
+
+func (r *inMemoryRepository[TModel]) Create(ctx context.Context, model *TModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id := r.idOf(ctx, model); id != 0 {
+		if _, exists := r.rows[id]; exists {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	return r.insert(ctx, model)
+}
+
+// CreateInBatches stops at the first model that can't be created, like a failed batch
+// statement the models before it stay created.
+func (r *inMemoryRepository[TModel]) CreateInBatches(ctx context.Context, models []TModel, _ int) error {
+	for i := range models {
+		if err := r.Create(ctx, &models[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Update only copies the non-zero fields, like gorm's Updates with a struct.
+func (r *inMemoryRepository[TModel]) Update(ctx context.Context, model *TModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	scope, err := r.tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+	current, ok := r.visible(ctx, scope, r.idOf(ctx, model))
+	if !ok {
+		return nil
+	}
+
+	src := reflect.ValueOf(model).Elem()
+	dst := reflect.ValueOf(current).Elem()
+	for _, field := range r.schema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		if value, zero := field.ValueOf(ctx, src); !zero {
+			if err := field.Set(ctx, dst, value); err != nil {
+				return err
+			}
+		}
+	}
+	if err := r.store(ctx, current); err != nil {
+		return err
+	}
+	*model = *current
+	return nil
+}
+
+func (r *inMemoryRepository[TModel]) Upsert(ctx context.Context, model *TModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	scope, err := r.tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+	id := r.idOf(ctx, model)
+	if _, ok := r.rows[id]; !ok {
+		return r.insert(ctx, model)
+	}
+	// the conflicting row of another tenant is left alone, as tenancy.Plugin guards it
+	if _, ok := r.visible(ctx, scope, id); !ok {
+		return nil
+	}
+	return r.store(ctx, model)
+}
+
+func (r *inMemoryRepository[TModel]) Delete(ctx context.Context, model *TModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	scope, err := r.tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+	id := r.idOf(ctx, model)
+	current, ok := r.visible(ctx, scope, id)
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	deletedAt := r.schema.LookUpField("DeletedAt")
+	if deletedAt == nil || deletedAt.FieldType != softDeleteType {
+		delete(r.rows, id)
+		return nil
+	}
+	rv := reflect.ValueOf(current).Elem()
+	if err := deletedAt.Set(ctx, rv, soft_delete.DeletedAt(r.now().Unix())); err != nil {
+		return err
+	}
+	return r.put(id, current)
+}
+
+func (r *inMemoryRepository[TModel]) find(ctx context.Context, specs []Specification) ([]TModel, error) {
+	var filters []Specification
+	var orders []Order
+	for _, spec := range specs {
+		switch s := spec.(type) {
+		case nil:
+		case Order:
+			orders = append(orders, s)
+		case Preload:
+			if err := checkAssociation(r.schema, s.Association); err != nil {
+				return nil, err
+			}
+		default:
+			filters = append(filters, spec)
+		}
+	}
+
+	scope, err := r.tenantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []TModel
+	for id := range r.rows {
+		model, ok := r.visible(ctx, scope, id)
+		if !ok {
+			continue
+		}
+		matched, err := r.matchAll(ctx, reflect.ValueOf(model).Elem(), filters)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			out = append(out, *model)
+		}
+	}
+
+	if len(orders) == 0 {
+		orders = []Order{{Column: r.primaryField().DBName}}
+	}
+	var sortErr error
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := reflect.ValueOf(&out[i]).Elem(), reflect.ValueOf(&out[j]).Elem()
+		for _, order := range orders {
+			field := r.schema.LookUpField(order.Column)
+			if field == nil {
+				sortErr = fmt.Errorf("%s has no column %q", r.schema.Name, order.Column)
+				return false
+			}
+			av, _ := field.ValueOf(ctx, a)
+			bv, _ := field.ValueOf(ctx, b)
+			cmp, _ := compareValues(av, bv)
+			if cmp != 0 {
+				return (cmp < 0) != order.Desc
+			}
+		}
+		return false
+	})
+	return out, sortErr
+}
+
+func (r *inMemoryRepository[TModel]) matchAll(ctx context.Context, rv reflect.Value, specs []Specification) (bool, error) {
+	for _, spec := range specs {
+		matched, err := r.match(ctx, rv, spec)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (r *inMemoryRepository[TModel]) match(ctx context.Context, rv reflect.Value, spec Specification) (bool, error) {
+	switch s := spec.(type) {
+	case Condition:
+		field := r.schema.LookUpField(s.Column)
+		if field == nil {
+			return false, fmt.Errorf("%s has no column %q", r.schema.Name, s.Column)
+		}
+		value, _ := field.ValueOf(ctx, rv)
+		return evaluate(value, s)
+	case Composite:
+		if !s.Or {
+			return r.matchAll(ctx, rv, s.Specs)
+		}
+		for _, sub := range s.Specs {
+			matched, err := r.match(ctx, rv, sub)
+			if err != nil || matched {
+				return matched, err
+			}
+		}
+		return len(s.Specs) == 0, nil
+	case Order, Preload:
+		return true, nil
+	default:
+		return false, fmt.Errorf("%w: %T", ErrUnsupportedSpecification, spec)
+	}
+}
+
+func (r *inMemoryRepository[TModel]) insert(ctx context.Context, model *TModel) error {
+	scope, err := r.tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(model).Elem()
+	if scope.field != nil && scope.scoped {
+		current, zero := scope.field.ValueOf(ctx, rv)
+		if !zero && current != scope.tenantID {
+			return fmt.Errorf("cannot create a %s row for tenant %v from tenant %s", r.schema.Name, current, scope.tenantID)
+		}
+	}
+
+	id := r.idOf(ctx, model)
+	if id == 0 {
+		r.nextID++
+		id = r.nextID
+		if err := r.primaryField().Set(ctx, rv, id); err != nil {
+			return err
+		}
+	} else if id > r.nextID {
+		r.nextID = id
+	}
+
+	now := r.now()
+	actor := ActorFromContext(ctx)
+	for name, value := range map[string]interface{}{"CreatedAt": now, "CreatedBy": actor} {
+		if field := r.schema.LookUpField(name); field != nil {
+			if _, zero := field.ValueOf(ctx, rv); zero {
+				if err := field.Set(ctx, rv, value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if scope.field != nil && scope.scoped {
+		if err := scope.field.Set(ctx, rv, scope.tenantID); err != nil {
+			return err
+		}
+	}
+	// Column defaults, e.g. the 'default' tenant, would otherwise be filled in by the database.
+	for _, field := range r.schema.Fields {
+		if field.DefaultValueInterface == nil {
+			continue
+		}
+		if _, zero := field.ValueOf(ctx, rv); zero {
+			if err := field.Set(ctx, rv, field.DefaultValueInterface); err != nil {
+				return err
+			}
+		}
+	}
+	return r.store(ctx, model)
+}
+
+func (r *inMemoryRepository[TModel]) store(ctx context.Context, model *TModel) error {
+	rv := reflect.ValueOf(model).Elem()
+	for name, value := range map[string]interface{}{"UpdatedAt": r.now(), "UpdatedBy": ActorFromContext(ctx)} {
+		if field := r.schema.LookUpField(name); field != nil {
+			if err := field.Set(ctx, rv, value); err != nil {
+				return err
+			}
+		}
+	}
+	return r.put(r.idOf(ctx, model), model)
+}
+
+func (r *inMemoryRepository[TModel]) put(id uint64, model *TModel) error {
+	raw, err := json.Marshal(model)
+	if err != nil {
+		return err
+	}
+	r.rows[id] = raw
+	return nil
+}
+
+// visible returns a copy of the row when it exists, isn't soft-deleted and belongs to
+// the tenant of scope.
+func (r *inMemoryRepository[TModel]) visible(ctx context.Context, scope tenantScope, id uint64) (*TModel, bool) {
+	raw, ok := r.rows[id]
+	if !ok {
+		return nil, false
+	}
+	var model TModel
+	if err := json.Unmarshal(raw, &model); err != nil {
+		return nil, false
+	}
+
+	rv := reflect.ValueOf(&model).Elem()
+	if field := r.schema.LookUpField("DeletedAt"); field != nil && field.FieldType == softDeleteType {
+		if _, zero := field.ValueOf(ctx, rv); !zero {
+			return nil, false
+		}
+	}
+	if scope.field != nil && scope.scoped {
+		if value, _ := scope.field.ValueOf(ctx, rv); value != scope.tenantID {
+			return nil, false
+		}
+	}
+	return &model, true
+}
+
+func (r *inMemoryRepository[TModel]) primaryField() *schema.Field {
+	return r.schema.PrioritizedPrimaryField
+}
+
+func (r *inMemoryRepository[TModel]) idOf(ctx context.Context, model *TModel) uint64 {
+	value, zero := r.primaryField().ValueOf(ctx, reflect.ValueOf(model).Elem())
+	if zero {
+		return 0
+	}
+	id, ok := toUint64(value)
+	if !ok {
+		return 0
+	}
+	return id
+}
+
+func evaluate(value interface{}, c Condition) (bool, error) {
+	value = deref(value)
+	switch c.Operator {
+	case OpIsNull:
+		return value == nil, nil
+	case OpIn:
+		for _, candidate := range toSlice(c.Value) {
+			if cmp, ok := compareValues(value, candidate); ok && cmp == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	case OpLike:
+		pattern, ok := c.Value.(string)
+		if !ok {
+			return false, fmt.Errorf("LIKE needs a string pattern, got %T", c.Value)
+		}
+		return likeToRegexp(pattern).MatchString(fmt.Sprint(value)), nil
+	}
+
+	cmp, ok := compareValues(value, c.Value)
+	if !ok {
+		return false, nil
+	}
+	switch c.Operator {
+	case OpEq:
+		return cmp == 0, nil
+	case OpNe:
+		return cmp != 0, nil
+	case OpGt:
+		return cmp > 0, nil
+	case OpGte:
+		return cmp >= 0, nil
+	case OpLt:
+		return cmp < 0, nil
+	case OpLte:
+		return cmp <= 0, nil
+	default:
+		return false, fmt.Errorf("%w: operator %s", ErrUnsupportedSpecification, c.Operator)
+	}
+}
+
+// compareValues orders two column values; ok is false when they aren't comparable,
+// which like SQL NULL never matches.
+func compareValues(a, b interface{}) (int, bool) {
+	a, b = deref(a), deref(b)
+	if a == nil || b == nil {
+		return 0, false
+	}
+
+	if at, ok := a.(time.Time); ok {
+		if bt, ok := b.(time.Time); ok {
+			return at.Compare(bt), true
+		}
+		return 0, false
+	}
+	if af, ok := toFloat64(a); ok {
+		if bf, ok := toFloat64(b); ok {
+			switch {
+			case af < bf:
+				return -1, true
+			case af > bf:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	if as, ok := a.(string); ok {
+		if bs, ok := b.(string); ok {
+			return strings.Compare(as, bs), true
+		}
+	}
+	if reflect.DeepEqual(a, b) {
+		return 0, true
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b)), true
+}
+
+func deref(value interface{}) interface{} {
+	rv := reflect.ValueOf(value)
+	for rv.IsValid() && rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+	return rv.Interface()
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+func toUint64(value interface{}) (uint64, bool) {
+	f, ok := toFloat64(deref(value))
+	if !ok || f < 0 {
+		return 0, false
+	}
+	return uint64(f), true
+}
+
+func likeToRegexp(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
@@ -0,0 +1,49 @@
+package core
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// PageRequest is a 1-based page number plus the page size.
+type PageRequest struct {
+	Page int
+	Size int
+}
+
+// Page holds one page of items together with the totals of the whole result set.
+type Page[TModel any] struct {
+	Items      []TModel
+	Page       int
+	Size       int
+	TotalItems int64
+	TotalPages int
+}
+
+func (p PageRequest) normalize() PageRequest {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.Size < 1 {
+		p.Size = DefaultPageSize
+	}
+	if p.Size > MaxPageSize {
+		p.Size = MaxPageSize
+	}
+	return p
+}
+
+func (p PageRequest) offset() int {
+	return (p.Page - 1) * p.Size
+}
+
+func newPage[TModel any](items []TModel, req PageRequest, total int64) *Page[TModel] {
+	totalPages := int((total + int64(req.Size) - 1) / int64(req.Size))
+	return &Page[TModel]{
+		Items:      items,
+		Page:       req.Page,
+		Size:       req.Size,
+		TotalItems: total,
+		TotalPages: totalPages,
+	}
+}
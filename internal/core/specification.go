@@ -0,0 +1,275 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Specification is a composable query condition understood by the repositories.
+type Specification interface {
+	Apply(db *gorm.DB) *gorm.DB
+}
+
+// Scope wraps a raw gorm scope, for queries the built-in specifications can't express.
+type Scope func(db *gorm.DB) *gorm.DB
+
+func (s Scope) Apply(db *gorm.DB) *gorm.DB {
+	return s(db)
+}
+
+type Operator string
+
+const (
+	OpEq     Operator = "="
+	OpNe     Operator = "<>"
+	OpGt     Operator = ">"
+	OpGte    Operator = ">="
+	OpLt     Operator = "<"
+	OpLte    Operator = "<="
+	OpIn     Operator = "IN"
+	OpLike   Operator = "LIKE"
+	OpIsNull Operator = "IS NULL"
+)
+
+// Condition compares a single column against a value.
+type Condition struct {
+	Column   string
+	Operator Operator
+	Value    interface{}
+}
+
+func (c Condition) Apply(db *gorm.DB) *gorm.DB {
+	return db.Where(c.Expression())
+}
+
+func (c Condition) Expression() clause.Expression {
+	column := clause.Column{Name: c.Column}
+	switch c.Operator {
+	case OpEq:
+		return clause.Eq{Column: column, Value: c.Value}
+	case OpNe:
+		return clause.Neq{Column: column, Value: c.Value}
+	case OpGt:
+		return clause.Gt{Column: column, Value: c.Value}
+	case OpGte:
+		return clause.Gte{Column: column, Value: c.Value}
+	case OpLt:
+		return clause.Lt{Column: column, Value: c.Value}
+	case OpLte:
+		return clause.Lte{Column: column, Value: c.Value}
+	case OpIn:
+		return clause.IN{Column: column, Values: toSlice(c.Value)}
+	case OpLike:
+		return clause.Like{Column: column, Value: c.Value}
+	case OpIsNull:
+		return clause.Eq{Column: column, Value: nil}
+	default:
+		return clause.Expr{SQL: fmt.Sprintf("? %s ?", c.Operator), Vars: []interface{}{column, c.Value}}
+	}
+}
+
+// Composite joins several specifications with AND / OR.
+type Composite struct {
+	Or    bool
+	Specs []Specification
+}
+
+func (c Composite) Apply(db *gorm.DB) *gorm.DB {
+	if len(c.Specs) == 0 {
+		return db
+	}
+	group := db.Session(&gorm.Session{NewDB: true})
+	for i, spec := range c.Specs {
+		sub := spec.Apply(db.Session(&gorm.Session{NewDB: true}))
+		if c.Or && i > 0 {
+			group = group.Or(sub)
+		} else {
+			group = group.Where(sub)
+		}
+	}
+	return db.Where(group)
+}
+
+// Order sorts the result by a column.
+type Order struct {
+	Column string
+	Desc   bool
+}
+
+func (o Order) Apply(db *gorm.DB) *gorm.DB {
+	return db.Order(clause.OrderByColumn{Column: clause.Column{Name: o.Column}, Desc: o.Desc})
+}
+
+// Preload eagerly loads an association of the model, optionally filtered by Specs.
+type Preload struct {
+	Association string
+	Specs       []Specification
+}
+
+func (p Preload) Apply(db *gorm.DB) *gorm.DB {
+	if len(p.Specs) == 0 {
+		return db.Preload(p.Association)
+	}
+	return db.Preload(p.Association, func(tx *gorm.DB) *gorm.DB {
+		return applySpecs(tx, p.Specs)
+	})
+}
+
+func Eq(column string, value interface{}) Specification {
+	return Condition{Column: column, Operator: OpEq, Value: value}
+}
+
+func Ne(column string, value interface{}) Specification {
+	return Condition{Column: column, Operator: OpNe, Value: value}
+}
+
+func Gt(column string, value interface{}) Specification {
+	return Condition{Column: column, Operator: OpGt, Value: value}
+}
+
+func Gte(column string, value interface{}) Specification {
+	return Condition{Column: column, Operator: OpGte, Value: value}
+}
+
+func Lt(column string, value interface{}) Specification {
+	return Condition{Column: column, Operator: OpLt, Value: value}
+}
+
+func Lte(column string, value interface{}) Specification {
+	return Condition{Column: column, Operator: OpLte, Value: value}
+}
+
+func In(column string, values interface{}) Specification {
+	return Condition{Column: column, Operator: OpIn, Value: values}
+}
+
+func Like(column string, pattern string) Specification {
+	return Condition{Column: column, Operator: OpLike, Value: pattern}
+}
+
+func IsNull(column string) Specification {
+	return Condition{Column: column, Operator: OpIsNull}
+}
+
+func And(specs ...Specification) Specification {
+	return Composite{Specs: specs}
+}
+
+func Or(specs ...Specification) Specification {
+	return Composite{Or: true, Specs: specs}
+}
+
+func OrderBy(column string) Specification {
+	return Order{Column: column}
+}
+
+func OrderByDesc(column string) Specification {
+	return Order{Column: column, Desc: true}
+}
+
+// With preloads an association, e.g. With("Comments", Eq("approved", true)). The
+// repository checks it against the model when the query runs.
+func With(association string, specs ...Specification) Specification {
+	return Preload{Association: association, Specs: specs}
+}
+
+// preloadSchemas caches the schemas WithAssociation checks against; only relationship
+// names are read, so the default naming strategy does.
+var preloadSchemas sync.Map
+
+// WithAssociation is With checked against the schema of TModel when built, every
+// segment of a nested "Comments.Author" included. It panics on an association TModel
+// doesn't have, the mistake being in the code rather than the input:
+//
+//	core.WithAssociation[PostModel]("Comments.Author", core.Eq("approved", true))
+func WithAssociation[TModel any](association string, specs ...Specification) Specification {
+	parsed, err := schema.Parse(new(TModel), &preloadSchemas, schema.NamingStrategy{})
+	if err != nil {
+		panic(fmt.Errorf("cannot parse schema of %T: %w", new(TModel), err))
+	}
+	if err := checkAssociation(parsed, association); err != nil {
+		panic(err)
+	}
+	return With(association, specs...)
+}
+
+func applySpecs(db *gorm.DB, specs []Specification) *gorm.DB {
+	for _, spec := range specs {
+		if spec != nil {
+			db = spec.Apply(db)
+		}
+	}
+	return db
+}
+
+// withoutOrdering drops Order and Preload specifications, counting doesn't need them.
+func withoutOrdering(specs []Specification) []Specification {
+	out := make([]Specification, 0, len(specs))
+	for _, spec := range specs {
+		switch spec.(type) {
+		case Order, Preload:
+			continue
+		}
+		out = append(out, spec)
+	}
+	return out
+}
+
+func toSlice(value interface{}) []interface{} {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return []interface{}{value}
+	}
+	out := make([]interface{}, rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
+	}
+	return out
+}
+
+// validatePreloads makes sure every With(...) targets a real association of the model.
+func validatePreloads(db *gorm.DB, model interface{}, specs []Specification) error {
+	var associations []string
+	for _, spec := range specs {
+		if p, ok := spec.(Preload); ok {
+			associations = append(associations, p.Association)
+		}
+	}
+	if len(associations) == 0 {
+		return nil
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+	for _, association := range associations {
+		if err := checkAssociation(stmt.Schema, association); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkAssociation follows a dotted association path from s; a clause.Associations
+// ending it stands for every association of that level.
+func checkAssociation(s *schema.Schema, association string) error {
+	if association == clause.Associations {
+		return nil
+	}
+	association = strings.TrimSuffix(association, "."+clause.Associations)
+	for _, name := range strings.Split(association, ".") {
+		relationship, ok := s.Relationships.Relations[name]
+		if !ok {
+			return fmt.Errorf("%w: %s has no association %q", ErrUnknownAssociation, s.Name, name)
+		}
+		s = relationship.FieldSchema
+	}
+	return nil
+}
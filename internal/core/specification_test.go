@@ -0,0 +1,97 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"agentic/commerce/internal/infrastructure/database"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+type author struct {
+	ID   uint64
+	Name string
+}
+
+type comment struct {
+	ID       uint64
+	PostID   uint64
+	AuthorID uint64
+	Author   author
+	Approved bool
+}
+
+type post struct {
+	ID       uint64
+	Title    string
+	Comments []comment
+}
+
+func TestWithAssociationChecksThePathWhenBuilt(t *testing.T) {
+	for _, association := range []string{"Comments", "Comments.Author", clause.Associations, "Comments." + clause.Associations} {
+		spec := WithAssociation[post](association, Eq("approved", true))
+		if preload, ok := spec.(Preload); !ok || preload.Association != association || len(preload.Specs) != 1 {
+			t.Fatalf("WithAssociation(%q) built %#v", association, spec)
+		}
+	}
+
+	for _, association := range []string{"Coments", "Comments.Writer", "Title"} {
+		t.Run(association, func(t *testing.T) {
+			defer func() {
+				err, _ := recover().(error)
+				if !errors.Is(err, ErrUnknownAssociation) {
+					t.Fatalf("WithAssociation(%q) didn't panic with ErrUnknownAssociation: %v", association, err)
+				}
+			}()
+			WithAssociation[post](association)
+		})
+	}
+}
+
+func TestRepositoriesCheckNestedPreloads(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	if err := db.AutoMigrate(&author{}, &post{}, &comment{}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	repository := NewBaseRepository[post](database.CreateGormDB(db))
+	created := &post{Title: "hello", Comments: []comment{
+		{Author: author{Name: "ann"}, Approved: true},
+		{Author: author{Name: "bob"}},
+	}}
+	if err := db.Create(created).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := repository.FindByID(ctx, created.ID, WithAssociation[post]("Comments.Author"), With("Comments", Eq("approved", true)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found.Comments) != 1 || found.Comments[0].Author.Name != "ann" {
+		t.Fatalf("preloaded %+v", found.Comments)
+	}
+
+	repositories := map[string]IBaseRepository[post]{
+		"gorm":      repository,
+		"in memory": NewInMemoryRepository[post]("default"),
+	}
+	for name, repository := range repositories {
+		if _, err := repository.FindAll(ctx, With("Comments.Writer")); !errors.Is(err, ErrUnknownAssociation) {
+			t.Fatalf("%s repository preloaded an unknown nested association: %v", name, err)
+		}
+	}
+}
@@ -32,3 +32,5 @@ func ResolveDBSliceResult[TModel any](models []TModel, db *gorm.DB) ([]TModel, e
 	}
 	return models, db.Error
 }
+
+var ErrUnknownAssociation = errors.New("unknown association")
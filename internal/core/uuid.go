@@ -0,0 +1,49 @@
+package core
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// UUID is a textual UUID, stored as uuid on Postgres and char(36) elsewhere.
+type UUID string
+
+func (UUID) GormDBDataType(db *gorm.DB, _ *schema.Field) string {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return "uuid"
+	case "mysql":
+		return "char(36)"
+	default:
+		return "text"
+	}
+}
+
+func (u *UUID) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*u = ""
+	case string:
+		*u = UUID(v)
+	case []byte:
+		*u = UUID(v)
+	default:
+		return fmt.Errorf("invalid UUID value: %T", value)
+	}
+	return nil
+}
+
+// Value stores the zero UUID as NULL, which Postgres accepts in a uuid column unlike "".
+func (u UUID) Value() (driver.Value, error) {
+	if u == "" {
+		return nil, nil
+	}
+	return string(u), nil
+}
+
+func (u UUID) String() string {
+	return string(u)
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type tagged struct {
+	ID   uint64
+	UUid UUID `gorm:"column:uuid"`
+}
+
+func TestUUIDStoresTheZeroValueAsNull(t *testing.T) {
+	if value, err := UUID("").Value(); err != nil || value != nil {
+		t.Fatalf("zero UUID is stored as %#v, %v", value, err)
+	}
+	id := "0190b6a2-7c1e-7d3a-9f00-1a2b3c4d5e6f"
+	if value, err := UUID(id).Value(); err != nil || value != id {
+		t.Fatalf("UUID is stored as %#v, %v", value, err)
+	}
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	if err := db.AutoMigrate(&tagged{}); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := []tagged{{}, {UUid: UUID(id)}}
+	if err := db.Create(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	var nulls int64
+	if err := db.Model(&tagged{}).Where("uuid IS NULL").Count(&nulls).Error; err != nil {
+		t.Fatal(err)
+	}
+	if nulls != 1 {
+		t.Fatalf("%d rows have a NULL uuid, want 1", nulls)
+	}
+
+	var loaded []tagged
+	if err := db.Order("id").Find(&loaded).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 2 || loaded[0].UUid != "" || loaded[1].UUid != UUID(id) {
+		t.Fatalf("loaded %+v", loaded)
+	}
+}
@@ -0,0 +1,105 @@
+package apikey
+
+import (
+	"agentic/commerce/internal/utils"
+	"agentic/commerce/pkg/apperror"
+	"agentic/commerce/pkg/logger"
+	"agentic/commerce/pkg/specs/api"
+
+	"github.com/labstack/echo/v4"
+)
+
+type IAPIKeyResource interface {
+	IssueAPIKey() echo.HandlerFunc
+	ListAPIKeys() echo.HandlerFunc
+	RotateAPIKey() echo.HandlerFunc
+	RevokeAPIKey() echo.HandlerFunc
+}
+
+type apiKeyResource struct {
+	APIKeyService IAPIKeyService
+	Logger        *logger.AppLogger
+}
+
+func NewAPIKeyResource(service IAPIKeyService, logger *logger.AppLogger) IAPIKeyResource {
+	return &apiKeyResource{
+		APIKeyService: service,
+		Logger:        logger.WithScope(apiKeyResource{}),
+	}
+}
+
+func (v *apiKeyResource) IssueAPIKey() echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		var req api.APIKeyIssueRequest
+		reqCtx := ctx.Request().Context()
+
+		err := ctx.Bind(&req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, apperror.ErrBadRequest, "Check your input: "+err.Error())
+		}
+
+		resp, err := v.APIKeyService.IssueAPIKey(reqCtx, &req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, err, "Cant issue the api key")
+		}
+
+		return utils.SuccessResponseWithMessage(ctx, "Store the key now, it is not shown again", resp)
+	}
+}
+
+func (v *apiKeyResource) ListAPIKeys() echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		var req api.APIKeyListRequest
+		reqCtx := ctx.Request().Context()
+
+		err := ctx.Bind(&req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, apperror.ErrBadRequest, "Check your input: "+err.Error())
+		}
+
+		resp, err := v.APIKeyService.ListAPIKeys(reqCtx, &req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, err, "Cant list the api keys")
+		}
+
+		return utils.SuccessResponse(ctx, resp)
+	}
+}
+
+func (v *apiKeyResource) RotateAPIKey() echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		var req api.APIKeyIDAwareRequest
+		reqCtx := ctx.Request().Context()
+
+		err := ctx.Bind(&req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, apperror.ErrBadRequest, "Check your input: "+err.Error())
+		}
+
+		resp, err := v.APIKeyService.RotateAPIKey(reqCtx, &req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, err, "Cant rotate the api key")
+		}
+
+		return utils.SuccessResponseWithMessage(ctx, "Store the key now, it is not shown again", resp)
+	}
+}
+
+func (v *apiKeyResource) RevokeAPIKey() echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		var req api.APIKeyIDAwareRequest
+		reqCtx := ctx.Request().Context()
+
+		err := ctx.Bind(&req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, apperror.ErrBadRequest, "Check your input: "+err.Error())
+		}
+
+		err = v.APIKeyService.RevokeAPIKey(reqCtx, &req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, err, "Cant revoke the api key")
+		}
+
+		return utils.SuccessResponse[any](ctx, nil)
+	}
+}
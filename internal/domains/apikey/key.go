@@ -0,0 +1,51 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// keyScheme starts every key, so leaked keys are easy to spot and scan for.
+const keyScheme = "gsk"
+
+const (
+	prefixBytes = 6
+	secretBytes = 32
+)
+
+// generateKey returns a new key "gsk_<prefix>_<secret>" and its prefix; the prefix is
+// hex, the secret base64url.
+func generateKey() (key string, prefix string, err error) {
+	raw := make([]byte, prefixBytes+secretBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	prefix = hex.EncodeToString(raw[:prefixBytes])
+	secret := base64.RawURLEncoding.EncodeToString(raw[prefixBytes:])
+	return keyScheme + "_" + prefix + "_" + secret, prefix, nil
+}
+
+// prefixOf returns the prefix of a well-formed key.
+func prefixOf(key string) (string, bool) {
+	parts := strings.SplitN(key, "_", 3)
+	if len(parts) != 3 || parts[0] != keyScheme || len(parts[1]) != 2*prefixBytes || parts[2] == "" {
+		return "", false
+	}
+	if _, err := hex.DecodeString(parts[1]); err != nil {
+		return "", false
+	}
+	return parts[1], true
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func matchesHash(key, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashKey(key)), []byte(hash)) == 1
+}
@@ -0,0 +1,35 @@
+package apikey
+
+import (
+	"strings"
+
+	"agentic/commerce/internal/core"
+	"agentic/commerce/pkg/specs/api"
+)
+
+func mapToAPIKeyItem(model *APIKeyModel) api.APIKeyItemResponse {
+	return api.APIKeyItemResponse{
+		ID:         model.Prefix,
+		Name:       model.Name,
+		Scopes:     strings.Fields(model.Scopes),
+		ExpiresAt:  model.ExpiresAt,
+		LastUsedAt: model.LastUsedAt,
+		RevokedAt:  model.RevokedAt,
+		ReplacedBy: model.ReplacedBy,
+		CreatedBy:  model.CreatedBy,
+		CreatedAt:  model.CreatedAt,
+	}
+}
+
+func mapToAPIKeyPage(page *core.Page[APIKeyModel]) *api.ApiPaginateResponse[api.APIKeyItemResponse] {
+	items := make([]api.APIKeyItemResponse, 0, len(page.Items))
+	for i := range page.Items {
+		items = append(items, mapToAPIKeyItem(&page.Items[i]))
+	}
+
+	return &api.ApiPaginateResponse[api.APIKeyItemResponse]{
+		TotalPage:   uint(page.TotalPages),
+		CurrentPage: uint(page.Page),
+		Items:       items,
+	}
+}
@@ -0,0 +1,27 @@
+package apikey
+
+import (
+	"time"
+
+	"agentic/commerce/internal/core"
+)
+
+// APIKeyModel is an API key of a machine client. Only the SHA-256 of the key is
+// stored; Prefix is the public part of the key the row is looked up by.
+type APIKeyModel struct {
+	core.BaseModel
+	Name   string `gorm:"Column:name;size:128;not null"`
+	Prefix string `gorm:"Column:prefix;size:16;not null;uniqueIndex"`
+	Hash   string `gorm:"Column:hash;size:64;not null" audit:"redact"`
+	// Scopes is space separated, like the scope claim of a token.
+	Scopes     string     `gorm:"Column:scopes"`
+	ExpiresAt  *time.Time `gorm:"Column:expires_at"`
+	LastUsedAt *time.Time `gorm:"Column:last_used_at"`
+	RevokedAt  *time.Time `gorm:"Column:revoked_at"`
+	// ReplacedBy is the prefix of the key a rotation issued in place of this one.
+	ReplacedBy string `gorm:"Column:replaced_by;size:16"`
+}
+
+func (APIKeyModel) TableName() string {
+	return "api_keys"
+}
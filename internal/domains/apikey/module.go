@@ -0,0 +1,31 @@
+package apikey
+
+import (
+	"agentic/commerce/internal/infrastructure/auth"
+	"agentic/commerce/internal/infrastructure/database"
+
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module(
+	"apikey",
+	fx.Provide(NewAPIKeyRepository),
+	fx.Provide(NewAPIKeyService),
+	fx.Provide(asResolver),
+	fx.Invoke(RegisterRoutes),
+	database.AsModel(&APIKeyModel{}),
+)
+
+// InMemoryModule is Module without a database, added to testkit.Module by the tests
+// that need API keys.
+var InMemoryModule = fx.Module(
+	"apikey",
+	fx.Provide(NewInMemoryAPIKeyRepository),
+	fx.Provide(NewAPIKeyService),
+	fx.Provide(asResolver),
+	fx.Invoke(RegisterRoutes),
+)
+
+func asResolver(s IAPIKeyService) auth.APIKeyResolver {
+	return s
+}
@@ -0,0 +1,73 @@
+package apikey
+
+import (
+	"context"
+	"time"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/core"
+	"agentic/commerce/internal/domains/audit"
+	"agentic/commerce/internal/infrastructure/database"
+)
+
+type IAPIKeyRepository interface {
+	core.IBaseRepository[APIKeyModel]
+	GetByPrefix(ctx context.Context, prefix string) (*APIKeyModel, error)
+	// TouchLastUsed sets last_used_at to now unless it is already later than now-interval,
+	// so a busy key costs one write per interval.
+	TouchLastUsed(ctx context.Context, id uint64, now time.Time, interval time.Duration) error
+}
+
+type apiKeyRepository struct {
+	core.IBaseRepository[APIKeyModel]
+	database database.GormDB
+}
+
+func NewAPIKeyRepository(database database.GormDB) IAPIKeyRepository {
+	return &apiKeyRepository{
+		IBaseRepository: core.NewBaseRepository[APIKeyModel](database),
+		database:        database,
+	}
+}
+
+func (db *apiKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*APIKeyModel, error) {
+	return db.FindOne(ctx, core.Eq("prefix", prefix))
+}
+
+func (db *apiKeyRepository) TouchLastUsed(ctx context.Context, id uint64, now time.Time, interval time.Duration) error {
+	return db.database(audit.WithoutRecording(ctx)).
+		Model(&APIKeyModel{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-interval)).
+		UpdateColumn("last_used_at", now).
+		Error
+}
+
+// inMemoryAPIKeyRepository tracks the last use through the base repository, which has
+// no conditional update.
+type inMemoryAPIKeyRepository struct {
+	*apiKeyRepository
+}
+
+// NewInMemoryAPIKeyRepository backs the repository with core.NewInMemoryRepository, for tests.
+func NewInMemoryAPIKeyRepository(tenancyCfg *config.TenancyConfig) IAPIKeyRepository {
+	var defaultTenant string
+	if tenancyCfg != nil {
+		defaultTenant = tenancyCfg.DefaultTenant
+	}
+	return &inMemoryAPIKeyRepository{
+		apiKeyRepository: &apiKeyRepository{
+			IBaseRepository: core.NewInMemoryRepository[APIKeyModel](defaultTenant),
+		},
+	}
+}
+
+func (db *inMemoryAPIKeyRepository) TouchLastUsed(ctx context.Context, id uint64, now time.Time, interval time.Duration) error {
+	model, err := db.FindByID(ctx, id)
+	if err != nil || model == nil {
+		return err
+	}
+	if model.LastUsedAt != nil && !model.LastUsedAt.Before(now.Add(-interval)) {
+		return nil
+	}
+	return db.Update(ctx, &APIKeyModel{BaseModel: core.BaseModel{ID: id}, LastUsedAt: &now})
+}
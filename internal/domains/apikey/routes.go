@@ -0,0 +1,39 @@
+package apikey
+
+import (
+	"go/types"
+
+	"agentic/commerce/internal/infrastructure/authz"
+	"agentic/commerce/internal/interfaces/http"
+	"agentic/commerce/internal/interfaces/http/middleware"
+	"agentic/commerce/pkg/logger"
+	"agentic/commerce/pkg/specs/api"
+)
+
+func RegisterRoutes(s *http.Server, apiKeyService IAPIKeyService, policy *authz.Policy, logger *logger.AppLogger) *http.Server {
+	apiKeyResourceObj := NewAPIKeyResource(apiKeyService, logger)
+
+	apis := s.Router.Group("/admin/api-keys")
+
+	http.AddRoute[api.APIKeyIssueRequest, api.APIResponse[api.APIKeyIssueResponse]](s,
+		apis.POST("", apiKeyResourceObj.IssueAPIKey(), middleware.RequirePermission(policy, "apikey:manage:any")),
+		middleware.SecurityAdmin,
+	)
+
+	http.AddRoute[api.APIKeyListRequest, api.APIResponse[api.ApiPaginateResponse[api.APIKeyItemResponse]]](s,
+		apis.GET("", apiKeyResourceObj.ListAPIKeys(), middleware.RequirePermission(policy, "apikey:manage:any")),
+		middleware.SecurityAdmin,
+	)
+
+	http.AddRoute[api.APIKeyIDAwareRequest, api.APIResponse[api.APIKeyIssueResponse]](s,
+		apis.POST("/:id/rotate", apiKeyResourceObj.RotateAPIKey(), middleware.RequirePermission(policy, "apikey:manage:any")),
+		middleware.SecurityAdmin,
+	)
+
+	http.AddRoute[api.APIKeyIDAwareRequest, api.APIResponse[types.Nil]](s,
+		apis.DELETE("/:id", apiKeyResourceObj.RevokeAPIKey(), middleware.RequirePermission(policy, "apikey:manage:any")),
+		middleware.SecurityAdmin,
+	)
+
+	return s
+}
@@ -0,0 +1,269 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/core"
+	"agentic/commerce/internal/infrastructure/auth"
+	"agentic/commerce/internal/infrastructure/authz"
+	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/internal/infrastructure/tenancy"
+	"agentic/commerce/pkg/apperror"
+	"agentic/commerce/pkg/logger"
+	"agentic/commerce/pkg/specs/api"
+
+	"github.com/samber/lo"
+)
+
+const defaultLastUsedInterval = time.Minute
+
+var manageKeys = authz.MustParsePermission("apikey:manage:any")
+
+type IAPIKeyService interface {
+	auth.APIKeyResolver
+	IssueAPIKey(ctx context.Context, req *api.APIKeyIssueRequest) (*api.APIKeyIssueResponse, error)
+	ListAPIKeys(ctx context.Context, req *api.APIKeyListRequest) (*api.ApiPaginateResponse[api.APIKeyItemResponse], error)
+	// RotateAPIKey issues a key with the name and scopes of the given one, which keeps
+	// working for the configured grace period.
+	RotateAPIKey(ctx context.Context, req *api.APIKeyIDAwareRequest) (*api.APIKeyIssueResponse, error)
+	RevokeAPIKey(ctx context.Context, req *api.APIKeyIDAwareRequest) error
+}
+
+type apiKeyService struct {
+	repository IAPIKeyRepository
+	txManager  database.ITransactionManager
+	policy     *authz.Policy
+	cfg        config.APIKeyConfig
+	logger     *logger.AppLogger
+	now        func() time.Time
+}
+
+func NewAPIKeyService(
+	logger *logger.AppLogger,
+	repository IAPIKeyRepository,
+	txManager database.ITransactionManager,
+	policy *authz.Policy,
+	authCfg *config.AuthConfig,
+) IAPIKeyService {
+	var cfg config.APIKeyConfig
+	if authCfg != nil {
+		cfg = authCfg.APIKeys
+	}
+	if cfg.LastUsedInterval <= 0 {
+		cfg.LastUsedInterval = defaultLastUsedInterval
+	}
+	return &apiKeyService{
+		repository: repository,
+		txManager:  txManager,
+		policy:     policy,
+		cfg:        cfg,
+		logger:     logger.WithScope(&apiKeyService{}),
+		now:        time.Now,
+	}
+}
+
+func (s *apiKeyService) IssueAPIKey(ctx context.Context, req *api.APIKeyIssueRequest) (*api.APIKeyIssueResponse, error) {
+	if err := s.policy.Require(ctx, manageKeys); err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 128 {
+		return nil, apperror.ErrValidation
+	}
+	scopes, ok := normalizeScopes(req.Scopes)
+	if !ok {
+		return nil, apperror.ErrValidation
+	}
+	// the scopes that are permissions are granted to the key, only those held can be
+	if err := s.policy.RequireAll(ctx, authz.ParsePermissions(req.Scopes)); err != nil {
+		return nil, err
+	}
+	now := s.now()
+	expiresAt := s.defaultExpiry(now)
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(now) {
+			return nil, apperror.ErrValidation
+		}
+		expiresAt = lo.ToPtr(req.ExpiresAt.UTC())
+	}
+
+	model, key, err := s.create(ctx, name, scopes, expiresAt)
+	if err != nil {
+		s.logger.Error("cannot issue api key", err)
+		return nil, database.ResolveError(err)
+	}
+	return &api.APIKeyIssueResponse{APIKeyItemResponse: mapToAPIKeyItem(model), Key: key}, nil
+}
+
+func (s *apiKeyService) ListAPIKeys(ctx context.Context, req *api.APIKeyListRequest) (*api.ApiPaginateResponse[api.APIKeyItemResponse], error) {
+	if err := s.policy.Require(ctx, manageKeys); err != nil {
+		return nil, err
+	}
+	specs := []core.Specification{core.OrderByDesc("id")}
+	if !req.IncludeRevoked {
+		specs = append(specs, core.IsNull("revoked_at"))
+	}
+
+	page, err := s.repository.Paginate(ctx, core.PageRequest{Page: req.Page, Size: req.Size}, specs...)
+	if err != nil {
+		s.logger.Error("cannot list api keys", err)
+		return nil, database.ResolveError(err)
+	}
+	return mapToAPIKeyPage(page), nil
+}
+
+func (s *apiKeyService) RotateAPIKey(ctx context.Context, req *api.APIKeyIDAwareRequest) (*api.APIKeyIssueResponse, error) {
+	if err := s.policy.Require(ctx, manageKeys); err != nil {
+		return nil, err
+	}
+
+	var issued *APIKeyModel
+	var key string
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		old, err := s.find(ctx, req.ID)
+		if err != nil {
+			return err
+		}
+		if old.RevokedAt != nil || old.ReplacedBy != "" {
+			return apperror.ErrBadRequest
+		}
+
+		now := s.now()
+		issued, key, err = s.create(ctx, old.Name, old.Scopes, s.defaultExpiry(now))
+		if err != nil {
+			return err
+		}
+
+		graceEnd := now.Add(s.cfg.RotationGrace).UTC()
+		if old.ExpiresAt == nil || old.ExpiresAt.After(graceEnd) {
+			old.ExpiresAt = &graceEnd
+		}
+		old.ReplacedBy = issued.Prefix
+		return s.repository.Update(ctx, old)
+	})
+	if err != nil {
+		return nil, s.resolveError("cannot rotate api key", err)
+	}
+	return &api.APIKeyIssueResponse{APIKeyItemResponse: mapToAPIKeyItem(issued), Key: key}, nil
+}
+
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, req *api.APIKeyIDAwareRequest) error {
+	if err := s.policy.Require(ctx, manageKeys); err != nil {
+		return err
+	}
+	model, err := s.find(ctx, req.ID)
+	if err != nil {
+		return s.resolveError("cannot find api key", err)
+	}
+	if model.RevokedAt != nil {
+		return nil
+	}
+	model.RevokedAt = lo.ToPtr(s.now().UTC())
+	if err := s.repository.Update(ctx, model); err != nil {
+		return s.resolveError("cannot revoke api key", err)
+	}
+	return nil
+}
+
+// ResolveAPIKey looks the key up across tenants, its principal acting in the tenant the
+// key was issued in. A key unknown to a replica is looked up again on the primary, it
+// may have just been issued.
+func (s *apiKeyService) ResolveAPIKey(ctx context.Context, key string) (*auth.Principal, error) {
+	prefix, ok := prefixOf(key)
+	if !ok {
+		return nil, auth.ErrInvalidAPIKey
+	}
+	ctx = tenancy.WithoutTenantScope(ctx)
+
+	model, err := s.repository.GetByPrefix(ctx, prefix)
+	if err == nil && model == nil {
+		model, err = s.repository.GetByPrefix(database.WithPrimary(ctx), prefix)
+	}
+	if err != nil {
+		s.logger.Error("cannot look up api key", err)
+		return nil, database.ResolveError(err)
+	}
+	if model == nil || !matchesHash(key, model.Hash) || model.RevokedAt != nil {
+		return nil, auth.ErrInvalidAPIKey
+	}
+	now := s.now()
+	if model.ExpiresAt != nil && !now.Before(*model.ExpiresAt) {
+		return nil, auth.ErrAPIKeyExpired
+	}
+
+	if model.LastUsedAt == nil || now.Sub(*model.LastUsedAt) >= s.cfg.LastUsedInterval {
+		if err := s.repository.TouchLastUsed(ctx, model.ID, now.UTC(), s.cfg.LastUsedInterval); err != nil {
+			s.logger.Warn("cannot track the last use of api key {}: {}", model.Prefix, err)
+		}
+	}
+
+	return &auth.Principal{
+		Kind:      auth.PrincipalAPIKey,
+		Subject:   "apikey:" + model.Prefix,
+		Scopes:    strings.Fields(model.Scopes),
+		Tenant:    model.TenantID,
+		ExpiresAt: lo.FromPtr(model.ExpiresAt),
+	}, nil
+}
+
+func (s *apiKeyService) create(ctx context.Context, name, scopes string, expiresAt *time.Time) (*APIKeyModel, string, error) {
+	key, prefix, err := generateKey()
+	if err != nil {
+		return nil, "", err
+	}
+	model := &APIKeyModel{
+		Name:      name,
+		Prefix:    prefix,
+		Hash:      hashKey(key),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.repository.Create(ctx, model); err != nil {
+		return nil, "", err
+	}
+	return model, key, nil
+}
+
+func (s *apiKeyService) find(ctx context.Context, prefix string) (*APIKeyModel, error) {
+	model, err := s.repository.GetByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if model == nil {
+		return nil, apperror.ErrNotFound
+	}
+	return model, nil
+}
+
+func (s *apiKeyService) defaultExpiry(now time.Time) *time.Time {
+	if s.cfg.DefaultTTL <= 0 {
+		return nil
+	}
+	return lo.ToPtr(now.Add(s.cfg.DefaultTTL).UTC())
+}
+
+// resolveError passes apperrors through and logs and maps database errors.
+func (s *apiKeyService) resolveError(message string, err error) error {
+	var appErr *apperror.ErrorWithStatus
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	s.logger.Error(message, err)
+	return database.ResolveError(err)
+}
+
+// normalizeScopes joins the scopes with spaces, dropping duplicates; a scope can't
+// contain whitespace.
+func normalizeScopes(scopes []string) (string, bool) {
+	for _, scope := range scopes {
+		if scope == "" || strings.ContainsFunc(scope, unicode.IsSpace) {
+			return "", false
+		}
+	}
+	return strings.Join(lo.Uniq(scopes), " "), true
+}
@@ -0,0 +1,309 @@
+package apikey
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/infrastructure/auth"
+	"agentic/commerce/internal/infrastructure/tenancy"
+	internalhttp "agentic/commerce/internal/interfaces/http"
+	"agentic/commerce/internal/interfaces/http/middleware"
+	"agentic/commerce/internal/testkit"
+	"agentic/commerce/pkg/apperror"
+	"agentic/commerce/pkg/specs/api"
+
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+// harness serves the API keys on testkit.Module with a clock the test moves.
+type harness struct {
+	t          *testing.T
+	server     *internalhttp.Server
+	service    IAPIKeyService
+	repository IAPIKeyRepository
+	now        time.Time
+	adminToken string
+}
+
+func newHarness(t *testing.T, apiKeys config.APIKeyConfig) *harness {
+	t.Helper()
+	cfg := testkit.Config()
+	cfg.Auth.APIKeys = apiKeys
+
+	h := &harness{t: t, now: time.Now().UTC().Truncate(time.Second)}
+	fxtest.New(t,
+		testkit.Module,
+		InMemoryModule,
+		fx.Replace(cfg),
+		fx.Populate(&h.server, &h.service, &h.repository),
+		fx.NopLogger,
+	).RequireStart()
+	h.service.(*apiKeyService).now = func() time.Time { return h.now }
+
+	token, err := testkit.Token(1, auth.RoleAdmin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.adminToken = token
+	return h
+}
+
+// call sends a request with a bearer token and an API key, either may be empty.
+func (h *harness) call(method, path, token, apiKey, body string) (int, json.RawMessage) {
+	h.t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if apiKey != "" {
+		req.Header.Set(middleware.APIKeyHeader, apiKey)
+	}
+	rec := httptest.NewRecorder()
+	h.server.Router.ServeHTTP(rec, req)
+
+	var res struct {
+		Data json.RawMessage `json:"data"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &res)
+	return rec.Code, res.Data
+}
+
+// issue issues a key through the admin API.
+func (h *harness) issue(scopes ...string) api.APIKeyIssueResponse {
+	h.t.Helper()
+	body, _ := json.Marshal(api.APIKeyIssueRequest{Name: "ci", Scopes: scopes})
+	status, data := h.call(http.MethodPost, "/admin/api-keys", h.adminToken, "", string(body))
+	var issued api.APIKeyIssueResponse
+	if status != http.StatusOK || json.Unmarshal(data, &issued) != nil || issued.Key == "" {
+		h.t.Fatalf("issue answered %d %s", status, data)
+	}
+	return issued
+}
+
+func (h *harness) rotate(id string) api.APIKeyIssueResponse {
+	h.t.Helper()
+	status, data := h.call(http.MethodPost, "/admin/api-keys/"+id+"/rotate", h.adminToken, "", "")
+	var rotated api.APIKeyIssueResponse
+	if status != http.StatusOK || json.Unmarshal(data, &rotated) != nil || rotated.Key == "" {
+		h.t.Fatalf("rotate answered %d %s", status, data)
+	}
+	return rotated
+}
+
+// list lists the posts of user 1 with an API key.
+func (h *harness) list(apiKey string) int {
+	h.t.Helper()
+	status, _ := h.call(http.MethodGet, "/metadata/list?user_id=1", "", apiKey, "")
+	return status
+}
+
+func TestIssuedKeysAreStoredHashed(t *testing.T) {
+	h := newHarness(t, config.APIKeyConfig{})
+	issued := h.issue("metadata:read:any")
+
+	prefix, ok := prefixOf(issued.Key)
+	if !ok || prefix != issued.ID {
+		t.Fatalf("key %q doesn't carry its id %q", issued.Key, issued.ID)
+	}
+	model, err := h.repository.GetByPrefix(context.Background(), issued.ID)
+	if err != nil || model == nil {
+		t.Fatalf("the key wasn't stored: %v", err)
+	}
+	secret := strings.SplitN(issued.Key, "_", 3)[2]
+	if model.Hash != hashKey(issued.Key) || strings.Contains(model.Hash, secret) || !matchesHash(issued.Key, model.Hash) {
+		t.Fatalf("stored %+v for key %q", model, issued.Key)
+	}
+	if matchesHash(issued.Key+"x", model.Hash) {
+		t.Fatal("another key matched the hash")
+	}
+
+	status, data := h.call(http.MethodGet, "/admin/api-keys", h.adminToken, "", "")
+	if status != http.StatusOK || strings.Contains(string(data), secret) || strings.Contains(string(data), model.Hash) {
+		t.Fatalf("the key list answered %d %s", status, data)
+	}
+}
+
+func TestPrefixOfRejectsMalformedKeys(t *testing.T) {
+	key, prefix, err := generateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := prefixOf(key); !ok || got != prefix {
+		t.Fatalf("prefixOf(%q) = %q, %t", key, got, ok)
+	}
+	malformed := []string{
+		"",
+		"gsk_" + prefix,
+		"gsk_" + prefix + "_",
+		"abc_" + prefix + "_secret",
+		"gsk_short_secret",
+		"gsk_zzzzzzzzzzzz_secret",
+	}
+	for _, key := range malformed {
+		if _, ok := prefixOf(key); ok {
+			t.Errorf("prefixOf accepted %q", key)
+		}
+	}
+}
+
+func TestAPIKeysExpire(t *testing.T) {
+	h := newHarness(t, config.APIKeyConfig{DefaultTTL: time.Hour, LastUsedInterval: time.Minute})
+	issued := h.issue("metadata:read:any")
+	if issued.ExpiresAt == nil || !issued.ExpiresAt.Equal(h.now.Add(time.Hour)) {
+		t.Fatalf("the key expires at %v, want the default TTL", issued.ExpiresAt)
+	}
+
+	if status := h.list(issued.Key); status != http.StatusOK {
+		t.Fatalf("a valid key answered %d", status)
+	}
+	model, err := h.repository.GetByPrefix(context.Background(), issued.ID)
+	if err != nil || model.LastUsedAt == nil || !model.LastUsedAt.Equal(h.now) {
+		t.Fatalf("the use wasn't tracked: %+v, %v", model, err)
+	}
+
+	h.now = h.now.Add(time.Hour)
+	if _, err := h.service.ResolveAPIKey(context.Background(), issued.Key); !errors.Is(err, auth.ErrAPIKeyExpired) {
+		t.Fatalf("expected ErrAPIKeyExpired, got %v", err)
+	}
+	if status := h.list(issued.Key); status != http.StatusUnauthorized {
+		t.Fatalf("an expired key answered %d", status)
+	}
+
+	past, _ := json.Marshal(api.APIKeyIssueRequest{Name: "ci", ExpiresAt: &issued.CreatedAt})
+	if status, _ := h.call(http.MethodPost, "/admin/api-keys", h.adminToken, "", string(past)); status != http.StatusBadRequest {
+		t.Fatalf("a key expiring in the past answered %d", status)
+	}
+}
+
+func TestAPIKeyScopesAreEnforced(t *testing.T) {
+	h := newHarness(t, config.APIKeyConfig{})
+	reader := h.issue("metadata:read:any")
+	unscoped := h.issue()
+
+	if status := h.list(reader.Key); status != http.StatusOK {
+		t.Fatalf("a key with the scope answered %d", status)
+	}
+	if status := h.list(unscoped.Key); status != http.StatusForbidden {
+		t.Fatalf("a key without the scope answered %d", status)
+	}
+	status, _ := h.call(http.MethodPost, "/metadata", "", reader.Key, `{"user_id":"1","meta_data":{}}`)
+	if status != http.StatusForbidden {
+		t.Fatalf("a read-only key created a post: %d", status)
+	}
+	// a key acts in no one's name, scopes limited to owned resources grant nothing
+	if status := h.list(h.issue("metadata:read:own").Key); status != http.StatusForbidden {
+		t.Fatalf("an own-scoped key answered %d", status)
+	}
+}
+
+func TestIssuersCannotGrantScopesTheyLack(t *testing.T) {
+	cfg := testkit.Config()
+	cfg.Auth.Roles = map[string][]string{
+		auth.RoleAdmin: {"*:*:any"},
+		"user":         {"metadata:read:own"},
+		"keymaster":    {"apikey:manage:any", "metadata:read:any"},
+	}
+	cfg.Auth.DefaultRoles = []string{"user"}
+	var service IAPIKeyService
+	fxtest.New(t, testkit.Module, InMemoryModule, fx.Replace(cfg), fx.Populate(&service), fx.NopLogger).RequireStart()
+
+	ctx := tenancy.WithTenant(auth.WithPrincipal(context.Background(), &auth.Principal{
+		Kind: auth.PrincipalUser, Subject: "5", UserID: 5, Roles: []string{"keymaster"},
+	}), "default")
+	if _, err := service.IssueAPIKey(ctx, &api.APIKeyIssueRequest{Name: "ci", Scopes: []string{"metadata:read:any"}}); err != nil {
+		t.Fatalf("a held scope couldn't be granted: %v", err)
+	}
+	_, err := service.IssueAPIKey(ctx, &api.APIKeyIssueRequest{Name: "ci", Scopes: []string{"metadata:delete:any"}})
+	if !errors.Is(err, apperror.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+}
+
+func TestRotatedKeysStopWorking(t *testing.T) {
+	h := newHarness(t, config.APIKeyConfig{})
+	old := h.issue("metadata:read:any")
+	rotated := h.rotate(old.ID)
+
+	if status := h.list(old.Key); status != http.StatusUnauthorized {
+		t.Fatalf("the rotated key answered %d", status)
+	}
+	if status := h.list(rotated.Key); status != http.StatusOK {
+		t.Fatalf("the new key answered %d", status)
+	}
+	if rotated.Name != old.Name || len(rotated.Scopes) != 1 || rotated.Scopes[0] != "metadata:read:any" {
+		t.Fatalf("the new key is %+v", rotated)
+	}
+	if status, _ := h.call(http.MethodPost, "/admin/api-keys/"+old.ID+"/rotate", h.adminToken, "", ""); status != http.StatusBadRequest {
+		t.Fatalf("rotating a rotated key answered %d", status)
+	}
+}
+
+func TestRotatedKeysWorkThroughTheGracePeriod(t *testing.T) {
+	h := newHarness(t, config.APIKeyConfig{RotationGrace: time.Minute})
+	old := h.issue("metadata:read:any")
+	rotated := h.rotate(old.ID)
+
+	if status := h.list(old.Key); status != http.StatusOK {
+		t.Fatalf("the rotated key answered %d within the grace period", status)
+	}
+	h.now = h.now.Add(time.Minute)
+	if status := h.list(old.Key); status != http.StatusUnauthorized {
+		t.Fatalf("the rotated key answered %d after the grace period", status)
+	}
+	if status := h.list(rotated.Key); status != http.StatusOK {
+		t.Fatalf("the new key answered %d", status)
+	}
+}
+
+func TestRevokedKeysStopWorking(t *testing.T) {
+	h := newHarness(t, config.APIKeyConfig{})
+	issued := h.issue("metadata:read:any")
+
+	if status, _ := h.call(http.MethodDelete, "/admin/api-keys/"+issued.ID, h.adminToken, "", ""); status != http.StatusOK {
+		t.Fatalf("revoke answered %d", status)
+	}
+	if _, err := h.service.ResolveAPIKey(context.Background(), issued.Key); !errors.Is(err, auth.ErrInvalidAPIKey) {
+		t.Fatalf("expected ErrInvalidAPIKey, got %v", err)
+	}
+	if status := h.list(issued.Key); status != http.StatusUnauthorized {
+		t.Fatalf("a revoked key answered %d", status)
+	}
+}
+
+func TestMiddlewareAcceptsATokenOrAnAPIKey(t *testing.T) {
+	h := newHarness(t, config.APIKeyConfig{})
+	key := h.issue("metadata:read:any").Key
+	token, err := testkit.Token(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		apiKey string
+		want   int
+	}{
+		{"token", token, "", http.StatusOK},
+		{"api key", "", key, http.StatusOK},
+		{"both", token, key, http.StatusUnauthorized},
+		{"neither", "", "", http.StatusUnauthorized},
+		{"malformed key", "", "gsk_not_a_key", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, data := h.call(http.MethodGet, "/metadata/list?user_id=1", tt.token, tt.apiKey, ""); status != tt.want {
+				t.Fatalf("answered %d %s, want %d", status, data, tt.want)
+			}
+		})
+	}
+}
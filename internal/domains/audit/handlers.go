@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"agentic/commerce/internal/utils"
+	"agentic/commerce/pkg/apperror"
+	"agentic/commerce/pkg/logger"
+	"agentic/commerce/pkg/specs/api"
+
+	"github.com/labstack/echo/v4"
+)
+
+type IAuditResource interface {
+	ListAuditLogs() echo.HandlerFunc
+}
+
+type auditResource struct {
+	AuditService IAuditService
+	Logger       *logger.AppLogger
+}
+
+func NewAuditResource(service IAuditService, logger *logger.AppLogger) IAuditResource {
+	return &auditResource{
+		AuditService: service,
+		Logger:       logger.WithScope(auditResource{}),
+	}
+}
+
+func (v *auditResource) ListAuditLogs() echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		var req api.AuditLogListRequest
+		reqCtx := ctx.Request().Context()
+
+		err := ctx.Bind(&req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, apperror.ErrBadRequest, "Check your input: "+err.Error())
+		}
+
+		resp, err := v.AuditService.ListAuditLogs(reqCtx, &req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, err, "Cant list the audit logs")
+		}
+
+		return utils.SuccessResponse(ctx, resp)
+	}
+}
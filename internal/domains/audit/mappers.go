@@ -0,0 +1,28 @@
+package audit
+
+import (
+	"agentic/commerce/internal/core"
+	"agentic/commerce/pkg/specs/api"
+)
+
+func mapToAuditLogPage(page *core.Page[AuditLogModel]) *api.ApiPaginateResponse[api.AuditLogItemResponse] {
+	items := make([]api.AuditLogItemResponse, 0, len(page.Items))
+	for _, item := range page.Items {
+		items = append(items, api.AuditLogItemResponse{
+			ID:        item.ID,
+			Entity:    item.Entity,
+			EntityID:  item.EntityID,
+			Action:    item.Action.Value,
+			Actor:     item.Actor,
+			Before:    api.NewJSONObject(item.Before),
+			After:     api.NewJSONObject(item.After),
+			CreatedAt: item.CreatedAt,
+		})
+	}
+
+	return &api.ApiPaginateResponse[api.AuditLogItemResponse]{
+		TotalPage:   uint(page.TotalPages),
+		CurrentPage: uint(page.Page),
+		Items:       items,
+	}
+}
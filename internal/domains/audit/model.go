@@ -0,0 +1,39 @@
+package audit
+
+import (
+	"time"
+
+	"agentic/commerce/internal/core"
+	"agentic/commerce/internal/infrastructure/database"
+
+	"github.com/orsinium-labs/enum"
+)
+
+type Action enum.Member[string]
+
+var (
+	ActionCreate = Action{"create"}
+	ActionUpdate = Action{"update"}
+	ActionDelete = Action{"delete"}
+
+	Actions = database.RegisterEnum(enum.New(ActionCreate, ActionUpdate, ActionDelete),
+		database.WithPostgresType("audit_action"))
+)
+
+// AuditLogModel is one recorded write on a core.BaseModel row. Before and After only hold
+// the columns that changed.
+type AuditLogModel struct {
+	ID        uint64    `gorm:"primarykey"`
+	TenantID  string    `gorm:"Column:tenant_id;size:64;not null;default:'default';index"`
+	Entity    string    `gorm:"Column:entity;index:idx_audit_logs_entity"`
+	EntityID  string    `gorm:"Column:entity_id;index:idx_audit_logs_entity"`
+	Action    Action    `gorm:"Column:action;serializer:enum"`
+	Actor     string    `gorm:"Column:actor;index"`
+	Before    core.JSON `gorm:"Column:before"`
+	After     core.JSON `gorm:"Column:after"`
+	CreatedAt time.Time `gorm:"Column:created_at;index"`
+}
+
+func (AuditLogModel) TableName() string {
+	return "audit_logs"
+}
@@ -0,0 +1,16 @@
+package audit
+
+import (
+	"agentic/commerce/internal/infrastructure/database"
+
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module(
+	"audit",
+	fx.Provide(NewAuditRepository),
+	fx.Provide(NewAuditService),
+	fx.Invoke(RegisterCallbacks),
+	fx.Invoke(RegisterRoutes),
+	database.AsModel(&AuditLogModel{}),
+)
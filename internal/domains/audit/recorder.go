@@ -0,0 +1,315 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"agentic/commerce/internal/core"
+	"agentic/commerce/pkg/logger"
+
+	"github.com/go-json-experiment/json/v1"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	beforeSnapshotsKey = "audit:before_snapshots"
+	redactedValue      = "[redacted]"
+)
+
+var baseModelType = reflect.TypeOf(core.BaseModel{})
+
+type skipContextKey struct{}
+
+// WithoutRecording turns the recorder off for the statements made with ctx, for bulk
+// loads like the seed command whose rows have no history worth keeping.
+func WithoutRecording(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipContextKey{}, true)
+}
+
+type recorder struct {
+	logger *logger.AppLogger
+}
+
+// RegisterCallbacks hooks the audit recorder into every create, update and delete made
+// on models embedding core.BaseModel. Statements without a primary key value (batch
+// updates by condition) are not recorded.
+func RegisterCallbacks(db *gorm.DB, logger *logger.AppLogger) error {
+	r := &recorder{logger: logger.WithScope("audit")}
+
+	if err := db.Callback().Create().After("gorm:create").Register("audit:after_create", r.afterCreate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("audit:before_update", r.captureBefore); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("audit:after_update", r.afterUpdate); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("audit:before_delete", r.captureBefore); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("audit:after_delete", r.afterDelete)
+}
+
+func (r *recorder) afterCreate(tx *gorm.DB) {
+	if !isAudited(tx) {
+		return
+	}
+	ids := primaryKeys(tx)
+	afters, err := snapshots(tx, ids)
+	if err != nil {
+		r.logger.Error("cannot snapshot created {} rows {}", err, tx.Statement.Table, ids)
+		return
+	}
+	for _, id := range ids {
+		after, ok := afters[fmt.Sprint(id)]
+		if !ok {
+			r.logger.Error("cannot snapshot created {} row {}", gorm.ErrRecordNotFound, tx.Statement.Table, id)
+			continue
+		}
+		r.record(tx, ActionCreate, id, nil, after)
+	}
+}
+
+func (r *recorder) captureBefore(tx *gorm.DB) {
+	if !isAudited(tx) {
+		return
+	}
+	ids := primaryKeys(tx)
+	befores, err := snapshots(tx, ids)
+	if err != nil {
+		r.logger.Error("cannot snapshot {} rows {}", err, tx.Statement.Table, ids)
+		return
+	}
+	tx.Statement.Settings.Store(beforeSnapshotsKey, befores)
+}
+
+func (r *recorder) afterUpdate(tx *gorm.DB) {
+	if tx.Error != nil || !isAudited(tx) {
+		return
+	}
+	befores := beforeSnapshots(tx)
+	ids := primaryKeys(tx)
+	afters, err := snapshots(tx, ids)
+	if err != nil {
+		r.logger.Error("cannot snapshot updated {} rows {}", err, tx.Statement.Table, ids)
+		return
+	}
+	for _, id := range ids {
+		after, ok := afters[fmt.Sprint(id)]
+		if !ok {
+			r.logger.Error("cannot snapshot updated {} row {}", gorm.ErrRecordNotFound, tx.Statement.Table, id)
+			continue
+		}
+		before, changed := diff(befores[fmt.Sprint(id)], after)
+		if len(changed) == 0 {
+			continue
+		}
+		r.record(tx, ActionUpdate, id, before, changed)
+	}
+}
+
+func (r *recorder) afterDelete(tx *gorm.DB) {
+	if tx.Error != nil || tx.Statement.RowsAffected == 0 || !isAudited(tx) {
+		return
+	}
+	befores := beforeSnapshots(tx)
+	for _, id := range primaryKeys(tx) {
+		before, ok := befores[fmt.Sprint(id)]
+		if !ok {
+			continue
+		}
+		r.record(tx, ActionDelete, id, before, nil)
+	}
+}
+
+func (r *recorder) record(tx *gorm.DB, action Action, id interface{}, before, after core.JSON) {
+	if tx.Error != nil {
+		return
+	}
+	entry := &AuditLogModel{
+		Entity:   tx.Statement.Table,
+		EntityID: fmt.Sprint(id),
+		Action:   action,
+		Actor:    core.ActorFromContext(tx.Statement.Context),
+		Before:   before,
+		After:    after,
+	}
+	err := tx.Session(&gorm.Session{NewDB: true}).Create(entry).Error
+	if err != nil {
+		_ = tx.AddError(fmt.Errorf("cannot write audit log: %w", err))
+	}
+}
+
+func isAudited(tx *gorm.DB) bool {
+	if tx.Error != nil || tx.Statement.Schema == nil {
+		return false
+	}
+	if skip, _ := tx.Statement.Context.Value(skipContextKey{}).(bool); skip {
+		return false
+	}
+	field, ok := tx.Statement.Schema.ModelType.FieldByName("BaseModel")
+	return ok && field.Anonymous && field.Type == baseModelType
+}
+
+func primaryKeys(tx *gorm.DB) []interface{} {
+	pk := tx.Statement.Schema.PrioritizedPrimaryField
+	if pk == nil {
+		return nil
+	}
+
+	var ids []interface{}
+	collect := func(rv reflect.Value) {
+		if id, zero := pk.ValueOf(tx.Statement.Context, rv); !zero {
+			ids = append(ids, id)
+		}
+	}
+
+	rv := reflect.Indirect(tx.Statement.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			collect(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		collect(rv)
+	}
+	return ids
+}
+
+// snapshots loads the rows of ids in one query, keyed by fmt.Sprint of their primary key.
+func snapshots(tx *gorm.DB, ids []interface{}) (map[string]core.JSON, error) {
+	out := make(map[string]core.JSON, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+
+	pk := tx.Statement.Schema.PrioritizedPrimaryField.DBName
+	rows, err := tx.Session(&gorm.Session{NewDB: true}).
+		Table(tx.Statement.Table).
+		Where(map[string]interface{}{pk: ids}).
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		var id string
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			// SQLite hands JSON columns back as text
+			if s, ok := values[i].(string); ok && isJSONColumn(columnTypes[i]) {
+				values[i] = []byte(s)
+			}
+			if column == pk {
+				id = primaryKeyString(values[i])
+			}
+			row[column] = values[i]
+		}
+		snapshot, err := normalize(row)
+		if err != nil {
+			return nil, err
+		}
+		redact(tx.Statement.Schema, snapshot)
+		out[id] = snapshot
+	}
+	return out, rows.Err()
+}
+
+// primaryKeyString spells a scanned primary key like fmt.Sprint spells the field value;
+// MySQL hands integers back as text.
+func primaryKeyString(value interface{}) string {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprint(value)
+}
+
+// redact masks the columns of the fields tagged audit:"redact", such as the hash of an
+// API key, so they never reach the log.
+func redact(s *schema.Schema, row core.JSON) {
+	for _, field := range s.Fields {
+		if _, ok := row[field.DBName]; ok && field.Tag.Get("audit") == "redact" {
+			row[field.DBName] = redactedValue
+		}
+	}
+}
+
+func isJSONColumn(columnType *sql.ColumnType) bool {
+	switch strings.ToLower(columnType.DatabaseTypeName()) {
+	case "json", "jsonb":
+		return true
+	}
+	return false
+}
+
+// normalize round-trips the row through JSON so snapshots compare and store uniformly.
+func normalize(row map[string]interface{}) (core.JSON, error) {
+	for k, v := range row {
+		if b, ok := v.([]byte); ok {
+			if json.Valid(b) {
+				row[k] = json.RawMessage(b)
+			} else {
+				row[k] = string(b)
+			}
+		}
+	}
+	raw, err := json.Marshal(row)
+	if err != nil {
+		return nil, err
+	}
+	var out core.JSON
+	err = json.Unmarshal(raw, &out)
+	return out, err
+}
+
+// diff returns the before and after values of the columns that differ.
+func diff(before, after core.JSON) (core.JSON, core.JSON) {
+	changedBefore, changedAfter := core.JSON{}, core.JSON{}
+	for k, v := range after {
+		if !sameValue(before[k], v) {
+			changedBefore[k] = before[k]
+			changedAfter[k] = v
+		}
+	}
+	return changedBefore, changedAfter
+}
+
+func sameValue(a, b interface{}) bool {
+	ra, errA := json.Marshal(a)
+	rb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ra, rb)
+}
+
+func beforeSnapshots(tx *gorm.DB) map[string]core.JSON {
+	if v, ok := tx.Statement.Settings.Load(beforeSnapshotsKey); ok {
+		if snapshots, ok := v.(map[string]core.JSON); ok {
+			return snapshots
+		}
+	}
+	return nil
+}
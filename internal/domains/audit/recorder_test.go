@@ -0,0 +1,209 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/core"
+	"agentic/commerce/internal/infrastructure/migration"
+	"agentic/commerce/migrations"
+	"agentic/commerce/pkg/logger"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+var errInvalidName = errors.New("invalid name")
+
+type widget struct {
+	core.BaseModel
+	Name   string `gorm:"Column:name"`
+	Secret string `gorm:"Column:secret" audit:"redact"`
+}
+
+func (w *widget) BeforeSave(*gorm.DB) error {
+	if w.Name == "invalid" {
+		return errInvalidName
+	}
+	return nil
+}
+
+// newRecordedDB migrates audit_logs, creates a widgets table and records the writes
+// on it; the counter is bumped by every read of widgets the recorder makes.
+func newRecordedDB(t *testing.T) (*gorm.DB, *int) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	all, err := migrations.All("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migration.NewMigrator(db, all).Up(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&widget{}); err != nil {
+		t.Fatal(err)
+	}
+
+	appLogger := logger.NewAppLogger(&config.Config{Mode: config.ModeDev, Logger: &config.Logger{Level: config.LevelWarn}})
+	if err := RegisterCallbacks(db, appLogger); err != nil {
+		t.Fatal(err)
+	}
+	var snapshots int
+	err = db.Callback().Row().Before("gorm:row").Register("test:count_snapshots", func(tx *gorm.DB) {
+		if tx.Statement.Table == "widgets" {
+			snapshots++
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, &snapshots
+}
+
+func entries(t *testing.T, db *gorm.DB) []AuditLogModel {
+	t.Helper()
+	var logs []AuditLogModel
+	if err := db.Where("entity = ?", "widgets").Order("id").Find(&logs).Error; err != nil {
+		t.Fatal(err)
+	}
+	return logs
+}
+
+func TestRecorderDiffsBeforeAndAfter(t *testing.T) {
+	db, _ := newRecordedDB(t)
+	alice := core.WithActor(context.Background(), "alice")
+	bob := core.WithActor(context.Background(), "bob")
+
+	w := &widget{Name: "first", Secret: "s3cret"}
+	if err := db.WithContext(alice).Create(w).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.WithContext(bob).Model(w).Update("name", "second").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(w).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	logs := entries(t, db)
+	if len(logs) != 3 {
+		t.Fatalf("%d entries, want 3", len(logs))
+	}
+
+	created := logs[0]
+	if created.Action != ActionCreate || created.Actor != "alice" || created.Before != nil {
+		t.Fatalf("create entry %+v", created)
+	}
+	if created.After["name"] != "first" || created.After["secret"] != redactedValue {
+		t.Fatalf("create recorded %v", created.After)
+	}
+
+	updated := logs[1]
+	if updated.Action != ActionUpdate || updated.Actor != "bob" {
+		t.Fatalf("update entry %+v", updated)
+	}
+	if updated.Before["name"] != "first" || updated.After["name"] != "second" || updated.After["updated_by"] != "bob" {
+		t.Fatalf("update recorded %v -> %v", updated.Before, updated.After)
+	}
+	for _, unchanged := range []string{"secret", "created_at", "created_by"} {
+		if _, ok := updated.After[unchanged]; ok {
+			t.Fatalf("the unchanged %s was recorded: %v", unchanged, updated.After)
+		}
+	}
+
+	deleted := logs[2]
+	if deleted.Action != ActionDelete || deleted.Actor != core.SystemActor || deleted.After != nil {
+		t.Fatalf("delete entry %+v", deleted)
+	}
+	if deleted.Before["name"] != "second" || deleted.Before["secret"] != redactedValue {
+		t.Fatalf("delete recorded %v", deleted.Before)
+	}
+}
+
+func TestRecorderSnapshotsAStatementInOneQuery(t *testing.T) {
+	db, snapshots := newRecordedDB(t)
+	widgets := []*widget{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+
+	if err := db.Create(&widgets).Error; err != nil || *snapshots != 1 {
+		t.Fatalf("the create took %d snapshot queries: %v", *snapshots, err)
+	}
+	*snapshots = 0
+	if err := db.Model(&widgets).Update("name", "renamed").Error; err != nil || *snapshots != 2 {
+		t.Fatalf("the update took %d snapshot queries: %v", *snapshots, err)
+	}
+	*snapshots = 0
+	if err := db.Delete(&widgets).Error; err != nil || *snapshots != 1 {
+		t.Fatalf("the delete took %d snapshot queries: %v", *snapshots, err)
+	}
+
+	logs := entries(t, db)
+	if len(logs) != 9 {
+		t.Fatalf("%d entries, want 9", len(logs))
+	}
+	for _, entry := range logs[3:6] {
+		if entry.Action != ActionUpdate || entry.Before["name"] == "renamed" || entry.After["name"] != "renamed" {
+			t.Fatalf("update entry %+v", entry)
+		}
+	}
+}
+
+func TestRecorderSkipsAStatementAHookRejects(t *testing.T) {
+	db, _ := newRecordedDB(t)
+	w := &widget{Name: "valid"}
+	if err := db.Create(w).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	w.Name = "invalid"
+	if err := db.Save(w).Error; !errors.Is(err, errInvalidName) {
+		t.Fatalf("expected the hook error, got %v", err)
+	}
+	if err := db.Create(&widget{Name: "invalid"}).Error; !errors.Is(err, errInvalidName) {
+		t.Fatalf("expected the hook error, got %v", err)
+	}
+	if logs := entries(t, db); len(logs) != 1 || logs[0].Action != ActionCreate {
+		t.Fatalf("entries %+v, only the first create happened", logs)
+	}
+}
+
+func TestRecorderFailsTheStatementItCannotRecord(t *testing.T) {
+	db, _ := newRecordedDB(t)
+	if err := db.Migrator().DropTable(&AuditLogModel{}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(&widget{Name: "unrecorded"}).Error
+	})
+	if err == nil || !strings.Contains(err.Error(), "cannot write audit log") {
+		t.Fatalf("expected the audit write to fail, got %v", err)
+	}
+	var count int64
+	if err := db.Model(&widget{}).Count(&count).Error; err != nil || count != 0 {
+		t.Fatalf("%d widgets kept without their audit entry: %v", count, err)
+	}
+}
+
+func TestRecorderSkipsStatementsWithoutRecording(t *testing.T) {
+	db, snapshots := newRecordedDB(t)
+	if err := db.WithContext(WithoutRecording(context.Background())).Create(&widget{Name: "bulk"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if logs := entries(t, db); len(logs) != 0 || *snapshots != 0 {
+		t.Fatalf("recorded %d entries with %d snapshots", len(logs), *snapshots)
+	}
+}
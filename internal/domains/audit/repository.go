@@ -0,0 +1,20 @@
+package audit
+
+import (
+	"agentic/commerce/internal/core"
+	"agentic/commerce/internal/infrastructure/database"
+)
+
+type IAuditRepository interface {
+	core.IBaseRepository[AuditLogModel]
+}
+
+type auditRepository struct {
+	core.IBaseRepository[AuditLogModel]
+}
+
+func NewAuditRepository(database database.GormDB) IAuditRepository {
+	return &auditRepository{
+		IBaseRepository: core.NewBaseRepository[AuditLogModel](database),
+	}
+}
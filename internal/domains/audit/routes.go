@@ -0,0 +1,22 @@
+package audit
+
+import (
+	"agentic/commerce/internal/infrastructure/authz"
+	"agentic/commerce/internal/interfaces/http"
+	"agentic/commerce/internal/interfaces/http/middleware"
+	"agentic/commerce/pkg/logger"
+	"agentic/commerce/pkg/specs/api"
+)
+
+func RegisterRoutes(s *http.Server, auditService IAuditService, policy *authz.Policy, logger *logger.AppLogger) *http.Server {
+	auditResourceObj := NewAuditResource(auditService, logger)
+
+	apis := s.Router.Group("/audit")
+
+	http.AddRoute[api.AuditLogListRequest, api.APIResponse[api.ApiPaginateResponse[api.AuditLogItemResponse]]](s,
+		apis.GET("", auditResourceObj.ListAuditLogs(), middleware.RequirePermission(policy, "audit:read:any")),
+		middleware.SecurityUser,
+	)
+
+	return s
+}
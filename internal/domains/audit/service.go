@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"context"
+
+	"agentic/commerce/internal/core"
+	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/pkg/apperror"
+	"agentic/commerce/pkg/logger"
+	"agentic/commerce/pkg/specs/api"
+)
+
+type IAuditService interface {
+	ListAuditLogs(ctx context.Context, req *api.AuditLogListRequest) (*api.ApiPaginateResponse[api.AuditLogItemResponse], error)
+}
+
+type auditService struct {
+	repository IAuditRepository
+	logger     *logger.AppLogger
+}
+
+func NewAuditService(logger *logger.AppLogger, repository IAuditRepository) IAuditService {
+	return &auditService{
+		repository: repository,
+		logger:     logger.WithScope(&auditService{}),
+	}
+}
+
+func (s *auditService) ListAuditLogs(ctx context.Context, req *api.AuditLogListRequest) (*api.ApiPaginateResponse[api.AuditLogItemResponse], error) {
+	specs := []core.Specification{core.OrderByDesc("id")}
+	if req.Entity != "" {
+		specs = append(specs, core.Eq("entity", req.Entity))
+	}
+	if req.EntityID != "" {
+		specs = append(specs, core.Eq("entity_id", req.EntityID))
+	}
+	if req.Actor != "" {
+		specs = append(specs, core.Eq("actor", req.Actor))
+	}
+	if req.Action != "" {
+		action := Actions.Parse(req.Action)
+		if action == nil {
+			return nil, apperror.ErrBadRequest
+		}
+		specs = append(specs, core.Eq("action", action.Value))
+	}
+
+	page, err := s.repository.Paginate(ctx, core.PageRequest{Page: req.Page, Size: req.Size}, specs...)
+	if err != nil {
+		s.logger.Error("cannot list audit logs", err)
+		return nil, database.ResolveError(err)
+	}
+
+	return mapToAuditLogPage(page), nil
+}
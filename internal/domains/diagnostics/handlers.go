@@ -0,0 +1,65 @@
+package diagnostics
+
+import (
+	"agentic/commerce/internal/utils"
+	"agentic/commerce/pkg/apperror"
+	"agentic/commerce/pkg/logger"
+	"agentic/commerce/pkg/specs/api"
+
+	"github.com/labstack/echo/v4"
+)
+
+type IDiagnosticsResource interface {
+	GetDatabaseDiagnostics() echo.HandlerFunc
+	GetSlowQueries() echo.HandlerFunc
+}
+
+type diagnosticsResource struct {
+	DiagnosticsService IDiagnosticsService
+	Logger             *logger.AppLogger
+}
+
+func NewDiagnosticsResource(service IDiagnosticsService, logger *logger.AppLogger) IDiagnosticsResource {
+	return &diagnosticsResource{
+		DiagnosticsService: service,
+		Logger:             logger.WithScope(diagnosticsResource{}),
+	}
+}
+
+func (v *diagnosticsResource) GetDatabaseDiagnostics() echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		var req api.DatabaseDiagnosticsRequest
+		reqCtx := ctx.Request().Context()
+
+		err := ctx.Bind(&req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, apperror.ErrBadRequest, "Check your input: "+err.Error())
+		}
+
+		resp, err := v.DiagnosticsService.GetDatabaseDiagnostics(reqCtx, &req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, err, "Cant read the database diagnostics")
+		}
+
+		return utils.SuccessResponse(ctx, resp)
+	}
+}
+
+func (v *diagnosticsResource) GetSlowQueries() echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		var req api.SlowQueriesRequest
+		reqCtx := ctx.Request().Context()
+
+		err := ctx.Bind(&req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, apperror.ErrBadRequest, "Check your input: "+err.Error())
+		}
+
+		resp, err := v.DiagnosticsService.GetSlowQueries(reqCtx, &req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, err, "Cant read the slow queries")
+		}
+
+		return utils.SuccessResponse(ctx, resp)
+	}
+}
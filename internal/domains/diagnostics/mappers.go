@@ -0,0 +1,72 @@
+package diagnostics
+
+import (
+	"time"
+
+	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/pkg/specs/api"
+
+	"github.com/samber/lo"
+)
+
+func mapPoolStats(pools []PoolStats) []api.PoolStatsResponse {
+	out := make([]api.PoolStatsResponse, 0, len(pools))
+	for _, pool := range pools {
+		out = append(out, api.PoolStatsResponse{
+			Name:              pool.Name,
+			MaxOpen:           pool.MaxOpenConnections,
+			Open:              pool.OpenConnections,
+			InUse:             pool.InUse,
+			Idle:              pool.Idle,
+			WaitCount:         pool.WaitCount,
+			WaitDurationMs:    pool.WaitDuration.Milliseconds(),
+			MaxIdleClosed:     pool.MaxIdleClosed,
+			MaxIdleTimeClosed: pool.MaxIdleTimeClosed,
+			MaxLifetimeClosed: pool.MaxLifetimeClosed,
+		})
+	}
+	return out
+}
+
+func mapSessions(sessions []Session) []api.DatabaseSessionResponse {
+	out := make([]api.DatabaseSessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		out = append(out, api.DatabaseSessionResponse{
+			PID:           session.PID,
+			User:          session.User,
+			ClientAddr:    lo.FromPtr(session.ClientAddr),
+			State:         lo.FromPtr(session.State),
+			WaitEventType: lo.FromPtr(session.WaitEventType),
+			WaitEvent:     lo.FromPtr(session.WaitEvent),
+			BackendStart:  session.BackendStart,
+			XactStart:     session.XactStart,
+			QueryStart:    session.QueryStart,
+			DurationMs:    int64(session.DurationMs),
+			Query:         session.Query,
+		})
+	}
+	return out
+}
+
+func mapSlowQueries(stats []database.QueryStat) []api.SlowQueryResponse {
+	out := make([]api.SlowQueryResponse, 0, len(stats))
+	for _, stat := range stats {
+		out = append(out, api.SlowQueryResponse{
+			Fingerprint: stat.Fingerprint,
+			Count:       stat.Count,
+			SlowCount:   stat.SlowCount,
+			Rows:        stat.Rows,
+			TotalMs:     milliseconds(stat.Total),
+			MeanMs:      milliseconds(stat.Total / time.Duration(stat.Count)),
+			P50Ms:       milliseconds(stat.P50),
+			P95Ms:       milliseconds(stat.P95),
+			MaxMs:       milliseconds(stat.Max),
+			LastSeen:    stat.LastSeen,
+		})
+	}
+	return out
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,27 @@
+package diagnostics
+
+import (
+	"database/sql"
+	"time"
+)
+
+// PoolStats is sql.DBStats of one connection pool.
+type PoolStats struct {
+	Name string
+	sql.DBStats
+}
+
+// Session is a row of pg_stat_activity, or of the MySQL process list mapped onto it.
+type Session struct {
+	PID           int64      `gorm:"Column:pid"`
+	User          string     `gorm:"Column:usename"`
+	ClientAddr    *string    `gorm:"Column:client_addr"`
+	State         *string    `gorm:"Column:state"`
+	WaitEventType *string    `gorm:"Column:wait_event_type"`
+	WaitEvent     *string    `gorm:"Column:wait_event"`
+	BackendStart  *time.Time `gorm:"Column:backend_start"`
+	XactStart     *time.Time `gorm:"Column:xact_start"`
+	QueryStart    *time.Time `gorm:"Column:query_start"`
+	DurationMs    float64    `gorm:"Column:duration_ms"`
+	Query         string     `gorm:"Column:query"`
+}
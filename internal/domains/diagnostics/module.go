@@ -0,0 +1,12 @@
+package diagnostics
+
+import (
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module(
+	"diagnostics",
+	fx.Provide(NewDiagnosticsRepository),
+	fx.Provide(NewDiagnosticsService),
+	fx.Invoke(RegisterRoutes),
+)
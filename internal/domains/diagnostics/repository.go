@@ -0,0 +1,127 @@
+package diagnostics
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/infrastructure/database"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+const sessionsQuery = `
+SELECT pid, usename, client_addr::text AS client_addr, state, wait_event_type, wait_event,
+       backend_start, xact_start, query_start,
+       COALESCE(EXTRACT(EPOCH FROM (now() - query_start)) * 1000, 0) AS duration_ms,
+       query
+FROM pg_stat_activity
+WHERE datname = current_database()
+  AND pid <> pg_backend_pid()
+  AND (? = '' OR application_name = ?)`
+
+// mysqlSessionsQuery maps information_schema.PROCESSLIST onto the columns of
+// pg_stat_activity; MySQL has no application name, sessions are filtered by database.
+const mysqlSessionsQuery = `
+SELECT id AS pid, user AS usename, host AS client_addr, command AS state, state AS wait_event,
+       NOW() - INTERVAL time SECOND AS query_start, time * 1000 AS duration_ms,
+       COALESCE(info, '') AS query
+FROM information_schema.PROCESSLIST
+WHERE db = DATABASE()
+  AND id <> CONNECTION_ID()`
+
+type IDiagnosticsRepository interface {
+	PoolStats() ([]PoolStats, error)
+	// Sessions lists the sessions of this application that aren't idle.
+	Sessions(ctx context.Context) ([]Session, error)
+	// LongRunning lists the active queries that have been running for at least threshold.
+	LongRunning(ctx context.Context, threshold time.Duration) ([]Session, error)
+	// SlowQueries reads the in-process statistics the gorm logger collects.
+	SlowQueries(limit int, sortBy string, includeFast bool) []database.QueryStat
+	QueryStatsSettings() (slowThreshold, window time.Duration)
+}
+
+type diagnosticsRepository struct {
+	db              *gorm.DB
+	replicas        *database.ReplicaSet
+	queryStats      *database.QueryStats
+	applicationName string
+	driver          config.DbDriver
+}
+
+func NewDiagnosticsRepository(db *gorm.DB, replicas *database.ReplicaSet, queryStats *database.QueryStats, cfg *config.DbConfig) IDiagnosticsRepository {
+	return &diagnosticsRepository{
+		db:              db,
+		replicas:        replicas,
+		queryStats:      queryStats,
+		applicationName: cfg.ApplicationName,
+		driver:          cfg.Dialect(),
+	}
+}
+
+func (r *diagnosticsRepository) PoolStats() ([]PoolStats, error) {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return nil, err
+	}
+	stats := []PoolStats{{Name: "primary", DBStats: sqlDB.Stats()}}
+
+	replicas := make([]PoolStats, 0)
+	for name, pool := range r.replicas.Pools() {
+		replicas = append(replicas, PoolStats{Name: name, DBStats: pool.Stats()})
+	}
+	sort.Slice(replicas, func(i, j int) bool {
+		return replicas[i].Name < replicas[j].Name
+	})
+	return append(stats, replicas...), nil
+}
+
+// Sessions and LongRunning read pg_stat_activity on Postgres and the process list on
+// MySQL; SQLite runs in-process and has no sessions to report.
+func (r *diagnosticsRepository) Sessions(ctx context.Context) ([]Session, error) {
+	var sessions []Session
+	var err error
+	switch r.driver {
+	case config.DbDriverPostgres:
+		err = r.primary(ctx).
+			Raw(sessionsQuery+" AND state <> 'idle' ORDER BY query_start", r.applicationName, r.applicationName).
+			Scan(&sessions).Error
+	case config.DbDriverMySQL:
+		err = r.primary(ctx).
+			Raw(mysqlSessionsQuery + " AND command <> 'Sleep' ORDER BY time DESC").
+			Scan(&sessions).Error
+	}
+	return sessions, err
+}
+
+func (r *diagnosticsRepository) LongRunning(ctx context.Context, threshold time.Duration) ([]Session, error) {
+	var sessions []Session
+	var err error
+	switch r.driver {
+	case config.DbDriverPostgres:
+		err = r.primary(ctx).
+			Raw(sessionsQuery+" AND state = 'active' AND now() - query_start >= ? * interval '1 millisecond' ORDER BY query_start",
+				r.applicationName, r.applicationName, threshold.Milliseconds()).
+			Scan(&sessions).Error
+	case config.DbDriverMySQL:
+		err = r.primary(ctx).
+			Raw(mysqlSessionsQuery+" AND command = 'Query' AND time * 1000 >= ? ORDER BY time DESC", threshold.Milliseconds()).
+			Scan(&sessions).Error
+	}
+	return sessions, err
+}
+
+func (r *diagnosticsRepository) SlowQueries(limit int, sortBy string, includeFast bool) []database.QueryStat {
+	return r.queryStats.Top(limit, sortBy, includeFast)
+}
+
+func (r *diagnosticsRepository) QueryStatsSettings() (time.Duration, time.Duration) {
+	return r.queryStats.SlowThreshold(), r.queryStats.Window()
+}
+
+// primary pins the query to the primary, replicas have their own session lists.
+func (r *diagnosticsRepository) primary(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Clauses(dbresolver.Write)
+}
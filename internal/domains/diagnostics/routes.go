@@ -0,0 +1,27 @@
+package diagnostics
+
+import (
+	"agentic/commerce/internal/infrastructure/authz"
+	"agentic/commerce/internal/interfaces/http"
+	"agentic/commerce/internal/interfaces/http/middleware"
+	"agentic/commerce/pkg/logger"
+	"agentic/commerce/pkg/specs/api"
+)
+
+func RegisterRoutes(s *http.Server, diagnosticsService IDiagnosticsService, policy *authz.Policy, logger *logger.AppLogger) *http.Server {
+	diagnosticsResourceObj := NewDiagnosticsResource(diagnosticsService, logger)
+
+	apis := s.Router.Group("/admin/database")
+
+	http.AddRoute[api.DatabaseDiagnosticsRequest, api.APIResponse[api.DatabaseDiagnosticsResponse]](s,
+		apis.GET("", diagnosticsResourceObj.GetDatabaseDiagnostics(), middleware.RequirePermission(policy, "diagnostics:read:any")),
+		middleware.SecurityAdmin,
+	)
+
+	http.AddRoute[api.SlowQueriesRequest, api.APIResponse[api.SlowQueriesResponse]](s,
+		apis.GET("/slow-queries", diagnosticsResourceObj.GetSlowQueries(), middleware.RequirePermission(policy, "diagnostics:read:any")),
+		middleware.SecurityAdmin,
+	)
+
+	return s
+}
@@ -0,0 +1,95 @@
+package diagnostics
+
+import (
+	"context"
+	"time"
+
+	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/pkg/apperror"
+	"agentic/commerce/pkg/logger"
+	"agentic/commerce/pkg/specs/api"
+)
+
+const (
+	DefaultLongRunningThreshold = 5 * time.Second
+	DefaultSlowQueriesLimit     = 10
+	MaxSlowQueriesLimit         = 100
+)
+
+type IDiagnosticsService interface {
+	GetDatabaseDiagnostics(ctx context.Context, req *api.DatabaseDiagnosticsRequest) (*api.DatabaseDiagnosticsResponse, error)
+	GetSlowQueries(ctx context.Context, req *api.SlowQueriesRequest) (*api.SlowQueriesResponse, error)
+}
+
+type diagnosticsService struct {
+	repository IDiagnosticsRepository
+	logger     *logger.AppLogger
+}
+
+func NewDiagnosticsService(logger *logger.AppLogger, repository IDiagnosticsRepository) IDiagnosticsService {
+	return &diagnosticsService{
+		repository: repository,
+		logger:     logger.WithScope(&diagnosticsService{}),
+	}
+}
+
+func (s *diagnosticsService) GetDatabaseDiagnostics(ctx context.Context, req *api.DatabaseDiagnosticsRequest) (*api.DatabaseDiagnosticsResponse, error) {
+	threshold := DefaultLongRunningThreshold
+	if req.LongRunningThreshold != "" {
+		parsed, err := time.ParseDuration(req.LongRunningThreshold)
+		if err != nil || parsed < 0 {
+			return nil, apperror.ErrBadRequest
+		}
+		threshold = parsed
+	}
+
+	pools, err := s.repository.PoolStats()
+	if err != nil {
+		s.logger.Error("cannot read pool stats", err)
+		return nil, apperror.ErrServer
+	}
+
+	sessions, err := s.repository.Sessions(ctx)
+	if err != nil {
+		s.logger.Error("cannot read pg_stat_activity", err)
+		return nil, database.ResolveError(err)
+	}
+
+	longRunning, err := s.repository.LongRunning(ctx, threshold)
+	if err != nil {
+		s.logger.Error("cannot read long running queries", err)
+		return nil, database.ResolveError(err)
+	}
+
+	return &api.DatabaseDiagnosticsResponse{
+		Pools:       mapPoolStats(pools),
+		Sessions:    mapSessions(sessions),
+		LongRunning: mapSessions(longRunning),
+	}, nil
+}
+
+func (s *diagnosticsService) GetSlowQueries(_ context.Context, req *api.SlowQueriesRequest) (*api.SlowQueriesResponse, error) {
+	limit := req.Limit
+	switch {
+	case limit < 0 || limit > MaxSlowQueriesLimit:
+		return nil, apperror.ErrBadRequest
+	case limit == 0:
+		limit = DefaultSlowQueriesLimit
+	}
+
+	sortBy := req.Sort
+	switch sortBy {
+	case "":
+		sortBy = database.QueryStatsSortTotal
+	case database.QueryStatsSortTotal, database.QueryStatsSortP95, database.QueryStatsSortMax, database.QueryStatsSortCount:
+	default:
+		return nil, apperror.ErrBadRequest
+	}
+
+	threshold, window := s.repository.QueryStatsSettings()
+	return &api.SlowQueriesResponse{
+		SlowThresholdMs: milliseconds(threshold),
+		WindowSeconds:   int64(window.Seconds()),
+		Queries:         mapSlowQueries(s.repository.SlowQueries(limit, sortBy, req.IncludeFast)),
+	}, nil
+}
@@ -0,0 +1,156 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/infrastructure/cache"
+	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/internal/infrastructure/tenancy"
+	"agentic/commerce/pkg/logger"
+)
+
+// cachedContentRepository serves the per-user reads from the cache and drops the
+// affected keys on every write, once its transaction commits.
+type cachedContentRepository struct {
+	IContentRepository
+	cache  cache.Cache
+	ttl    time.Duration
+	logger *logger.AppLogger
+}
+
+func NewCachedContentRepository(
+	repository IContentRepository,
+	c cache.Cache,
+	cfg *config.CacheConfig,
+	logger *logger.AppLogger,
+) IContentRepository {
+	if c == nil {
+		return repository
+	}
+	return &cachedContentRepository{
+		IContentRepository: repository,
+		cache:              c,
+		ttl:                cfg.TTL,
+		logger:             logger.WithScope(cachedContentRepository{}),
+	}
+}
+
+func (r *cachedContentRepository) ListByUserID(ctx context.Context, userId int64) ([]MetaDataModel, error) {
+	key := listCacheKey(ctx, userId)
+	if cached, ok := r.get(ctx, key); ok {
+		return *cached, nil
+	}
+
+	result, err := r.IContentRepository.ListByUserID(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	r.set(ctx, key, result)
+	return result, nil
+}
+
+func (r *cachedContentRepository) GetByUserID(ctx context.Context, uuid string, userId int64) (*MetaDataModel, error) {
+	key := itemCacheKey(ctx, userId, uuid)
+	if cached, ok := r.get(ctx, key); ok && len(*cached) == 1 {
+		return &(*cached)[0], nil
+	}
+
+	result, err := r.IContentRepository.GetByUserID(ctx, uuid, userId)
+	if err != nil || result == nil {
+		return result, err
+	}
+	r.set(ctx, key, []MetaDataModel{*result})
+	return result, nil
+}
+
+func (r *cachedContentRepository) Save(ctx context.Context, model *MetaDataModel) error {
+	defer r.invalidateAfterCommit(ctx, model)
+	return r.IContentRepository.Save(ctx, model)
+}
+
+func (r *cachedContentRepository) Create(ctx context.Context, model *MetaDataModel) error {
+	defer r.invalidateAfterCommit(ctx, model)
+	return r.IContentRepository.Create(ctx, model)
+}
+
+func (r *cachedContentRepository) CreateInBatches(ctx context.Context, models []MetaDataModel, batchSize int) error {
+	defer func() {
+		for i := range models {
+			r.invalidateAfterCommit(ctx, &models[i])
+		}
+	}()
+	return r.IContentRepository.CreateInBatches(ctx, models, batchSize)
+}
+
+func (r *cachedContentRepository) Update(ctx context.Context, model *MetaDataModel) error {
+	defer r.invalidateAfterCommit(ctx, model)
+	return r.IContentRepository.Update(ctx, model)
+}
+
+func (r *cachedContentRepository) Upsert(ctx context.Context, model *MetaDataModel) error {
+	defer r.invalidateAfterCommit(ctx, model)
+	return r.IContentRepository.Upsert(ctx, model)
+}
+
+func (r *cachedContentRepository) Delete(ctx context.Context, model *MetaDataModel) error {
+	defer r.invalidateAfterCommit(ctx, model)
+	return r.IContentRepository.Delete(ctx, model)
+}
+
+func (r *cachedContentRepository) get(ctx context.Context, key string) (*[]MetaDataModel, bool) {
+	// reads inside a transaction must see the transaction, not the cache
+	if _, inTx := database.TxFromContext(ctx); inTx {
+		return nil, false
+	}
+	cached, ok, err := cache.GetJSON[[]MetaDataModel](ctx, r.cache, key)
+	if err != nil {
+		r.logger.Warn("cache read failed for {}: {}", key, err.Error())
+		return nil, false
+	}
+	return cached, ok
+}
+
+func (r *cachedContentRepository) set(ctx context.Context, key string, value []MetaDataModel) {
+	// nor may rows the transaction hasn't committed reach the cache
+	if _, inTx := database.TxFromContext(ctx); inTx {
+		return
+	}
+	if err := cache.SetJSON(ctx, r.cache, key, value, r.ttl); err != nil {
+		r.logger.Warn("cache write failed for {}: {}", key, err.Error())
+	}
+}
+
+// invalidateAfterCommit drops the keys once the write is visible: dropped before the
+// commit, a concurrent read would cache the old row again until the TTL.
+func (r *cachedContentRepository) invalidateAfterCommit(ctx context.Context, model *MetaDataModel) {
+	database.AfterCommit(ctx, func(ctx context.Context) {
+		r.invalidate(ctx, model)
+	})
+}
+
+func (r *cachedContentRepository) invalidate(ctx context.Context, model *MetaDataModel) {
+	if model == nil || model.UserId == nil {
+		return
+	}
+	keys := []string{listCacheKey(ctx, *model.UserId)}
+	if model.UUid != nil {
+		keys = append(keys, itemCacheKey(ctx, *model.UserId, model.UUid.String()))
+	}
+	if err := r.cache.Delete(ctx, keys...); err != nil {
+		r.logger.Warn("cache invalidation failed for {}: {}", keys, err.Error())
+	}
+}
+
+// keys carry the tenant so one tenant can never be served another tenant's rows
+func listCacheKey(ctx context.Context, userId int64) string {
+	tenantID, _ := tenancy.FromContext(ctx)
+	return fmt.Sprintf("metadata:tenant:%s:user:%d:list", tenantID, userId)
+}
+
+func itemCacheKey(ctx context.Context, userId int64, uuid string) string {
+	tenantID, _ := tenancy.FromContext(ctx)
+	return fmt.Sprintf("metadata:tenant:%s:user:%d:uuid:%s", tenantID, userId, uuid)
+}
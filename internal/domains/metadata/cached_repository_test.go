@@ -0,0 +1,113 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/infrastructure/cache"
+	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/internal/infrastructure/tenancy"
+	"agentic/commerce/pkg/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/samber/lo"
+)
+
+const cachePrefix = "test:"
+
+// newRedisCachedRepository puts the Redis cache, served by miniredis, in front of the
+// SQLite repository.
+func newRedisCachedRepository(t *testing.T) (IContentRepository, database.ITransactionManager, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	repository, db := newSQLiteRepository(t)
+	appLogger := logger.NewAppLogger(&config.Config{Mode: config.ModeDev, Logger: &config.Logger{Level: config.LevelWarn}})
+	cached := NewCachedContentRepository(repository, cache.NewRedisCache(client, cachePrefix), &config.CacheConfig{TTL: time.Hour}, appLogger)
+	return cached, database.NewTransactionManager(db), server
+}
+
+func listPosts(t *testing.T, repository IContentRepository, ctx context.Context) []MetaDataModel {
+	t.Helper()
+	posts, err := repository.ListByUserID(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return posts
+}
+
+func TestCachedContentRepositoryInvalidatesAfterCommit(t *testing.T) {
+	repository, txManager, server := newRedisCachedRepository(t)
+	ctx := tenancy.WithTenant(context.Background(), "a")
+	key := cachePrefix + listCacheKey(ctx, 1)
+
+	if posts := listPosts(t, repository, ctx); len(posts) != 0 {
+		t.Fatalf("listed %+v", posts)
+	}
+	if !server.Exists(key) {
+		t.Fatal("the list wasn't cached")
+	}
+
+	err := txManager.RunInTx(ctx, func(txCtx context.Context) error {
+		if err := repository.Create(txCtx, &MetaDataModel{UserId: lo.ToPtr(int64(1))}); err != nil {
+			return err
+		}
+		// reads inside the transaction see it and never reach the cache
+		if posts := listPosts(t, repository, txCtx); len(posts) != 1 {
+			t.Fatalf("the transaction listed %d posts", len(posts))
+		}
+		// a concurrent read still sees the committed rows and caches them again
+		if posts := listPosts(t, repository, ctx); len(posts) != 0 {
+			t.Fatalf("a concurrent read listed %d uncommitted posts", len(posts))
+		}
+		if !server.Exists(key) {
+			t.Fatal("the key was dropped before the commit")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if server.Exists(key) {
+		t.Fatal("the key outlived the commit")
+	}
+	if posts := listPosts(t, repository, ctx); len(posts) != 1 {
+		t.Fatalf("listed %d posts after the commit", len(posts))
+	}
+}
+
+func TestCachedContentRepositoryKeepsTheCacheOnRollback(t *testing.T) {
+	repository, txManager, server := newRedisCachedRepository(t)
+	ctx := tenancy.WithTenant(context.Background(), "a")
+	key := cachePrefix + listCacheKey(ctx, 1)
+	listPosts(t, repository, ctx)
+	cached, err := server.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rollback := errors.New("rollback")
+	err = txManager.RunInTx(ctx, func(txCtx context.Context) error {
+		if err := repository.Create(txCtx, &MetaDataModel{UserId: lo.ToPtr(int64(1))}); err != nil {
+			return err
+		}
+		return rollback
+	})
+	if !errors.Is(err, rollback) {
+		t.Fatal(err)
+	}
+
+	if current, err := server.Get(key); err != nil || current != cached {
+		t.Fatalf("the cache changed on rollback: %q, %v", current, err)
+	}
+	if posts := listPosts(t, repository, ctx); len(posts) != 0 {
+		t.Fatalf("listed %d rolled back posts", len(posts))
+	}
+}
@@ -0,0 +1,24 @@
+package metadata
+
+type MetadataCreated struct {
+	UUID   string
+	UserID int64
+	Model  MetaDataModel
+}
+
+func (MetadataCreated) EventName() string { return EventMetadataCreated }
+
+type MetadataUpdated struct {
+	UUID   string
+	UserID int64
+	Model  MetaDataModel
+}
+
+func (MetadataUpdated) EventName() string { return EventMetadataUpdated }
+
+type MetadataDeleted struct {
+	UUID   string
+	UserID int64
+}
+
+func (MetadataDeleted) EventName() string { return EventMetadataDeleted }
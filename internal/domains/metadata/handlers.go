@@ -13,6 +13,8 @@ type IContentResource interface {
 	CreateMetadata() echo.HandlerFunc
 	GetMetadata() echo.HandlerFunc
 	ListMetadata() echo.HandlerFunc
+	UpdateMetadata() echo.HandlerFunc
+	DeleteMetadata() echo.HandlerFunc
 }
 
 type contentResource struct {
@@ -58,11 +60,11 @@ func (v *contentResource) GetMetadata() echo.HandlerFunc {
 		if err != nil {
 			return utils.ErrorResponse(ctx, apperror.ErrBadRequest, "Check your input: "+err.Error())
 		}
-		v.Logger.Info("contentService.ListMetadata called")
+		v.Logger.Info("contentService.GetMetadata called")
 
 		resp, err := v.ContentService.GetMetaData(reqCtx, &req)
 		if err != nil {
-			return utils.ErrorResponse(ctx, err, "Cant create the metadata")
+			return utils.ErrorResponse(ctx, err, "Cant get the metadata")
 		}
 
 		return utils.SuccessResponse(ctx, resp)
@@ -71,11 +73,16 @@ func (v *contentResource) GetMetadata() echo.HandlerFunc {
 
 func (v *contentResource) ListMetadata() echo.HandlerFunc {
 	return func(ctx echo.Context) error {
+		var req api.MetadataListRequest
 		reqCtx := ctx.Request().Context()
 
+		err := ctx.Bind(&req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, apperror.ErrBadRequest, "Check your input: "+err.Error())
+		}
 		v.Logger.Info("contentService.ListMetadata called")
 
-		resp, err := v.ContentService.ListMetaData(reqCtx)
+		resp, err := v.ContentService.ListMetaData(reqCtx, &req)
 		if err != nil {
 			return utils.ErrorResponse(ctx, err, "Cant list the metadata")
 		}
@@ -83,3 +90,43 @@ func (v *contentResource) ListMetadata() echo.HandlerFunc {
 		return utils.SuccessResponse(ctx, resp)
 	}
 }
+
+func (v *contentResource) UpdateMetadata() echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		var req api.MetadataUpdateRequest
+		reqCtx := ctx.Request().Context()
+
+		err := ctx.Bind(&req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, apperror.ErrBadRequest, "Check your input: "+err.Error())
+		}
+		v.Logger.Info("contentService.UpdateMetadata called")
+
+		resp, err := v.ContentService.UpdateMetaData(reqCtx, &req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, err, "Cant update the metadata")
+		}
+
+		return utils.SuccessResponse(ctx, resp)
+	}
+}
+
+func (v *contentResource) DeleteMetadata() echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		var req api.MetadataIDAwareRequest
+		reqCtx := ctx.Request().Context()
+
+		err := ctx.Bind(&req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, apperror.ErrBadRequest, "Check your input: "+err.Error())
+		}
+		v.Logger.Info("contentService.DeleteMetadata called")
+
+		err = v.ContentService.DeleteMetaData(reqCtx, &req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, err, "Cant delete the metadata")
+		}
+
+		return utils.SuccessResponse[any](ctx, nil)
+	}
+}
@@ -2,6 +2,7 @@ package metadata
 
 import (
 	"agentic/commerce/config"
+	"agentic/commerce/internal/core"
 	"agentic/commerce/pkg/specs/api"
 	"errors"
 	"strconv"
@@ -11,6 +12,7 @@ import (
 
 type IContentMapper interface {
 	mapContentRequestToModel(*api.MetadataRequest, string) (*MetaDataModel, error)
+	mapMetadataBody(body api.MetaDataBody) core.JSON
 	mapToMetadataList(res []MetaDataModel) []api.MetadataItemResponse
 	mapToMetadataItem(res *MetaDataModel) *api.MetadataItemResponse
 }
@@ -29,25 +31,27 @@ func (m *contentMapper) mapContentRequestToModel(req *api.MetadataRequest, uuid
 		return nil, errors.New("meta data request is empty")
 	}
 
-	metadata := map[string]interface{}{
-		"desc":     req.MetaData.Desc,
-		"images":   req.MetaData.Images,
-		"location": req.MetaData.Location,
-	}
-
 	v, err := strconv.ParseInt(req.UserId, 10, 64)
 	if err != nil {
 		return nil, err
 	}
 
 	return &MetaDataModel{
-		UUid:     lo.ToPtr(uuid),
+		UUid:     lo.ToPtr(core.UUID(uuid)),
 		UserId:   lo.ToPtr(v),
-		Metadata: metadata,
+		Metadata: m.mapMetadataBody(req.MetaData),
 	}, nil
 
 }
 
+func (m *contentMapper) mapMetadataBody(body api.MetaDataBody) core.JSON {
+	return core.JSON{
+		"desc":     body.Desc,
+		"images":   body.Images,
+		"location": body.Location,
+	}
+}
+
 func (m *contentMapper) mapToMetadataItem(res *MetaDataModel) *api.MetadataItemResponse {
 	if res == nil {
 		return nil
@@ -2,6 +2,7 @@ package metadata
 
 import (
 	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/internal/infrastructure/partition"
 
 	"go.uber.org/fx"
 )
@@ -9,8 +10,20 @@ import (
 var Module = fx.Module(
 	"content-request",
 	fx.Provide(NewContentRepository),
+	fx.Decorate(NewCachedContentRepository),
 	fx.Provide(NewContentMapper),
 	fx.Provide(NewContentService),
+	fx.Provide(NewSeeder),
 	fx.Invoke(RegisterRoutes),
 	database.AsModel(&MetaDataModel{}),
+	partition.AsMonthly(&MetaDataModel{}, "created_at"),
+)
+
+// InMemoryModule is Module without a database, see testkit.Module.
+var InMemoryModule = fx.Module(
+	"content-request",
+	fx.Provide(NewInMemoryContentRepository),
+	fx.Provide(NewContentMapper),
+	fx.Provide(NewContentService),
+	fx.Invoke(RegisterRoutes),
 )
@@ -2,42 +2,92 @@ package metadata
 
 import (
 	"context"
+	"time"
 
+	"agentic/commerce/config"
 	"agentic/commerce/internal/core"
 
 	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/pkg/idgen"
+
+	"github.com/google/uuid"
 )
 
+// createdAtSlack is how far created_at may be from the time of a UUIDv7 for
+// createdAtWindow: the id is generated just before the insert sets created_at, and a
+// day still prunes all but one or two monthly partitions.
+const createdAtSlack = 24 * time.Hour
+
 type IContentRepository interface {
 	core.IBaseRepository[MetaDataModel]
 	ListByUserID(ctx context.Context, userId int64) ([]MetaDataModel, error)
+	// ListByUserIDCreatedBetween lists the posts of the user created in [from, to); a
+	// zero bound is left open.
+	ListByUserIDCreatedBetween(ctx context.Context, userId int64, from, to time.Time) ([]MetaDataModel, error)
 	GetByUserID(ctx context.Context, uuid string, userId int64) (*MetaDataModel, error)
+	// GetByUUID finds a post whoever owns it.
+	GetByUUID(ctx context.Context, uuid string) (*MetaDataModel, error)
 }
 
 type contentRepository struct {
 	core.IBaseRepository[MetaDataModel]
-	database database.GormDB
 }
 
 func NewContentRepository(database database.GormDB) IContentRepository {
 	return &contentRepository{
 		IBaseRepository: core.NewBaseRepository[MetaDataModel](database),
-		database:        database,
 	}
 }
 
 func (db *contentRepository) ListByUserID(ctx context.Context, userId int64) ([]MetaDataModel, error) {
-	var result []MetaDataModel
-	tx := db.database(ctx).Model(&MetaDataModel{}).
-		Where("user_id = ?", userId).
-		Find(&result)
-	return core.ResolveDBSliceResult(result, tx)
+	return db.FindAll(ctx, core.Eq("user_id", userId))
+}
+
+func (db *contentRepository) ListByUserIDCreatedBetween(ctx context.Context, userId int64, from, to time.Time) ([]MetaDataModel, error) {
+	specs := []core.Specification{core.Eq("user_id", userId)}
+	if !from.IsZero() {
+		specs = append(specs, core.Gte("created_at", from))
+	}
+	if !to.IsZero() {
+		specs = append(specs, core.Lt("created_at", to))
+	}
+	return db.FindAll(ctx, specs...)
 }
 
 func (db *contentRepository) GetByUserID(ctx context.Context, uuid string, userId int64) (*MetaDataModel, error) {
-	var result *MetaDataModel
-	tx := db.database(ctx).Model(&MetaDataModel{}).
-		Where("user_id = ? and uuid = ?", userId, uuid).
-		First(&result)
-	return core.ResolveDBResult(result, tx)
+	specs := append([]core.Specification{core.Eq("user_id", userId), core.Eq("uuid", uuid)}, createdAtWindow(uuid)...)
+	return db.FindOne(ctx, specs...)
+}
+
+func (db *contentRepository) GetByUUID(ctx context.Context, uuid string) (*MetaDataModel, error) {
+	return db.FindOne(ctx, append([]core.Specification{core.Eq("uuid", uuid)}, createdAtWindow(uuid)...)...)
+}
+
+// createdAtWindow bounds created_at around the time of a UUIDv7, so Postgres prunes the
+// partitions of meta_data_models a lookup by uuid would otherwise all scan. Other ids
+// get no bound.
+func createdAtWindow(id string) []core.Specification {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return nil
+	}
+	at, ok := idgen.Time(parsed)
+	if !ok {
+		return nil
+	}
+	return []core.Specification{
+		core.Gte("created_at", at.Add(-createdAtSlack)),
+		core.Lt("created_at", at.Add(createdAtSlack)),
+	}
+}
+
+// NewInMemoryContentRepository backs the repository with core.NewInMemoryRepository, for tests.
+func NewInMemoryContentRepository(tenancyCfg *config.TenancyConfig) IContentRepository {
+	var defaultTenant string
+	if tenancyCfg != nil {
+		defaultTenant = tenancyCfg.DefaultTenant
+	}
+	return &contentRepository{
+		IBaseRepository: core.NewInMemoryRepository[MetaDataModel](defaultTenant),
+	}
 }
@@ -0,0 +1,169 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/core"
+	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/internal/infrastructure/tenancy"
+	"agentic/commerce/pkg/idgen"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newSQLiteRepository backs the repository with a SQLite database scoped by
+// tenancy.Plugin, as app.NewDatabase sets it up. The database is a WAL file, so a
+// transaction and the reads around it use connections of their own.
+func newSQLiteRepository(t *testing.T) (IContentRepository, *gorm.DB) {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	if err := db.Use(&tenancy.Plugin{DefaultTenant: "default"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&MetaDataModel{}); err != nil {
+		t.Fatal(err)
+	}
+	return NewContentRepository(database.CreateGormDB(db)), db
+}
+
+func createPost(t *testing.T, repository IContentRepository, tenantID, uuid string, userID int64) *MetaDataModel {
+	t.Helper()
+	model := &MetaDataModel{UUid: lo.ToPtr(core.UUID(uuid)), UserId: lo.ToPtr(userID)}
+	if err := repository.Create(tenancy.WithTenant(context.Background(), tenantID), model); err != nil {
+		t.Fatal(err)
+	}
+	return model
+}
+
+func TestContentRepositoryCrossTenantReads(t *testing.T) {
+	repository, _ := newSQLiteRepository(t)
+	createPost(t, repository, "a", "00000000-0000-0000-0000-00000000000a", 1)
+	theirs := createPost(t, repository, "b", "00000000-0000-0000-0000-00000000000b", 1)
+
+	ctxA := tenancy.WithTenant(context.Background(), "a")
+
+	found, err := repository.GetByUUID(ctxA, string(*theirs.UUid))
+	if err != nil || found != nil {
+		t.Fatalf("tenant a read the post of tenant b: %v, %v", found, err)
+	}
+	found, err = repository.FindByID(ctxA, theirs.ID)
+	if err != nil || found != nil {
+		t.Fatalf("tenant a read the post of tenant b by id: %v, %v", found, err)
+	}
+
+	posts, err := repository.ListByUserID(ctxA, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 1 || posts[0].TenantID != "a" {
+		t.Fatalf("tenant a listed %+v", posts)
+	}
+
+	count, err := repository.Count(ctxA)
+	if err != nil || count != 1 {
+		t.Fatalf("tenant a counted %d posts: %v", count, err)
+	}
+
+	// without a tenant the default one applies, which owns nothing here
+	posts, err = repository.ListByUserID(context.Background(), 1)
+	if err != nil || len(posts) != 0 {
+		t.Fatalf("the default tenant listed %+v: %v", posts, err)
+	}
+
+	posts, err = repository.ListByUserID(tenancy.WithoutTenantScope(context.Background()), 1)
+	if err != nil || len(posts) != 2 {
+		t.Fatalf("an unscoped context listed %d posts: %v", len(posts), err)
+	}
+}
+
+func TestContentRepositoryCrossTenantWrites(t *testing.T) {
+	repository, db := newSQLiteRepository(t)
+	theirs := createPost(t, repository, "b", "00000000-0000-0000-0000-00000000000b", 1)
+
+	ctxA := tenancy.WithTenant(context.Background(), "a")
+
+	hijack := *theirs
+	hijack.UserId = lo.ToPtr(int64(2))
+	if err := repository.Update(ctxA, &hijack); err != nil {
+		t.Fatal(err)
+	}
+	if err := repository.Delete(ctxA, &hijack); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("tenant a deleted the post of tenant b: %v", err)
+	}
+
+	forged := &MetaDataModel{
+		BaseModel: core.BaseModel{TenantID: "b"},
+		UUid:      lo.ToPtr(core.UUID("00000000-0000-0000-0000-0000000000ff")),
+	}
+	if err := repository.Create(ctxA, forged); err == nil {
+		t.Fatal("tenant a created a post for tenant b")
+	}
+
+	var stored MetaDataModel
+	if err := db.WithContext(tenancy.WithoutTenantScope(context.Background())).First(&stored, theirs.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.DeletedAt != 0 || *stored.UserId != 1 || stored.TenantID != "b" {
+		t.Fatalf("tenant a changed the post of tenant b: %+v", stored)
+	}
+}
+
+func TestContentRepositoryBoundsUUIDLookupsByCreationTime(t *testing.T) {
+	sqliteRepository, _ := newSQLiteRepository(t)
+	repositories := map[string]IContentRepository{
+		"gorm":      sqliteRepository,
+		"in memory": NewInMemoryContentRepository(&config.TenancyConfig{DefaultTenant: "default"}),
+	}
+	for name, repository := range repositories {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			id, err := idgen.NewFor(&MetaDataModel{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			createPost(t, repository, "default", id.String(), 1)
+			if found, err := repository.GetByUUID(ctx, id.String()); err != nil || found == nil {
+				t.Fatalf("the post wasn't found by uuid: %v", err)
+			}
+			if found, err := repository.GetByUserID(ctx, id.String(), 1); err != nil || found == nil {
+				t.Fatalf("the post wasn't found by uuid and user: %v", err)
+			}
+
+			// created_at months away from the time of the uuid is outside the window
+			at, _ := idgen.Time(id)
+			moved, err := uuid.NewV7()
+			if err != nil {
+				t.Fatal(err)
+			}
+			model := &MetaDataModel{
+				BaseModel: core.BaseModel{CreatedAt: at.AddDate(0, -3, 0)},
+				UUid:      lo.ToPtr(core.UUID(moved.String())),
+				UserId:    lo.ToPtr(int64(1)),
+			}
+			if err := repository.Create(ctx, model); err != nil {
+				t.Fatal(err)
+			}
+			if found, err := repository.GetByUUID(ctx, moved.String()); err != nil || found != nil {
+				t.Fatalf("a post created months before its uuid was found: %+v, %v", found, err)
+			}
+		})
+	}
+}
@@ -3,28 +3,41 @@ package metadata
 import (
 	"go/types"
 
+	"agentic/commerce/internal/infrastructure/authz"
 	"agentic/commerce/internal/interfaces/http"
+	"agentic/commerce/internal/interfaces/http/middleware"
 	"agentic/commerce/pkg/logger"
 	"agentic/commerce/pkg/specs/api"
-
-	echoAdapter "github.com/TickLabVN/tonic/adapters/echo"
 )
 
-func RegisterRoutes(s *http.Server, shipmentService IContentService, logger *logger.AppLogger) *http.Server {
+func RegisterRoutes(s *http.Server, shipmentService IContentService, policy *authz.Policy, logger *logger.AppLogger) *http.Server {
 	contentResourceObj := NewContentResource(shipmentService, logger)
 
 	apis := s.Router.Group("/metadata")
 
-	echoAdapter.AddRoute[api.MetadataRequest, api.APIResponse[types.Nil]](s.Spec,
-		apis.POST("", contentResourceObj.CreateMetadata()),
+	http.AddRoute[api.MetadataRequest, api.APIResponse[types.Nil]](s,
+		apis.POST("", contentResourceObj.CreateMetadata(), middleware.RequirePermission(policy, "metadata:create:own")),
+		middleware.SecurityUser|middleware.SecurityAPIKey,
+	)
+
+	http.AddRoute[api.MetadataIDAwareRequest, api.APIResponse[api.MetadataItemResponse]](s,
+		apis.GET("/:id", contentResourceObj.GetMetadata(), middleware.RequirePermission(policy, "metadata:read:own")),
+		middleware.SecurityUser|middleware.SecurityAPIKey,
+	)
+
+	http.AddRoute[api.MetadataListRequest, api.ApiPaginateResponse[api.MetadataItemResponse]](s,
+		apis.GET("/list", contentResourceObj.ListMetadata(), middleware.RequirePermission(policy, "metadata:read:own")),
+		middleware.SecurityUser|middleware.SecurityAPIKey,
 	)
 
-	echoAdapter.AddRoute[api.MetadataIDAwareRequest, api.APIResponse[api.MetadataItemResponse]](s.Spec,
-		apis.GET("/:id", contentResourceObj.GetMetadata()),
+	http.AddRoute[api.MetadataUpdateRequest, api.APIResponse[api.MetadataItemResponse]](s,
+		apis.PUT("/:id", contentResourceObj.UpdateMetadata(), middleware.RequirePermission(policy, "metadata:update:own")),
+		middleware.SecurityUser|middleware.SecurityAPIKey,
 	)
 
-	echoAdapter.AddRoute[types.Nil, api.ApiPaginateResponse[api.MetadataItemResponse]](s.Spec,
-		apis.GET("/list", contentResourceObj.ListMetadata()),
+	http.AddRoute[api.MetadataIDAwareRequest, api.APIResponse[types.Nil]](s,
+		apis.DELETE("/:id", contentResourceObj.DeleteMetadata(), middleware.RequirePermission(policy, "metadata:delete:own")),
+		middleware.SecurityUser|middleware.SecurityAPIKey,
 	)
 
 	return s
@@ -0,0 +1,181 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"agentic/commerce/internal/core"
+	"agentic/commerce/pkg/faker"
+	"agentic/commerce/pkg/logger"
+
+	"github.com/samber/lo"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	DefaultSeedUsers     = 100
+	DefaultSeedPosts     = 10_000
+	DefaultSeedBatchSize = 500
+	DefaultSeedWorkers   = 4
+	DefaultSeedPeriod    = 365 * 24 * time.Hour
+	// MaxSeedUsers is how many distinct six digit user ids there are.
+	MaxSeedUsers = 900_000
+)
+
+// SeedOptions shapes the fake posts of ISeeder.Seed; zero values take the defaults.
+type SeedOptions struct {
+	Seed      int64
+	Users     int
+	Posts     int
+	BatchSize int
+	Workers   int
+	// PersianShare is the fraction of users writing in Persian, the others write in English.
+	PersianShare float64
+	// Posts are spread over the Period before Until.
+	Until  time.Time
+	Period time.Duration
+}
+
+// ISeeder fills the database with fake posts for development and load tests.
+type ISeeder interface {
+	// Seed inserts the posts in concurrent batches, calling progress with the number of
+	// posts inserted so far. The same seed, users, posts and period always generate the
+	// same posts, whatever the batch size and number of workers.
+	Seed(ctx context.Context, opts SeedOptions, progress func(inserted int)) error
+}
+
+type seeder struct {
+	repository IContentRepository
+	logger     *logger.AppLogger
+}
+
+func NewSeeder(repository IContentRepository, logger *logger.AppLogger) ISeeder {
+	return &seeder{
+		repository: repository,
+		logger:     logger.WithScope(&seeder{}),
+	}
+}
+
+// fakeUser posts in one language, mostly around their home city.
+type fakeUser struct {
+	id   int64
+	lang faker.Lang
+	city faker.City
+}
+
+func (s *seeder) Seed(ctx context.Context, opts SeedOptions, progress func(inserted int)) error {
+	opts = opts.normalize()
+	if opts.Users <= 0 || opts.Posts <= 0 {
+		return errors.New("seeding needs at least one user and one post")
+	}
+	if opts.Users > MaxSeedUsers {
+		return fmt.Errorf("cannot seed more than %d users", MaxSeedUsers)
+	}
+
+	users := fakeUsers(opts)
+	batches := (opts.Posts + opts.BatchSize - 1) / opts.BatchSize
+	var inserted atomic.Int64
+
+	group, ctx := errgroup.WithContext(ctx)
+	group.SetLimit(opts.Workers)
+	for batch := 0; batch < batches; batch++ {
+		size := min(opts.BatchSize, opts.Posts-batch*opts.BatchSize)
+		group.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			posts := fakePosts(opts, users, batch, size)
+			if err := s.repository.CreateInBatches(ctx, posts, opts.BatchSize); err != nil {
+				s.logger.Error("cannot insert seed batch {}", err, batch)
+				return err
+			}
+			if progress != nil {
+				progress(int(inserted.Add(int64(len(posts)))))
+			}
+			return nil
+		})
+	}
+	return group.Wait()
+}
+
+func (o SeedOptions) normalize() SeedOptions {
+	if o.Users == 0 {
+		o.Users = DefaultSeedUsers
+	}
+	if o.Posts == 0 {
+		o.Posts = DefaultSeedPosts
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = DefaultSeedBatchSize
+	}
+	if o.Workers <= 0 {
+		o.Workers = DefaultSeedWorkers
+	}
+	if o.Until.IsZero() {
+		o.Until = time.Now()
+	}
+	if o.Period <= 0 {
+		o.Period = DefaultSeedPeriod
+	}
+	o.PersianShare = min(max(o.PersianShare, 0), 1)
+	return o
+}
+
+// fakeUsers draws distinct six digit ids from stream 0 of the seed.
+func fakeUsers(opts SeedOptions) []fakeUser {
+	f := faker.New(opts.Seed, 0)
+	users := make([]fakeUser, 0, opts.Users)
+	seen := make(map[int64]bool, opts.Users)
+	for len(users) < opts.Users {
+		id := int64(f.Between(100_000, 999_999))
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		users = append(users, fakeUser{id: id, lang: f.Lang(opts.PersianShare), city: f.City()})
+	}
+	return users
+}
+
+// fakePosts generates the posts of batch, each from the stream of its position so the
+// batch size doesn't change them.
+func fakePosts(opts SeedOptions, users []fakeUser, batch, size int) []MetaDataModel {
+	posts := make([]MetaDataModel, size)
+	for i := range posts {
+		posts[i] = fakePost(opts, users, batch*opts.BatchSize+i)
+	}
+	return posts
+}
+
+// fakePost picks the author from a Zipf distribution so a few users have most of the
+// posts, as on a real feed.
+func fakePost(opts SeedOptions, users []fakeUser, index int) MetaDataModel {
+	f := faker.New(opts.Seed, uint64(index)+1)
+	user := users[rand.NewZipf(f.Rand(), 1.2, 1, uint64(len(users)-1)).Uint64()]
+	city := user.city
+	// one post in five is away from home
+	if f.IntN(5) == 0 {
+		city = f.City()
+	}
+
+	images := make([]string, f.Between(0, 4))
+	for i := range images {
+		images[i] = f.ImageURL()
+	}
+
+	createdAt := f.TimeBetween(opts.Until.Add(-opts.Period), opts.Until)
+	return MetaDataModel{
+		BaseModel: core.BaseModel{CreatedAt: createdAt, UpdatedAt: createdAt},
+		UUid:      lo.ToPtr(core.UUID(f.UUIDv7(createdAt).String())),
+		UserId:    lo.ToPtr(user.id),
+		Metadata: core.JSON{
+			"desc":     f.Paragraph(user.lang, city),
+			"images":   images,
+			"location": city.Location(user.lang),
+		},
+	}
+}
@@ -0,0 +1,89 @@
+package metadata
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"agentic/commerce/config"
+	"agentic/commerce/pkg/logger"
+)
+
+// seedPosts seeds a fresh database and returns its posts ordered by uuid.
+func seedPosts(t *testing.T, opts SeedOptions) []MetaDataModel {
+	t.Helper()
+	repository, db := newSQLiteRepository(t)
+	appLogger := logger.NewAppLogger(&config.Config{Mode: config.ModeDev, Logger: &config.Logger{Level: config.LevelWarn}})
+
+	// the workers report their progress concurrently
+	var mu sync.Mutex
+	var progressed int
+	progress := func(inserted int) {
+		mu.Lock()
+		defer mu.Unlock()
+		progressed = max(progressed, inserted)
+	}
+	if err := NewSeeder(repository, appLogger).Seed(context.Background(), opts, progress); err != nil {
+		t.Fatal(err)
+	}
+	if progressed != opts.Posts {
+		t.Fatalf("progress reached %d posts, want %d", progressed, opts.Posts)
+	}
+
+	var posts []MetaDataModel
+	if err := db.Order("uuid").Find(&posts).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != opts.Posts {
+		t.Fatalf("seeded %d posts, want %d", len(posts), opts.Posts)
+	}
+	return posts
+}
+
+func TestSeedIsReproducible(t *testing.T) {
+	base := SeedOptions{
+		Seed:         42,
+		Users:        20,
+		Posts:        120,
+		PersianShare: 0.5,
+		Until:        time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+		Period:       30 * 24 * time.Hour,
+	}
+	// one batch inserted by one worker
+	single := base
+	single.BatchSize, single.Workers = base.Posts, 1
+	want := seedPosts(t, single)
+
+	tests := []struct {
+		name      string
+		batchSize int
+		workers   int
+	}{
+		{name: "small batches", batchSize: 7, workers: 1},
+		{name: "concurrent batches", batchSize: 7, workers: 4},
+		{name: "uneven batches", batchSize: 50, workers: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := base
+			opts.BatchSize, opts.Workers = tt.batchSize, tt.workers
+			got := seedPosts(t, opts)
+			for i := range want {
+				if *got[i].UUid != *want[i].UUid || *got[i].UserId != *want[i].UserId ||
+					!got[i].CreatedAt.Equal(want[i].CreatedAt) || !reflect.DeepEqual(got[i].Metadata, want[i].Metadata) {
+					t.Fatalf("post %d is %+v, want %+v", i, got[i], want[i])
+				}
+			}
+		})
+	}
+
+	t.Run("another seed", func(t *testing.T) {
+		opts := base
+		opts.Seed++
+		if got := seedPosts(t, opts); *got[0].UUid == *want[0].UUid {
+			t.Fatal("another seed generated the same posts")
+		}
+	})
+}
@@ -1,69 +1,266 @@
 package metadata
 
 import (
+	"agentic/commerce/internal/infrastructure/authz"
+	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/internal/infrastructure/eventbus"
+	"agentic/commerce/internal/infrastructure/outbox"
 	"agentic/commerce/internal/interfaces/http/middleware"
 	"agentic/commerce/pkg/apperror"
+	"agentic/commerce/pkg/idgen"
 	"agentic/commerce/pkg/logger"
 	"agentic/commerce/pkg/specs/api"
 
-	"github.com/google/uuid"
-
 	"context"
+	"errors"
 )
 
 type IContentService interface {
 	CreateMetaData(ctx context.Context, req *api.MetadataRequest) (*api.MetadataResponse, error)
 	GetMetaData(ctx context.Context, req *api.MetadataIDAwareRequest) (*api.MetadataItemResponse, error)
-	ListMetaData(ctx context.Context) ([]api.MetadataItemResponse, error)
+	ListMetaData(ctx context.Context, req *api.MetadataListRequest) ([]api.MetadataItemResponse, error)
+	UpdateMetaData(ctx context.Context, req *api.MetadataUpdateRequest) (*api.MetadataItemResponse, error)
+	DeleteMetaData(ctx context.Context, req *api.MetadataIDAwareRequest) error
 }
 
+const (
+	AggregateType        = "metadata"
+	EventMetadataCreated = "metadata.created"
+	EventMetadataUpdated = "metadata.updated"
+	EventMetadataDeleted = "metadata.deleted"
+
+	// Resource is the resource of the metadata permissions, such as metadata:read:own.
+	Resource = "metadata"
+)
+
 type contentService struct {
 	repository IContentRepository
 	logger     *logger.AppLogger
 	mappers    IContentMapper
+	txManager  database.ITransactionManager
+	outbox     outbox.IOutbox
+	events     eventbus.IEventBus
+	policy     *authz.Policy
 }
 
 func NewContentService(
 	logger *logger.AppLogger,
 	repository IContentRepository,
 	mappers IContentMapper,
-
+	txManager database.ITransactionManager,
+	outbox outbox.IOutbox,
+	events eventbus.IEventBus,
+	policy *authz.Policy,
 ) IContentService {
 	return &contentService{
 		repository: repository,
 		logger:     logger.WithScope(&contentService{}),
 		mappers:    mappers,
+		txManager:  txManager,
+		outbox:     outbox,
+		events:     events,
+		policy:     policy,
 	}
 }
 
 func (s *contentService) CreateMetaData(ctx context.Context, req *api.MetadataRequest) (*api.MetadataResponse, error) {
-	model, err := s.mappers.mapContentRequestToModel(req, uuid.New().String())
+	id, err := idgen.NewFor(&MetaDataModel{})
+	if err != nil {
+		s.logger.Error("cannot generate metadata id", err)
+		return nil, apperror.ErrServer
+	}
+	model, err := s.mappers.mapContentRequestToModel(req, id.String())
 	if err != nil {
 		return nil, err
 	}
-	err = s.repository.Create(ctx, model)
+	if err := s.policy.AuthorizeOwner(ctx, Resource, "create", *model.UserId); err != nil {
+		return nil, err
+	}
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.repository.Create(ctx, model); err != nil {
+			return err
+		}
+		return s.outbox.Add(ctx, AggregateType, model.UUid.String(), EventMetadataCreated, map[string]interface{}{
+			"uuid":     model.UUid.String(),
+			"user_id":  *model.UserId,
+			"metadata": map[string]interface{}(model.Metadata),
+		})
+	})
 	if err != nil {
-		return nil, apperror.ErrServer
+		s.logger.Error("cannot create metadata", err)
+		return nil, database.ResolveError(err)
 	}
 
+	s.publish(ctx, MetadataCreated{UUID: model.UUid.String(), UserID: *model.UserId, Model: *model})
+
 	return &api.MetadataResponse{
-		UUID: *model.UUid,
+		UUID: model.UUid.String(),
 	}, err
 }
 func (s *contentService) GetMetaData(ctx context.Context, req *api.MetadataIDAwareRequest) (*api.MetadataItemResponse, error) {
-	res, err := s.repository.GetByUserID(ctx, req.ID, middleware.GetUserID(ctx))
+	id, err := parseID(req.ID)
 	if err != nil {
-		return nil, apperror.ErrServer
+		return nil, err
+	}
+
+	scope, err := s.policy.Authorize(ctx, Resource, "read")
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.find(ctx, scope, id)
+	if err == nil && res == nil {
+		// the replica may not have caught up with a post created right before
+		res, err = s.find(database.WithPrimary(ctx), scope, id)
+	}
+	if err != nil {
+		return nil, database.ResolveError(err)
+	}
+	if res == nil {
+		return nil, apperror.ErrNotFound
 	}
 
-	return s.mappers.mapToMetadataItem(res), err
+	return s.mappers.mapToMetadataItem(res), nil
 }
 
-func (s *contentService) ListMetaData(ctx context.Context) ([]api.MetadataItemResponse, error) {
-	res, err := s.repository.ListByUserID(ctx, middleware.GetUserID(ctx))
+func (s *contentService) ListMetaData(ctx context.Context, req *api.MetadataListRequest) ([]api.MetadataItemResponse, error) {
+	userID := req.UserID
+	if userID == 0 {
+		userID = middleware.GetUserID(ctx)
+	}
+	if userID == 0 {
+		// an API key owns no posts, it has to name the user
+		return nil, apperror.ErrBadRequest
+	}
+	if err := s.policy.AuthorizeOwner(ctx, Resource, "read", userID); err != nil {
+		return nil, err
+	}
+
+	var res []MetaDataModel
+	var err error
+	if req.CreatedFrom.IsZero() && req.CreatedTo.IsZero() {
+		res, err = s.repository.ListByUserID(ctx, userID)
+	} else {
+		if !req.CreatedFrom.IsZero() && !req.CreatedTo.IsZero() && !req.CreatedFrom.Before(req.CreatedTo) {
+			return nil, apperror.ErrBadRequest
+		}
+		res, err = s.repository.ListByUserIDCreatedBetween(ctx, userID, req.CreatedFrom, req.CreatedTo)
+	}
 	if err != nil {
-		return nil, apperror.ErrServer
+		return nil, database.ResolveError(err)
 	}
 
 	return s.mappers.mapToMetadataList(res), err
 }
+
+func (s *contentService) UpdateMetaData(ctx context.Context, req *api.MetadataUpdateRequest) (*api.MetadataItemResponse, error) {
+	id, err := parseID(req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	scope, err := s.policy.Authorize(ctx, Resource, "update")
+	if err != nil {
+		return nil, err
+	}
+
+	var model *MetaDataModel
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+		model, err = s.find(ctx, scope, id)
+		if err != nil {
+			return err
+		}
+		if model == nil {
+			return apperror.ErrNotFound
+		}
+
+		model.Metadata = s.mappers.mapMetadataBody(req.MetaData)
+		if err := s.repository.Update(ctx, model); err != nil {
+			return err
+		}
+		return s.outbox.Add(ctx, AggregateType, model.UUid.String(), EventMetadataUpdated, map[string]interface{}{
+			"uuid":     model.UUid.String(),
+			"user_id":  *model.UserId,
+			"metadata": map[string]interface{}(model.Metadata),
+		})
+	})
+	if errors.Is(err, apperror.ErrNotFound) {
+		return nil, apperror.ErrNotFound
+	}
+	if err != nil {
+		s.logger.Error("cannot update metadata", err)
+		return nil, database.ResolveError(err)
+	}
+
+	s.publish(ctx, MetadataUpdated{UUID: model.UUid.String(), UserID: *model.UserId, Model: *model})
+
+	return s.mappers.mapToMetadataItem(model), nil
+}
+
+func (s *contentService) DeleteMetaData(ctx context.Context, req *api.MetadataIDAwareRequest) error {
+	id, err := parseID(req.ID)
+	if err != nil {
+		return err
+	}
+
+	scope, err := s.policy.Authorize(ctx, Resource, "delete")
+	if err != nil {
+		return err
+	}
+
+	var userID int64
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		model, err := s.find(ctx, scope, id)
+		if err != nil {
+			return err
+		}
+		if model == nil {
+			return apperror.ErrNotFound
+		}
+		userID = *model.UserId
+
+		if err := s.repository.Delete(ctx, model); err != nil {
+			return err
+		}
+		return s.outbox.Add(ctx, AggregateType, id, EventMetadataDeleted, map[string]interface{}{
+			"uuid":    id,
+			"user_id": userID,
+		})
+	})
+	if errors.Is(err, apperror.ErrNotFound) {
+		return apperror.ErrNotFound
+	}
+	if err != nil {
+		s.logger.Error("cannot delete metadata", err)
+		return database.ResolveError(err)
+	}
+
+	s.publish(ctx, MetadataDeleted{UUID: id, UserID: userID})
+	return nil
+}
+
+// find looks a post up among the caller's own, or among everyone's with ScopeAny. A post
+// out of reach is not found rather than forbidden, so ids can't be probed.
+func (s *contentService) find(ctx context.Context, scope authz.Scope, id string) (*MetaDataModel, error) {
+	if scope == authz.ScopeAny {
+		return s.repository.GetByUUID(ctx, id)
+	}
+	return s.repository.GetByUserID(ctx, id, middleware.GetUserID(ctx))
+}
+
+// publish runs after the commit, so a failing subscriber can't undo the write; it is only logged.
+func (s *contentService) publish(ctx context.Context, event eventbus.Event) {
+	if err := s.events.Publish(ctx, event); err != nil {
+		s.logger.Error("subscribers failed on {}", err, event.EventName())
+	}
+}
+
+// parseID rejects malformed ids before they reach the database and normalizes ULIDs to
+// the stored uuid form.
+func parseID(raw string) (string, error) {
+	id, err := idgen.Parse(raw)
+	if err != nil {
+		return "", apperror.ErrBadRequest
+	}
+	return id.String(), nil
+}
@@ -1,7 +1,11 @@
 package domains
 
 import (
+	"agentic/commerce/internal/domains/apikey"
+	"agentic/commerce/internal/domains/audit"
+	"agentic/commerce/internal/domains/diagnostics"
 	"agentic/commerce/internal/domains/metadata"
+	"agentic/commerce/internal/domains/role"
 	"agentic/commerce/internal/infrastructure/database"
 
 	"go.uber.org/fx"
@@ -11,5 +15,10 @@ var Modules = fx.Module(
 	"domains",
 
 	fx.Provide(database.CreateGormDB),
+	fx.Provide(database.NewTransactionManager),
+	apikey.Module,
+	audit.Module,
+	diagnostics.Module,
 	metadata.Module,
+	role.Module,
 )
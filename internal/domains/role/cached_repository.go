@@ -0,0 +1,120 @@
+package role
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/infrastructure/cache"
+	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/internal/infrastructure/tenancy"
+	"agentic/commerce/pkg/logger"
+)
+
+// cachedRoleRepository serves the roles of a subject, read on every authorization, from
+// the cache and drops them on every write of the subject's assignments.
+type cachedRoleRepository struct {
+	IRoleRepository
+	cache  cache.Cache
+	ttl    time.Duration
+	logger *logger.AppLogger
+}
+
+func NewCachedRoleRepository(
+	repository IRoleRepository,
+	c cache.Cache,
+	cfg *config.CacheConfig,
+	logger *logger.AppLogger,
+) IRoleRepository {
+	if c == nil {
+		return repository
+	}
+	return &cachedRoleRepository{
+		IRoleRepository: repository,
+		cache:           c,
+		ttl:             cfg.TTL,
+		logger:          logger.WithScope(cachedRoleRepository{}),
+	}
+}
+
+func (r *cachedRoleRepository) AssignedRoles(ctx context.Context, subject string) ([]string, error) {
+	key := rolesCacheKey(ctx, subject)
+	// a transaction reads its own writes, and those stay out of the cache until it commits
+	if _, inTx := database.TxFromContext(ctx); inTx {
+		return r.IRoleRepository.AssignedRoles(ctx, subject)
+	}
+	cached, ok, err := cache.GetJSON[[]string](ctx, r.cache, key)
+	if err != nil {
+		r.logger.Warn("cache read failed for {}: {}", key, err.Error())
+	} else if ok {
+		return *cached, nil
+	}
+
+	roles, err := r.IRoleRepository.AssignedRoles(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+	if err := cache.SetJSON(ctx, r.cache, key, roles, r.ttl); err != nil {
+		r.logger.Warn("cache write failed for {}: {}", key, err.Error())
+	}
+	return roles, nil
+}
+
+func (r *cachedRoleRepository) Save(ctx context.Context, model *RoleAssignmentModel) error {
+	defer r.invalidateAfterCommit(ctx, model)
+	return r.IRoleRepository.Save(ctx, model)
+}
+
+func (r *cachedRoleRepository) Create(ctx context.Context, model *RoleAssignmentModel) error {
+	defer r.invalidateAfterCommit(ctx, model)
+	return r.IRoleRepository.Create(ctx, model)
+}
+
+func (r *cachedRoleRepository) CreateInBatches(ctx context.Context, models []RoleAssignmentModel, batchSize int) error {
+	defer func() {
+		for i := range models {
+			r.invalidateAfterCommit(ctx, &models[i])
+		}
+	}()
+	return r.IRoleRepository.CreateInBatches(ctx, models, batchSize)
+}
+
+func (r *cachedRoleRepository) Update(ctx context.Context, model *RoleAssignmentModel) error {
+	defer r.invalidateAfterCommit(ctx, model)
+	return r.IRoleRepository.Update(ctx, model)
+}
+
+func (r *cachedRoleRepository) Upsert(ctx context.Context, model *RoleAssignmentModel) error {
+	defer r.invalidateAfterCommit(ctx, model)
+	return r.IRoleRepository.Upsert(ctx, model)
+}
+
+func (r *cachedRoleRepository) Delete(ctx context.Context, model *RoleAssignmentModel) error {
+	defer r.invalidateAfterCommit(ctx, model)
+	return r.IRoleRepository.Delete(ctx, model)
+}
+
+// invalidateAfterCommit drops the roles once the assignment is visible, dropped before
+// the commit a concurrent authorization would cache the old roles again.
+func (r *cachedRoleRepository) invalidateAfterCommit(ctx context.Context, model *RoleAssignmentModel) {
+	database.AfterCommit(ctx, func(ctx context.Context) {
+		r.invalidate(ctx, model)
+	})
+}
+
+func (r *cachedRoleRepository) invalidate(ctx context.Context, model *RoleAssignmentModel) {
+	if model == nil || model.Subject == "" {
+		return
+	}
+	key := rolesCacheKey(ctx, model.Subject)
+	if err := r.cache.Delete(ctx, key); err != nil {
+		r.logger.Warn("cache invalidation failed for {}: {}", key, err.Error())
+	}
+}
+
+// keys carry the tenant, a role assigned in one tenant grants nothing in another
+func rolesCacheKey(ctx context.Context, subject string) string {
+	tenantID, _ := tenancy.FromContext(ctx)
+	return fmt.Sprintf("role:tenant:%s:subject:%s", tenantID, subject)
+}
@@ -0,0 +1,59 @@
+package role
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/infrastructure/cache"
+	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/internal/infrastructure/tenancy"
+	"agentic/commerce/pkg/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const cachePrefix = "test:"
+
+func TestCachedRoleRepositoryInvalidatesAfterCommit(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	repository, db := newSQLiteRepository(t)
+	appLogger := logger.NewAppLogger(&config.Config{Mode: config.ModeDev, Logger: &config.Logger{Level: config.LevelWarn}})
+	cached := NewCachedRoleRepository(repository, cache.NewRedisCache(client, cachePrefix), &config.CacheConfig{TTL: time.Hour}, appLogger)
+	txManager := database.NewTransactionManager(db)
+
+	ctx := tenancy.WithTenant(context.Background(), "a")
+	key := cachePrefix + rolesCacheKey(ctx, "1")
+	if roles, err := cached.AssignedRoles(ctx, "1"); err != nil || len(roles) != 0 {
+		t.Fatalf("assigned roles %v: %v", roles, err)
+	}
+
+	err := txManager.RunInTx(ctx, func(txCtx context.Context) error {
+		if err := cached.Create(txCtx, &RoleAssignmentModel{Subject: "1", Role: "admin"}); err != nil {
+			return err
+		}
+		// the transaction sees its assignment, the cache doesn't until the commit
+		if roles, err := cached.AssignedRoles(txCtx, "1"); err != nil || len(roles) != 1 {
+			t.Fatalf("the transaction read roles %v: %v", roles, err)
+		}
+		if current, err := server.Get(key); err != nil || current != "[]" {
+			t.Fatalf("the cache changed before the commit: %q, %v", current, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if server.Exists(key) {
+		t.Fatal("the key outlived the commit")
+	}
+	if roles, err := cached.AssignedRoles(ctx, "1"); err != nil || len(roles) != 1 {
+		t.Fatalf("assigned roles after the commit %v: %v", roles, err)
+	}
+}
@@ -0,0 +1,97 @@
+package role
+
+import (
+	"agentic/commerce/internal/utils"
+	"agentic/commerce/pkg/apperror"
+	"agentic/commerce/pkg/logger"
+	"agentic/commerce/pkg/specs/api"
+
+	"github.com/labstack/echo/v4"
+)
+
+type IRoleResource interface {
+	ListRoles() echo.HandlerFunc
+	ListAssignments() echo.HandlerFunc
+	AssignRole() echo.HandlerFunc
+	UnassignRole() echo.HandlerFunc
+}
+
+type roleResource struct {
+	RoleService IRoleService
+	Logger      *logger.AppLogger
+}
+
+func NewRoleResource(service IRoleService, logger *logger.AppLogger) IRoleResource {
+	return &roleResource{
+		RoleService: service,
+		Logger:      logger.WithScope(roleResource{}),
+	}
+}
+
+func (v *roleResource) ListRoles() echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		resp, err := v.RoleService.ListRoles(ctx.Request().Context())
+		if err != nil {
+			return utils.ErrorResponse(ctx, err, "Cant list the roles")
+		}
+
+		return utils.SuccessResponse(ctx, resp)
+	}
+}
+
+func (v *roleResource) ListAssignments() echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		var req api.RoleAssignmentListRequest
+		reqCtx := ctx.Request().Context()
+
+		err := ctx.Bind(&req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, apperror.ErrBadRequest, "Check your input: "+err.Error())
+		}
+
+		resp, err := v.RoleService.ListAssignments(reqCtx, &req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, err, "Cant list the role assignments")
+		}
+
+		return utils.SuccessResponse(ctx, resp)
+	}
+}
+
+func (v *roleResource) AssignRole() echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		var req api.RoleAssignmentRequest
+		reqCtx := ctx.Request().Context()
+
+		err := ctx.Bind(&req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, apperror.ErrBadRequest, "Check your input: "+err.Error())
+		}
+
+		resp, err := v.RoleService.AssignRole(reqCtx, &req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, err, "Cant assign the role")
+		}
+
+		return utils.SuccessResponse(ctx, resp)
+	}
+}
+
+func (v *roleResource) UnassignRole() echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		var req api.RoleAssignmentRequest
+		reqCtx := ctx.Request().Context()
+
+		err := ctx.Bind(&req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, apperror.ErrBadRequest, "Check your input: "+err.Error())
+		}
+
+		err = v.RoleService.UnassignRole(reqCtx, &req)
+		if err != nil {
+			return utils.ErrorResponse(ctx, err, "Cant unassign the role")
+		}
+
+		return utils.SuccessResponse[any](ctx, nil)
+	}
+}
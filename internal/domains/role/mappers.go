@@ -0,0 +1,38 @@
+package role
+
+import (
+	"agentic/commerce/internal/core"
+	"agentic/commerce/internal/infrastructure/authz"
+	"agentic/commerce/pkg/specs/api"
+
+	"github.com/samber/lo"
+)
+
+func mapToRole(name string, permissions []authz.Permission) api.RoleResponse {
+	return api.RoleResponse{
+		Name:        name,
+		Permissions: lo.Map(permissions, func(p authz.Permission, _ int) string { return p.String() }),
+	}
+}
+
+func mapToRoleAssignment(model *RoleAssignmentModel) api.RoleAssignmentResponse {
+	return api.RoleAssignmentResponse{
+		Subject:   model.Subject,
+		Role:      model.Role,
+		CreatedBy: model.CreatedBy,
+		CreatedAt: model.CreatedAt,
+	}
+}
+
+func mapToRoleAssignmentPage(page *core.Page[RoleAssignmentModel]) *api.ApiPaginateResponse[api.RoleAssignmentResponse] {
+	items := make([]api.RoleAssignmentResponse, 0, len(page.Items))
+	for i := range page.Items {
+		items = append(items, mapToRoleAssignment(&page.Items[i]))
+	}
+
+	return &api.ApiPaginateResponse[api.RoleAssignmentResponse]{
+		TotalPage:   uint(page.TotalPages),
+		CurrentPage: uint(page.Page),
+		Items:       items,
+	}
+}
@@ -0,0 +1,19 @@
+package role
+
+import "agentic/commerce/internal/core"
+
+// RoleAssignmentModel grants a role to a principal subject within a tenant, on top of
+// the roles of its token. The subject is a user id or "apikey:<prefix>". A tenant
+// assigns a role to a subject once; TenantID repeats the column of the BaseModel, which
+// it shadows, so the unique index can lead with it. The migrations leave soft deleted
+// assignments out of the index.
+type RoleAssignmentModel struct {
+	core.BaseModel
+	TenantID string `gorm:"Column:tenant_id;size:64;not null;default:'default';uniqueIndex:idx_role_assignments_subject_role,priority:1"`
+	Subject  string `gorm:"Column:subject;size:128;not null;uniqueIndex:idx_role_assignments_subject_role,priority:2"`
+	Role     string `gorm:"Column:role;size:64;not null;uniqueIndex:idx_role_assignments_subject_role,priority:3"`
+}
+
+func (RoleAssignmentModel) TableName() string {
+	return "role_assignments"
+}
@@ -0,0 +1,18 @@
+package role
+
+import (
+	"agentic/commerce/internal/infrastructure/authz"
+	"agentic/commerce/internal/infrastructure/database"
+
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module(
+	"role",
+	fx.Provide(NewRoleRepository),
+	fx.Decorate(NewCachedRoleRepository),
+	fx.Provide(func(r IRoleRepository) authz.RoleSource { return r }),
+	fx.Provide(NewRoleService),
+	fx.Invoke(RegisterRoutes),
+	database.AsModel(&RoleAssignmentModel{}),
+)
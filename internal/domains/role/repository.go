@@ -0,0 +1,40 @@
+package role
+
+import (
+	"context"
+
+	"agentic/commerce/internal/core"
+	"agentic/commerce/internal/infrastructure/database"
+
+	"github.com/samber/lo"
+)
+
+type IRoleRepository interface {
+	core.IBaseRepository[RoleAssignmentModel]
+	// AssignedRoles lists the roles of the subject, it makes the repository the
+	// authz.RoleSource of the policy.
+	AssignedRoles(ctx context.Context, subject string) ([]string, error)
+	FindAssignments(ctx context.Context, subject, role string) ([]RoleAssignmentModel, error)
+}
+
+type roleRepository struct {
+	core.IBaseRepository[RoleAssignmentModel]
+}
+
+func NewRoleRepository(database database.GormDB) IRoleRepository {
+	return &roleRepository{
+		IBaseRepository: core.NewBaseRepository[RoleAssignmentModel](database),
+	}
+}
+
+func (db *roleRepository) AssignedRoles(ctx context.Context, subject string) ([]string, error) {
+	assignments, err := db.FindAll(ctx, core.Eq("subject", subject))
+	if err != nil {
+		return nil, err
+	}
+	return lo.Uniq(lo.Map(assignments, func(a RoleAssignmentModel, _ int) string { return a.Role })), nil
+}
+
+func (db *roleRepository) FindAssignments(ctx context.Context, subject, role string) ([]RoleAssignmentModel, error) {
+	return db.FindAll(ctx, core.Eq("subject", subject), core.Eq("role", role))
+}
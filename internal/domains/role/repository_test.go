@@ -0,0 +1,74 @@
+package role
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/internal/infrastructure/migration"
+	"agentic/commerce/internal/infrastructure/tenancy"
+	"agentic/commerce/migrations"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newSQLiteRepository backs the repository with a SQLite database scoped by
+// tenancy.Plugin and migrated like a deployment, the partial unique index included.
+func newSQLiteRepository(t *testing.T) (IRoleRepository, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard, TranslateError: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	if err := db.Use(&tenancy.Plugin{DefaultTenant: "default"}); err != nil {
+		t.Fatal(err)
+	}
+	all, err := migrations.All("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migration.NewMigrator(db, all).Up(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+	return NewRoleRepository(database.CreateGormDB(db)), db
+}
+
+func TestRoleRepositoryAssignsARoleOncePerTenant(t *testing.T) {
+	repository, _ := newSQLiteRepository(t)
+	ctxA := tenancy.WithTenant(context.Background(), "a")
+
+	assignment := &RoleAssignmentModel{Subject: "1", Role: "admin"}
+	if err := repository.Create(ctxA, assignment); err != nil {
+		t.Fatal(err)
+	}
+	if assignment.TenantID != "a" {
+		t.Fatalf("the assignment went to tenant %q", assignment.TenantID)
+	}
+	if err := repository.Create(ctxA, &RoleAssignmentModel{Subject: "1", Role: "admin"}); err == nil {
+		t.Fatal("the role was assigned twice")
+	}
+	if err := repository.Create(tenancy.WithTenant(context.Background(), "b"), &RoleAssignmentModel{Subject: "1", Role: "admin"}); err != nil {
+		t.Fatalf("another tenant couldn't assign the role: %v", err)
+	}
+
+	// a soft deleted assignment leaves the role free to assign again
+	if err := repository.Delete(ctxA, assignment); err != nil {
+		t.Fatal(err)
+	}
+	if err := repository.Create(ctxA, &RoleAssignmentModel{Subject: "1", Role: "admin"}); err != nil {
+		t.Fatalf("the role couldn't be assigned again: %v", err)
+	}
+	roles, err := repository.AssignedRoles(ctxA, "1")
+	if err != nil || len(roles) != 1 {
+		t.Fatalf("assigned roles %v: %v", roles, err)
+	}
+}
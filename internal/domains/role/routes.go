@@ -0,0 +1,39 @@
+package role
+
+import (
+	"go/types"
+
+	"agentic/commerce/internal/infrastructure/authz"
+	"agentic/commerce/internal/interfaces/http"
+	"agentic/commerce/internal/interfaces/http/middleware"
+	"agentic/commerce/pkg/logger"
+	"agentic/commerce/pkg/specs/api"
+)
+
+func RegisterRoutes(s *http.Server, roleService IRoleService, policy *authz.Policy, logger *logger.AppLogger) *http.Server {
+	roleResourceObj := NewRoleResource(roleService, logger)
+
+	apis := s.Router.Group("/admin/roles")
+
+	http.AddRoute[api.RoleListRequest, api.APIResponse[[]api.RoleResponse]](s,
+		apis.GET("", roleResourceObj.ListRoles(), middleware.RequirePermission(policy, "role:read:any")),
+		middleware.SecurityAdmin,
+	)
+
+	http.AddRoute[api.RoleAssignmentListRequest, api.APIResponse[api.ApiPaginateResponse[api.RoleAssignmentResponse]]](s,
+		apis.GET("/assignments", roleResourceObj.ListAssignments(), middleware.RequirePermission(policy, "role:read:any")),
+		middleware.SecurityAdmin,
+	)
+
+	http.AddRoute[api.RoleAssignmentRequest, api.APIResponse[api.RoleAssignmentResponse]](s,
+		apis.PUT("/:role/subjects/:subject", roleResourceObj.AssignRole(), middleware.RequirePermission(policy, "role:assign:any")),
+		middleware.SecurityAdmin,
+	)
+
+	http.AddRoute[api.RoleAssignmentRequest, api.APIResponse[types.Nil]](s,
+		apis.DELETE("/:role/subjects/:subject", roleResourceObj.UnassignRole(), middleware.RequirePermission(policy, "role:assign:any")),
+		middleware.SecurityAdmin,
+	)
+
+	return s
+}
@@ -0,0 +1,170 @@
+package role
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"agentic/commerce/internal/core"
+	"agentic/commerce/internal/infrastructure/authz"
+	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/pkg/apperror"
+	"agentic/commerce/pkg/logger"
+	"agentic/commerce/pkg/specs/api"
+
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+var (
+	readRoles   = authz.MustParsePermission("role:read:any")
+	assignRoles = authz.MustParsePermission("role:assign:any")
+)
+
+type IRoleService interface {
+	ListRoles(ctx context.Context) ([]api.RoleResponse, error)
+	ListAssignments(ctx context.Context, req *api.RoleAssignmentListRequest) (*api.ApiPaginateResponse[api.RoleAssignmentResponse], error)
+	// AssignRole is idempotent. Only a principal holding every permission of the role
+	// may assign it.
+	AssignRole(ctx context.Context, req *api.RoleAssignmentRequest) (*api.RoleAssignmentResponse, error)
+	UnassignRole(ctx context.Context, req *api.RoleAssignmentRequest) error
+}
+
+type roleService struct {
+	repository IRoleRepository
+	policy     *authz.Policy
+	txManager  database.ITransactionManager
+	logger     *logger.AppLogger
+}
+
+func NewRoleService(
+	logger *logger.AppLogger,
+	repository IRoleRepository,
+	policy *authz.Policy,
+	txManager database.ITransactionManager,
+) IRoleService {
+	return &roleService{
+		repository: repository,
+		policy:     policy,
+		txManager:  txManager,
+		logger:     logger.WithScope(&roleService{}),
+	}
+}
+
+func (s *roleService) ListRoles(ctx context.Context) ([]api.RoleResponse, error) {
+	if err := s.policy.Require(ctx, readRoles); err != nil {
+		return nil, err
+	}
+	roles := make([]api.RoleResponse, 0)
+	for _, name := range s.policy.Roles() {
+		permissions, _ := s.policy.Permissions(name)
+		roles = append(roles, mapToRole(name, permissions))
+	}
+	return roles, nil
+}
+
+func (s *roleService) ListAssignments(ctx context.Context, req *api.RoleAssignmentListRequest) (*api.ApiPaginateResponse[api.RoleAssignmentResponse], error) {
+	if err := s.policy.Require(ctx, readRoles); err != nil {
+		return nil, err
+	}
+	specs := []core.Specification{core.OrderByDesc("id")}
+	if req.Subject != "" {
+		specs = append(specs, core.Eq("subject", req.Subject))
+	}
+	if req.Role != "" {
+		specs = append(specs, core.Eq("role", req.Role))
+	}
+
+	page, err := s.repository.Paginate(ctx, core.PageRequest{Page: req.Page, Size: req.Size}, specs...)
+	if err != nil {
+		s.logger.Error("cannot list role assignments", err)
+		return nil, database.ResolveError(err)
+	}
+	return mapToRoleAssignmentPage(page), nil
+}
+
+func (s *roleService) AssignRole(ctx context.Context, req *api.RoleAssignmentRequest) (*api.RoleAssignmentResponse, error) {
+	if err := s.authorizeAssignment(ctx, req); err != nil {
+		return nil, err
+	}
+
+	var assignment *RoleAssignmentModel
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		existing, err := s.repository.FindAssignments(ctx, req.Subject, req.Role)
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			assignment = &existing[0]
+			return nil
+		}
+		assignment = &RoleAssignmentModel{Subject: req.Subject, Role: req.Role}
+		return s.repository.Create(ctx, assignment)
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		// a concurrent assign inserted it between the find and the create
+		assignment, err = s.assignment(ctx, req)
+	}
+	if err != nil {
+		s.logger.Error("cannot assign role", err)
+		return nil, database.ResolveError(err)
+	}
+	return lo.ToPtr(mapToRoleAssignment(assignment)), nil
+}
+
+func (s *roleService) UnassignRole(ctx context.Context, req *api.RoleAssignmentRequest) error {
+	if err := s.authorizeAssignment(ctx, req); err != nil {
+		return err
+	}
+
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		existing, err := s.repository.FindAssignments(ctx, req.Subject, req.Role)
+		if err != nil {
+			return err
+		}
+		if len(existing) == 0 {
+			return apperror.ErrNotFound
+		}
+		for i := range existing {
+			if err := s.repository.Delete(ctx, &existing[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, apperror.ErrNotFound) {
+		return apperror.ErrNotFound
+	}
+	if err != nil {
+		s.logger.Error("cannot unassign role", err)
+		return database.ResolveError(err)
+	}
+	return nil
+}
+
+func (s *roleService) assignment(ctx context.Context, req *api.RoleAssignmentRequest) (*RoleAssignmentModel, error) {
+	existing, err := s.repository.FindAssignments(database.WithPrimary(ctx), req.Subject, req.Role)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) == 0 {
+		// deleted again meanwhile, the caller can retry
+		return nil, gorm.ErrDuplicatedKey
+	}
+	return &existing[0], nil
+}
+
+func (s *roleService) authorizeAssignment(ctx context.Context, req *api.RoleAssignmentRequest) error {
+	if err := s.policy.Require(ctx, assignRoles); err != nil {
+		return err
+	}
+	req.Subject = strings.TrimSpace(req.Subject)
+	if req.Subject == "" || len(req.Subject) > 128 {
+		return apperror.ErrValidation
+	}
+	permissions, ok := s.policy.Permissions(req.Role)
+	if !ok {
+		return apperror.ErrValidation
+	}
+	return s.policy.RequireAll(ctx, permissions)
+}
@@ -0,0 +1,56 @@
+package role
+
+import (
+	"context"
+	"testing"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/infrastructure/auth"
+	"agentic/commerce/internal/infrastructure/authz"
+	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/internal/infrastructure/tenancy"
+	"agentic/commerce/pkg/logger"
+	"agentic/commerce/pkg/specs/api"
+)
+
+// racingRepository misses the assignments on its first lookup, as when another assign
+// inserts the row between the find and the create.
+type racingRepository struct {
+	IRoleRepository
+	raced bool
+}
+
+func (r *racingRepository) FindAssignments(ctx context.Context, subject, role string) ([]RoleAssignmentModel, error) {
+	if !r.raced {
+		r.raced = true
+		return nil, nil
+	}
+	return r.IRoleRepository.FindAssignments(ctx, subject, role)
+}
+
+func TestAssignRoleIsIdempotentUnderARace(t *testing.T) {
+	repository, db := newSQLiteRepository(t)
+	appLogger := logger.NewAppLogger(&config.Config{Mode: config.ModeDev, Logger: &config.Logger{Level: config.LevelWarn}})
+	policy, err := authz.NewPolicy(authz.PolicyParams{Logger: appLogger})
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewRoleService(appLogger, &racingRepository{IRoleRepository: repository}, policy, database.NewTransactionManager(db))
+
+	ctx := tenancy.WithTenant(context.Background(), "a")
+	if err := repository.Create(ctx, &RoleAssignmentModel{Subject: "1", Role: auth.RoleAdmin}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx = auth.WithPrincipal(ctx, &auth.Principal{Kind: auth.PrincipalUser, Subject: "99", UserID: 99, Roles: []string{auth.RoleAdmin}})
+	assignment, err := service.AssignRole(ctx, &api.RoleAssignmentRequest{Subject: "1", Role: auth.RoleAdmin})
+	if err != nil {
+		t.Fatalf("the racing assign failed: %v", err)
+	}
+	if assignment.Subject != "1" || assignment.Role != auth.RoleAdmin {
+		t.Fatalf("assigned %+v", assignment)
+	}
+	if count, err := repository.Count(ctx); err != nil || count != 1 {
+		t.Fatalf("%d assignments: %v", count, err)
+	}
+}
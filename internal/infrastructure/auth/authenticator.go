@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"agentic/commerce/config"
+	"agentic/commerce/pkg/jwt"
+	"agentic/commerce/pkg/logger"
+
+	"go.uber.org/fx"
+)
+
+var (
+	// ErrNoCredentials is returned when the request carries no credential at all.
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidSubject is returned for a user token whose sub is not a user id.
+	ErrInvalidSubject = errors.New("token subject is not a user id")
+	// ErrNotConfigured is returned while no key is configured to verify tokens with.
+	ErrNotConfigured = errors.New("no token verification key configured")
+	// ErrInvalidAPIKey is returned for an API key that is unknown, revoked or malformed.
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	// ErrAPIKeyExpired is returned for an API key past its expiry.
+	ErrAPIKeyExpired = errors.New("api key is expired")
+)
+
+// sampleSecret is the HS256 secret an earlier sample config shipped with; anyone with the
+// repository can sign tokens with it.
+const sampleSecret = "dev-only-secret-change-me-32-bytes!!"
+
+// APIKeyResolver returns the principal of an API key, or an error wrapping
+// ErrInvalidAPIKey or ErrAPIKeyExpired.
+type APIKeyResolver interface {
+	ResolveAPIKey(ctx context.Context, key string) (*Principal, error)
+}
+
+type AuthenticatorParams struct {
+	fx.In
+
+	Config *config.AuthConfig
+	Mode   config.ModeEnum
+	Logger *logger.AppLogger
+	// APIKeys is provided by the apikey domain; graphs without it reject every API key.
+	APIKeys APIKeyResolver `optional:"true"`
+}
+
+// Authenticator turns the credential of a request into a Principal.
+type Authenticator struct {
+	verifier *jwt.Verifier
+	apiKeys  APIKeyResolver
+}
+
+// NewAuthenticator loads the keys of the config. Without any key the authenticator
+// rejects every token, which keeps commands that never serve requests working
+// unconfigured. In prod it refuses an HS256 key without a secret or with the sample one;
+// in dev such a key is skipped or warned about.
+func NewAuthenticator(params AuthenticatorParams) (*Authenticator, error) {
+	cfg := params.Config
+	if cfg == nil {
+		cfg = &config.AuthConfig{}
+	}
+	log := params.Logger.WithScope(Authenticator{})
+	keys, err := loadKeys(cfg, params.Mode, log)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		log.Warn("no auth keys configured, every bearer token is rejected")
+		return &Authenticator{apiKeys: params.APIKeys}, nil
+	}
+
+	verifier, err := jwt.NewVerifier(jwt.VerifierOptions{
+		Keys:      keys,
+		Issuer:    cfg.Issuer,
+		Audience:  cfg.Audience,
+		ClockSkew: cfg.ClockSkew,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Authenticator{verifier: verifier, apiKeys: params.APIKeys}, nil
+}
+
+// AuthenticateAPIKey resolves the principal of an X-API-Key header.
+func (a *Authenticator) AuthenticateAPIKey(ctx context.Context, key string) (*Principal, error) {
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+	if a.apiKeys == nil {
+		return nil, ErrInvalidAPIKey
+	}
+	return a.apiKeys.ResolveAPIKey(ctx, key)
+}
+
+// AuthenticateToken verifies a bearer token; its sub must be a numeric user id.
+func (a *Authenticator) AuthenticateToken(_ context.Context, token string) (*Principal, error) {
+	if token == "" {
+		return nil, ErrNoCredentials
+	}
+	if a.verifier == nil {
+		return nil, ErrNotConfigured
+	}
+	claims, err := a.verifier.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil || userID <= 0 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSubject, claims.Subject)
+	}
+	return &Principal{
+		Kind:      PrincipalUser,
+		Subject:   claims.Subject,
+		UserID:    userID,
+		Roles:     claims.Roles,
+		Scopes:    claims.Scopes,
+		Tenant:    claims.Tenant,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
+
+func loadKeys(cfg *config.AuthConfig, mode config.ModeEnum, log *logger.AppLogger) ([]*jwt.Key, error) {
+	var keys []*jwt.Key
+	for i, keyCfg := range cfg.Keys {
+		switch {
+		case keyCfg.HasSecret() && keyCfg.PublicKey != "":
+			return nil, fmt.Errorf("auth key %d: set either a secret or publicKey", i)
+		case keyCfg.HasSecret():
+			secret, err := keyCfg.ResolveSecret()
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case secret == "" && mode == config.ModeProd:
+				return nil, fmt.Errorf("auth key %q: the secret is not set", keyCfg.Kid)
+			case secret == "":
+				log.Warn("auth key {} has no secret, skipping it", keyCfg.Kid)
+				continue
+			case secret == sampleSecret && mode == config.ModeProd:
+				return nil, fmt.Errorf("auth key %q: the sample secret is public, set a secret of your own", keyCfg.Kid)
+			case secret == sampleSecret:
+				log.Warn("auth key {} uses the public sample secret, never in prod", keyCfg.Kid)
+			}
+			key, err := jwt.NewHMACKey(keyCfg.Kid, []byte(secret))
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		case keyCfg.PublicKey != "":
+			data, err := os.ReadFile(keyCfg.PublicKey)
+			if err != nil {
+				return nil, fmt.Errorf("auth key %d: %w", i, err)
+			}
+			key, err := jwt.ParsePublicKeyPEM(keyCfg.Kid, data)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		default:
+			return nil, fmt.Errorf("auth key %d: needs a secret or a publicKey", i)
+		}
+	}
+
+	if cfg.JWKSFile != "" {
+		data, err := os.ReadFile(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		set, err := jwt.ParseJWKS(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.JWKSFile, err)
+		}
+		keys = append(keys, set...)
+	}
+	return keys, nil
+}
@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"agentic/commerce/config"
+	"agentic/commerce/pkg/jwt"
+	"agentic/commerce/pkg/logger"
+)
+
+const testSecret = "auth-test-secret-at-least-32-bytes"
+
+func newTestAuthenticator(t *testing.T, mode config.ModeEnum, keys ...config.AuthKey) (*Authenticator, error) {
+	t.Helper()
+	return newConfiguredAuthenticator(t, mode, &config.AuthConfig{Keys: keys})
+}
+
+func newConfiguredAuthenticator(t *testing.T, mode config.ModeEnum, cfg *config.AuthConfig) (*Authenticator, error) {
+	t.Helper()
+	return NewAuthenticator(AuthenticatorParams{
+		Config: cfg,
+		Mode:   mode,
+		Logger: logger.NewAppLogger(&config.Config{Mode: mode, Logger: &config.Logger{Level: config.LevelWarn}}),
+	})
+}
+
+func userClaims(subject string) jwt.Claims {
+	now := time.Now()
+	return jwt.Claims{
+		Subject:   subject,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+	}
+}
+
+func TestAuthenticateTokenBindsTheTenant(t *testing.T) {
+	authenticator, err := newTestAuthenticator(t, config.ModeProd, config.AuthKey{Kid: "test", Secret: testSecret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	token, err := jwt.Sign(jwt.Claims{
+		Subject:   "7",
+		Tenant:    "a",
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+	}, "test", []byte(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	principal, err := authenticator.AuthenticateToken(context.Background(), token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal.Tenant != "a" || principal.UserID != 7 {
+		t.Fatalf("principal %+v", principal)
+	}
+}
+
+func TestNewAuthenticatorRefusesUnsafeSecretsInProd(t *testing.T) {
+	t.Setenv("AUTH_TEST_SECRET", "")
+	unsafe := map[string]config.AuthKey{
+		"sample":    {Kid: "dev", Secret: sampleSecret},
+		"unset env": {Kid: "main", SecretEnv: "AUTH_TEST_SECRET"},
+	}
+	for name, key := range unsafe {
+		if _, err := newTestAuthenticator(t, config.ModeProd, key); err == nil {
+			t.Errorf("%s: prod started", name)
+		}
+		if _, err := newTestAuthenticator(t, config.ModeDev, key); err != nil {
+			t.Errorf("%s: dev refused: %v", name, err)
+		}
+	}
+
+	t.Setenv("AUTH_TEST_SECRET", testSecret)
+	if _, err := newTestAuthenticator(t, config.ModeProd, config.AuthKey{Kid: "main", SecretEnv: "AUTH_TEST_SECRET"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuthenticateTokenLoadsPublicKeysAndJWKS(t *testing.T) {
+	dir := t.TempDir()
+	pemPublic, pemPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(pemPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemFile := filepath.Join(dir, "public.pem")
+	if err := os.WriteFile(pemFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	jwksPublic, jwksPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwksFile := filepath.Join(dir, "jwks.json")
+	set := fmt.Sprintf(`{"keys":[{"kty":"OKP","crv":"Ed25519","kid":"jwks","x":%q}]}`, base64.RawURLEncoding.EncodeToString(jwksPublic))
+	if err := os.WriteFile(jwksFile, []byte(set), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	authenticator, err := newConfiguredAuthenticator(t, config.ModeProd, &config.AuthConfig{
+		Keys:     []config.AuthKey{{Kid: "pem", PublicKey: pemFile}},
+		JWKSFile: jwksFile,
+		Issuer:   "issuer",
+		Audience: []string{"api"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := userClaims("7")
+	claims.Issuer, claims.Audience = "issuer", jwt.Audience{"api"}
+	for kid, private := range map[string]ed25519.PrivateKey{"pem": pemPrivate, "jwks": jwksPrivate} {
+		token, err := jwt.Sign(claims, kid, private)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := authenticator.AuthenticateToken(context.Background(), token); err != nil {
+			t.Errorf("the %s key doesn't verify: %v", kid, err)
+		}
+	}
+
+	claims.Audience = jwt.Audience{"web"}
+	token, err := jwt.Sign(claims, "pem", pemPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := authenticator.AuthenticateToken(context.Background(), token); !errors.Is(err, jwt.ErrAudience) {
+		t.Fatalf("expected ErrAudience, got %v", err)
+	}
+}
+
+func TestAuthenticateTokenRefusesWhatIsNotAUserToken(t *testing.T) {
+	authenticator, err := newTestAuthenticator(t, config.ModeProd, config.AuthKey{Kid: "test", Secret: testSecret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, subject := range []string{"", "abc", "0", "-1"} {
+		token, err := jwt.Sign(userClaims(subject), "test", []byte(testSecret))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := authenticator.AuthenticateToken(context.Background(), token); !errors.Is(err, ErrInvalidSubject) {
+			t.Errorf("subject %q: expected ErrInvalidSubject, got %v", subject, err)
+		}
+	}
+	if _, err := authenticator.AuthenticateToken(context.Background(), ""); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("expected ErrNoCredentials, got %v", err)
+	}
+
+	unconfigured, err := newTestAuthenticator(t, config.ModeProd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jwt.Sign(userClaims("7"), "test", []byte(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := unconfigured.AuthenticateToken(context.Background(), token); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("expected ErrNotConfigured, got %v", err)
+	}
+}
+
+func TestNewAuthenticatorRefusesAmbiguousKeys(t *testing.T) {
+	invalid := map[string]config.AuthKey{
+		"secret and public key": {Kid: "both", Secret: testSecret, PublicKey: "public.pem"},
+		"neither":               {Kid: "none"},
+		"missing pem file":      {Kid: "pem", PublicKey: filepath.Join(t.TempDir(), "missing.pem")},
+		"short secret":          {Kid: "short", Secret: "too short"},
+	}
+	for name, key := range invalid {
+		if _, err := newTestAuthenticator(t, config.ModeDev, key); err == nil {
+			t.Errorf("%s: the key was accepted", name)
+		}
+	}
+}
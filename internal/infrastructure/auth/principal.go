@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+type PrincipalKind string
+
+const (
+	// PrincipalUser is a person signed in with a bearer token.
+	PrincipalUser PrincipalKind = "user"
+	// PrincipalAPIKey is a machine client calling with an X-API-Key header.
+	PrincipalAPIKey PrincipalKind = "api-key"
+	// PrincipalSystem is the application itself, for the CLI and background jobs.
+	PrincipalSystem PrincipalKind = "system"
+)
+
+// RoleAdmin is the role of the System principal, granted every permission by default.
+const RoleAdmin = "admin"
+
+// Principal is who a request acts for, whatever credential it came with.
+type Principal struct {
+	Kind    PrincipalKind
+	Subject string
+	// UserID is the subject of a user token as a number, the id posts are owned by.
+	UserID int64
+	Roles  []string
+	Scopes []string
+	// Tenant is the tenant the credential is bound to: the tenant claim of a token, the
+	// tenant of an API key. Empty binds it to the default tenant.
+	Tenant string
+	// ExpiresAt is zero for credentials that never expire.
+	ExpiresAt time.Time
+}
+
+// System is the principal of the CLI commands, an administrator of every tenant.
+func System() *Principal {
+	return &Principal{Kind: PrincipalSystem, Subject: "system", Roles: []string{RoleAdmin}}
+}
+
+func (p *Principal) HasRole(role string) bool {
+	return lo.Contains(p.Roles, role)
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	return lo.Contains(p.Scopes, scope)
+}
+
+type principalContextKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFrom returns the principal the auth middleware verified, if any.
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
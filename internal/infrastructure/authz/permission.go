@@ -0,0 +1,57 @@
+package authz
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Scope is how far a permission reaches: the principal's own resources or anyone's.
+type Scope string
+
+const (
+	ScopeOwn Scope = "own"
+	ScopeAny Scope = "any"
+)
+
+// wildcard matches every resource or every action.
+const wildcard = "*"
+
+// Permission is written "resource:action:scope", such as metadata:delete:own. A grant
+// with scope any covers own too; resource and action may be "*".
+type Permission struct {
+	Resource string
+	Action   string
+	Scope    Scope
+}
+
+func ParsePermission(value string) (Permission, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+		return Permission{}, fmt.Errorf("permission %q: want resource:action:scope", value)
+	}
+	scope := Scope(parts[2])
+	if scope != ScopeOwn && scope != ScopeAny {
+		return Permission{}, fmt.Errorf("permission %q: scope is %s or %s", value, ScopeOwn, ScopeAny)
+	}
+	return Permission{Resource: parts[0], Action: parts[1], Scope: scope}, nil
+}
+
+// MustParsePermission is ParsePermission for the permissions routes are declared with.
+func MustParsePermission(value string) Permission {
+	permission, err := ParsePermission(value)
+	if err != nil {
+		panic(err)
+	}
+	return permission
+}
+
+func (p Permission) String() string {
+	return p.Resource + ":" + p.Action + ":" + string(p.Scope)
+}
+
+// Covers reports whether the grant p allows what wanted asks for.
+func (p Permission) Covers(wanted Permission) bool {
+	return (p.Resource == wildcard || p.Resource == wanted.Resource) &&
+		(p.Action == wildcard || p.Action == wanted.Action) &&
+		(p.Scope == ScopeAny || wanted.Scope == ScopeOwn)
+}
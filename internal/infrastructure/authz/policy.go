@@ -0,0 +1,220 @@
+// Package authz decides what a principal may do. Roles grant permissions, a principal
+// holds the roles of its token, the default roles of every user and the roles assigned
+// to its subject; an API key holds the permissions listed in its scopes.
+package authz
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/infrastructure/auth"
+	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/pkg/apperror"
+	"agentic/commerce/pkg/logger"
+
+	"github.com/samber/lo"
+	"go.uber.org/fx"
+)
+
+// RoleSource returns the roles assigned to a subject in the tenant of ctx, see the role
+// domain.
+type RoleSource interface {
+	AssignedRoles(ctx context.Context, subject string) ([]string, error)
+}
+
+type PolicyParams struct {
+	fx.In
+
+	Config *config.AuthConfig
+	Logger *logger.AppLogger
+	// Roles is provided by the role domain; without it only token and default roles count.
+	Roles RoleSource `optional:"true"`
+}
+
+// Policy evaluates permissions for the principal of a context. Services call it with the
+// owner of the resource at hand, routes only check the permission is held at all.
+type Policy struct {
+	roles        map[string][]Permission
+	defaultRoles []string
+	source       RoleSource
+	logger       *logger.AppLogger
+}
+
+func NewPolicy(params PolicyParams) (*Policy, error) {
+	definitions, defaultRoles := DefaultRoles, []string{RoleUser}
+	if params.Config != nil && len(params.Config.Roles) > 0 {
+		definitions, defaultRoles = params.Config.Roles, params.Config.DefaultRoles
+	}
+
+	roles := make(map[string][]Permission, len(definitions))
+	for name, values := range definitions {
+		for _, value := range values {
+			permission, err := ParsePermission(value)
+			if err != nil {
+				return nil, fmt.Errorf("role %q: %w", name, err)
+			}
+			roles[name] = append(roles[name], permission)
+		}
+	}
+	for _, name := range defaultRoles {
+		if _, ok := roles[name]; !ok {
+			return nil, fmt.Errorf("default role %q is not defined", name)
+		}
+	}
+
+	return &Policy{
+		roles:        roles,
+		defaultRoles: defaultRoles,
+		source:       params.Roles,
+		logger:       params.Logger.WithScope(Policy{}),
+	}, nil
+}
+
+// Roles returns the names of the defined roles, sorted.
+func (p *Policy) Roles() []string {
+	names := lo.Keys(p.roles)
+	sort.Strings(names)
+	return names
+}
+
+// Permissions returns the permissions of a role, false when it isn't defined.
+func (p *Policy) Permissions(role string) ([]Permission, bool) {
+	permissions, ok := p.roles[role]
+	return permissions, ok
+}
+
+// Require checks the principal of ctx holds the permission; ErrUnauthorized without a
+// principal, ErrForbidden otherwise.
+func (p *Policy) Require(ctx context.Context, wanted Permission) error {
+	_, grants, err := p.grants(ctx)
+	if err != nil {
+		return err
+	}
+	if !covered(grants, wanted) {
+		return apperror.ErrForbidden
+	}
+	return nil
+}
+
+// Authorize returns the widest scope the principal of ctx may act on resource with:
+// ScopeAny, or ScopeOwn for its own resources only.
+func (p *Policy) Authorize(ctx context.Context, resource, action string) (Scope, error) {
+	_, grants, err := p.grants(ctx)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case covered(grants, Permission{Resource: resource, Action: action, Scope: ScopeAny}):
+		return ScopeAny, nil
+	case covered(grants, Permission{Resource: resource, Action: action, Scope: ScopeOwn}):
+		return ScopeOwn, nil
+	default:
+		return "", apperror.ErrForbidden
+	}
+}
+
+// AuthorizeOwner checks the principal of ctx may act on a resource owned by the user
+// ownerID.
+func (p *Policy) AuthorizeOwner(ctx context.Context, resource, action string, ownerID int64) error {
+	principal, grants, err := p.grants(ctx)
+	if err != nil {
+		return err
+	}
+	wanted := Permission{Resource: resource, Action: action, Scope: ScopeAny}
+	if ownerID != 0 && ownerID == principal.UserID {
+		wanted.Scope = ScopeOwn
+	}
+	if !covered(grants, wanted) {
+		return apperror.ErrForbidden
+	}
+	return nil
+}
+
+// RequireAll checks the principal of ctx holds every permission, so it can't hand out
+// more than it has, through a role or the scopes of an API key.
+func (p *Policy) RequireAll(ctx context.Context, wanted []Permission) error {
+	_, grants, err := p.grants(ctx)
+	if err != nil {
+		return err
+	}
+	for _, permission := range wanted {
+		if !covered(grants, permission) {
+			return apperror.ErrForbidden
+		}
+	}
+	return nil
+}
+
+// RequireRole checks the principal of ctx holds the role, from its token, the default
+// roles or an assignment.
+func (p *Policy) RequireRole(ctx context.Context, role string) error {
+	principal, ok := auth.PrincipalFrom(ctx)
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+	roles, err := p.rolesOf(ctx, principal)
+	if err != nil {
+		return err
+	}
+	if !lo.Contains(roles, role) {
+		return apperror.ErrForbidden
+	}
+	return nil
+}
+
+func (p *Policy) rolesOf(ctx context.Context, principal *auth.Principal) ([]string, error) {
+	roles := append([]string{}, principal.Roles...)
+	if principal.Kind == auth.PrincipalUser {
+		roles = append(roles, p.defaultRoles...)
+	}
+	if p.source != nil && principal.Kind != auth.PrincipalSystem {
+		assigned, err := p.source.AssignedRoles(ctx, principal.Subject)
+		if err != nil {
+			p.logger.Error("cannot load the roles of {}", err, principal.Subject)
+			return nil, database.ResolveError(err)
+		}
+		roles = append(roles, assigned...)
+	}
+	return lo.Uniq(roles), nil
+}
+
+func (p *Policy) grants(ctx context.Context) (*auth.Principal, []Permission, error) {
+	principal, ok := auth.PrincipalFrom(ctx)
+	if !ok {
+		return nil, nil, apperror.ErrUnauthorized
+	}
+	roles, err := p.rolesOf(ctx, principal)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var grants []Permission
+	for _, role := range roles {
+		grants = append(grants, p.roles[role]...)
+	}
+	if principal.Kind == auth.PrincipalAPIKey {
+		grants = append(grants, ParsePermissions(principal.Scopes)...)
+	}
+	if principal.UserID == 0 {
+		// nothing is owned by a principal that isn't a user
+		grants = lo.Filter(grants, func(permission Permission, _ int) bool { return permission.Scope == ScopeAny })
+	}
+	return principal, grants, nil
+}
+
+func covered(grants []Permission, wanted Permission) bool {
+	return lo.ContainsBy(grants, func(grant Permission) bool { return grant.Covers(wanted) })
+}
+
+// ParsePermissions parses the scopes that are permissions and skips the others.
+func ParsePermissions(values []string) []Permission {
+	var permissions []Permission
+	for _, value := range values {
+		if permission, err := ParsePermission(value); err == nil {
+			permissions = append(permissions, permission)
+		}
+	}
+	return permissions
+}
@@ -0,0 +1,13 @@
+package authz
+
+import "agentic/commerce/internal/infrastructure/auth"
+
+// RoleUser is held by every signed-in user when no roles are configured.
+const RoleUser = "user"
+
+// DefaultRoles apply when the config defines no roles.
+var DefaultRoles = map[string][]string{
+	auth.RoleAdmin: {"*:*:any"},
+	"moderator":    {"metadata:read:any", "metadata:delete:any", "audit:read:any"},
+	RoleUser:       {"metadata:create:own", "metadata:read:own", "metadata:update:own", "metadata:delete:own"},
+}
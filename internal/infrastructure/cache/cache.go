@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-json-experiment/json/v1"
+)
+
+// Cache is a byte-oriented key/value store with per-entry expiry.
+type Cache interface {
+	// Get reports false when the key is missing or expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// GetJSON reads key and decodes it into a TValue.
+func GetJSON[TValue any](ctx context.Context, c Cache, key string) (*TValue, bool, error) {
+	raw, ok, err := c.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	var value TValue
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, false, err
+	}
+	return &value, true, nil
+}
+
+// SetJSON encodes value and stores it under key.
+func SetJSON[TValue any](ctx context.Context, c Cache, key string, value TValue, ttl time.Duration) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, key, raw, ttl)
+}
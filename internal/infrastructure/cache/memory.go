@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// memoryCache is an in-process LRU whose entries also expire after their TTL.
+type memoryCache struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+	onEvict  func()
+	now      func() time.Time
+}
+
+func NewMemoryCache(capacity int, onEvict func()) Cache {
+	if onEvict == nil {
+		onEvict = func() {}
+	}
+	return &memoryCache{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+		onEvict:  onEvict,
+		now:      time.Now,
+	}
+}
+
+func (c *memoryCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := el.Value.(*memoryEntry)
+	if c.expired(entry) {
+		c.remove(el)
+		c.onEvict()
+		return nil, false, nil
+	}
+	c.order.MoveToFront(el)
+	return entry.value, true, nil
+}
+
+func (c *memoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.now().Add(ttl)
+	}
+
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*memoryEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(el)
+		return nil
+	}
+
+	c.items[key] = c.order.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+	for c.capacity > 0 && c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+		c.onEvict()
+	}
+	return nil
+}
+
+func (c *memoryCache) Delete(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := c.items[key]; ok {
+			c.remove(el)
+		}
+	}
+	return nil
+}
+
+func (c *memoryCache) expired(entry *memoryEntry) bool {
+	return !entry.expiresAt.IsZero() && c.now().After(entry.expiresAt)
+}
+
+func (c *memoryCache) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*memoryEntry).key)
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+type Metrics struct {
+	Hits   prometheus.Counter
+	Misses prometheus.Counter
+}
+
+func NewMetrics(registerer prometheus.Registerer, backend string) (*Metrics, error) {
+	newCounter := func(name, help string) (prometheus.Counter, error) {
+		counter := prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   "cache",
+			Name:        name,
+			Help:        help,
+			ConstLabels: prometheus.Labels{"backend": backend},
+		})
+		return counter, registerer.Register(counter)
+	}
+
+	var m Metrics
+	var err error
+	if m.Hits, err = newCounter("hits_total", "Number of cache lookups served from the cache."); err != nil {
+		return nil, err
+	}
+	if m.Misses, err = newCounter("misses_total", "Number of cache lookups that fell through."); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+const evictionsHelp = "Number of entries dropped for capacity or expiry."
+
+// NewEvictionCounter counts the evictions of a backend that reports them one by one,
+// like the onEvict callback of NewMemoryCache.
+func NewEvictionCounter(registerer prometheus.Registerer, backend string) (prometheus.Counter, error) {
+	counter := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace:   "cache",
+		Name:        "evictions_total",
+		Help:        evictionsHelp,
+		ConstLabels: prometheus.Labels{"backend": backend},
+	})
+	return counter, registerer.Register(counter)
+}
+
+// redisEvictions reads the evicted_keys and expired_keys of INFO stats on every scrape.
+// They count the whole server since it started, keys outside the cache prefix included.
+type redisEvictions struct {
+	client redis.UniversalClient
+	desc   *prometheus.Desc
+}
+
+// NewRedisEvictionCollector exports the evictions of a Redis server as cache_evictions_total.
+func NewRedisEvictionCollector(client redis.UniversalClient) prometheus.Collector {
+	return &redisEvictions{
+		client: client,
+		desc: prometheus.NewDesc("cache_evictions_total", evictionsHelp, nil,
+			prometheus.Labels{"backend": "redis"}),
+	}
+}
+
+func (c *redisEvictions) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect leaves the sample out when the server can't be reached, the scrape goes on.
+func (c *redisEvictions) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	info, err := c.client.InfoMap(ctx, "stats").Result()
+	if err != nil {
+		return
+	}
+
+	var total float64
+	for _, key := range []string{"evicted_keys", "expired_keys"} {
+		if value, err := strconv.ParseFloat(info["Stats"][key], 64); err == nil {
+			total += value
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.CounterValue, total)
+}
+
+type instrumentedCache struct {
+	Cache
+	metrics *Metrics
+}
+
+// Instrument counts hits and misses of c.
+func Instrument(c Cache, metrics *Metrics) Cache {
+	return &instrumentedCache{
+		Cache:   c,
+		metrics: metrics,
+	}
+}
+
+func (c *instrumentedCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, ok, err := c.Cache.Get(ctx, key)
+	if err == nil {
+		if ok {
+			c.metrics.Hits.Inc()
+		} else {
+			c.metrics.Misses.Inc()
+		}
+	}
+	return value, ok, err
+}
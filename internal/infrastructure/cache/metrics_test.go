@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+// statsHook answers INFO with stats, miniredis reports no evictions.
+type statsHook map[string]string
+
+func (statsHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h statsHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if info, ok := cmd.(*redis.InfoCmd); ok {
+			info.SetVal(map[string]map[string]string{"Stats": h})
+			return nil
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (statsHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func gatherEvictions(t *testing.T, collector prometheus.Collector) []float64 {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values []float64
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			values = append(values, metric.GetCounter().GetValue())
+		}
+	}
+	return values
+}
+
+func TestRedisEvictionsReadInfoStats(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	client.AddHook(statsHook{"evicted_keys": "3", "expired_keys": "4", "keyspace_hits": "100"})
+
+	if values := gatherEvictions(t, NewRedisEvictionCollector(client)); len(values) != 1 || values[0] != 7 {
+		t.Fatalf("evictions %v, want [7]", values)
+	}
+}
+
+func TestRedisEvictionsSkipAnUnreachableServer(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() })
+	server.Close()
+
+	if values := gatherEvictions(t, NewRedisEvictionCollector(client)); len(values) != 0 {
+		t.Fatalf("evictions %v of an unreachable server", values)
+	}
+}
+
+func TestMemoryCacheCountsEvictions(t *testing.T) {
+	evictions, err := NewEvictionCounter(prometheus.NewRegistry(), "memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewMemoryCache(1, evictions.Inc)
+	ctx := context.Background()
+	for _, key := range []string{"a", "b", "c"} {
+		if err := c.Set(ctx, key, []byte(key), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if values := gatherEvictions(t, evictions); len(values) != 1 || values[0] != 2 {
+		t.Fatalf("evictions %v, want [2]", values)
+	}
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"time"
+
+	"agentic/commerce/config"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// NewGormConnection opens the pool of the configured driver and pings it until the
+// database answers, backing off exponentially between attempts and reporting them to
+// out. It gives up after DialRetry attempts or as soon as ctx is cancelled, e.g. by a
+// shutdown signal during startup.
+func NewGormConnection(ctx context.Context, cfg *config.DbConfig, out io.Writer) (*gorm.DB, error) {
+	name := cfg.Redacted()
+
+	dialector, err := NewDialector(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:               logger.Default.LogMode(logger.Info),
+		DisableAutomaticPing: true,
+		// violated unique keys come back as gorm.ErrDuplicatedKey whatever the driver
+		TranslateError: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot open database %s: %w", name, err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get sql database %s: %w", name, err)
+	}
+
+	configurePool(sqlDB, cfg)
+	if cfg.Dialect() == config.DbDriverSQLite && cfg.URL == "" && (cfg.Database == "" || cfg.Database == ":memory:") {
+		// the shared in-memory database is dropped when its last connection closes
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
+		sqlDB.SetMaxIdleConns(max(cfg.IdleConn, 1))
+	}
+
+	retryBackoff, maxRetryBackoff := cfg.RetryBackoff, cfg.MaxRetryBackoff
+	if retryBackoff <= 0 {
+		retryBackoff = 500 * time.Millisecond
+	}
+	if maxRetryBackoff <= 0 {
+		maxRetryBackoff = 30 * time.Second
+	}
+	attempts := max(cfg.DialRetry, 1)
+
+	for attempt := 1; ; attempt++ {
+		var version string
+		err = ping(ctx, sqlDB, cfg.Dialect(), cfg.DialTimeout, &version)
+		if err == nil {
+			fmt.Fprintf(out, "Connected to %s (%s %s)\n", name, cfg.Dialect(), version)
+			return db, nil
+		}
+		if attempt >= attempts {
+			break
+		}
+
+		delay := backoff(attempt, retryBackoff, maxRetryBackoff)
+		fmt.Fprintf(out, "Cannot connect to %s (attempt %d/%d), retrying in %s: %v\n", name, attempt, attempts, delay.Round(time.Millisecond), err)
+		if sleepErr := sleep(ctx, delay); sleepErr != nil {
+			err = sleepErr
+			break
+		}
+	}
+
+	_ = sqlDB.Close()
+	return nil, fmt.Errorf("cannot connect to %s: %w", name, err)
+}
+
+var versionQueries = map[config.DbDriver]string{
+	config.DbDriverPostgres: "SHOW server_version",
+	config.DbDriverMySQL:    "SELECT VERSION()",
+	config.DbDriverSQLite:   "SELECT sqlite_version()",
+}
+
+func ping(ctx context.Context, sqlDB *sql.DB, driver config.DbDriver, timeout time.Duration, version *string) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return sqlDB.QueryRowContext(ctx, versionQueries[driver]).Scan(version)
+}
+
+func configurePool(sqlDB *sql.DB, cfg *config.DbConfig) {
+	sqlDB.SetMaxOpenConns(cfg.MaxConn)
+	sqlDB.SetMaxIdleConns(cfg.IdleConn)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"fmt"
+	"os"
+
+	"agentic/commerce/config"
+
+	"github.com/glebarez/sqlite"
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// The enum serializer is registered once for every dialect, before any model is parsed.
+func init() {
+	schema.RegisterSerializer("enum", orsiniumEnumSerializer{})
+}
+
+// NewDialector returns the GORM dialector of the configured driver.
+func NewDialector(cfg *config.DbConfig) (gorm.Dialector, error) {
+	switch cfg.Dialect() {
+	case config.DbDriverPostgres:
+		return postgres.Open(cfg.PostgresDSN()), nil
+	case config.DbDriverMySQL:
+		if err := registerMySQLTLS(cfg); err != nil {
+			return nil, err
+		}
+		return mysql.Open(cfg.MySQLDSN()), nil
+	case config.DbDriverSQLite:
+		return sqlite.Open(cfg.SQLiteDSN()), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
+}
+
+// dialectorForConn wraps an already opened pool, as the read replicas need.
+func dialectorForConn(driver config.DbDriver, conn *sql.DB) (gorm.Dialector, error) {
+	switch driver {
+	case config.DbDriverPostgres:
+		return postgres.New(postgres.Config{Conn: conn}), nil
+	case config.DbDriverMySQL:
+		return mysql.New(mysql.Config{Conn: conn, SkipInitializeWithVersion: true}), nil
+	default:
+		return nil, fmt.Errorf("read replicas are not supported with %s", driver)
+	}
+}
+
+// registerMySQLTLS registers the root CA and client certificate with go-sql-driver
+// under config.MySQLTLSConfigName.
+func registerMySQLTLS(cfg *config.DbConfig) error {
+	if cfg.TLS == nil || (cfg.TLS.RootCert == "" && cfg.TLS.Cert == "") {
+		return nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.Host,
+		InsecureSkipVerify: cfg.TLS.Mode == "require",
+	}
+	if cfg.TLS.RootCert != "" {
+		pem, err := os.ReadFile(cfg.TLS.RootCert)
+		if err != nil {
+			return fmt.Errorf("cannot read database root certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificate found in %s", cfg.TLS.RootCert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.TLS.Cert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLS.Cert, cfg.TLS.Key)
+		if err != nil {
+			return fmt.Errorf("cannot load database client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return mysqlDriver.RegisterTLSConfig(config.MySQLTLSConfigName, tlsConfig)
+}
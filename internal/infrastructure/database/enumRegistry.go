@@ -0,0 +1,108 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/orsinium-labs/enum"
+)
+
+var ErrUnknownEnumValue = errors.New("unknown enum value")
+
+// EnumType is an enum registered with RegisterEnum. The enum serializer rejects values
+// outside Values, both read and written.
+type EnumType struct {
+	GoType reflect.Type
+	Values []interface{}
+	// PostgresType is the native ENUM type the columns have on Postgres; empty keeps
+	// them as plain text or integer columns.
+	PostgresType string
+}
+
+type EnumOption func(*EnumType)
+
+// WithPostgresType stores the enum in a native Postgres ENUM type of that name. The
+// type is created by a migration; `schema diff` reports it when missing or when its
+// labels differ from the enum. Only string enums can use it.
+func WithPostgresType(name string) EnumOption {
+	return func(t *EnumType) {
+		t.PostgresType = name
+	}
+}
+
+var enumTypes sync.Map // reflect.Type -> *EnumType
+
+// RegisterEnum makes the members of e known to the enum serializer and returns e, so it
+// can wrap the declaration:
+//
+//	Actions = database.RegisterEnum(enum.New(ActionCreate, ActionUpdate, ActionDelete))
+func RegisterEnum[M interface{ ~struct{ Value V } }, V comparable](e enum.Enum[M, V], opts ...EnumOption) enum.Enum[M, V] {
+	t := &EnumType{GoType: reflect.TypeOf(*new(M))}
+	for _, value := range e.Values() {
+		t.Values = append(t.Values, value)
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	if t.PostgresType != "" && reflect.TypeOf(*new(V)).Kind() != reflect.String {
+		panic(fmt.Sprintf("enum %s: only string enums can have a Postgres type", t.GoType))
+	}
+	if _, loaded := enumTypes.LoadOrStore(t.GoType, t); loaded {
+		panic(fmt.Sprintf("enum %s is registered twice", t.GoType))
+	}
+	return e
+}
+
+// LookupEnum returns the registered enum of a member type.
+func LookupEnum(goType reflect.Type) (*EnumType, bool) {
+	for goType.Kind() == reflect.Ptr {
+		goType = goType.Elem()
+	}
+	t, ok := enumTypes.Load(goType)
+	if !ok {
+		return nil, false
+	}
+	return t.(*EnumType), true
+}
+
+// Enums lists every registered enum ordered by Go type name.
+func Enums() []*EnumType {
+	var types []*EnumType
+	enumTypes.Range(func(_, value interface{}) bool {
+		types = append(types, value.(*EnumType))
+		return true
+	})
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].GoType.String() < types[j].GoType.String()
+	})
+	return types
+}
+
+func (t *EnumType) Contains(value interface{}) bool {
+	for _, v := range t.Values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Labels are the values as strings, in declaration order.
+func (t *EnumType) Labels() []string {
+	labels := make([]string, len(t.Values))
+	for i, v := range t.Values {
+		labels[i] = fmt.Sprint(v)
+	}
+	return labels
+}
+
+func (t *EnumType) check(value interface{}) error {
+	if t.Contains(value) {
+		return nil
+	}
+	return fmt.Errorf("%w %v for %s, allowed values are %v", ErrUnknownEnumValue, value, t.GoType, t.Labels())
+}
@@ -4,8 +4,8 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strconv"
 
-	"github.com/orsinium-labs/enum"
 	"gorm.io/gorm/schema"
 )
 
@@ -18,22 +18,7 @@ func (s orsiniumEnumSerializer) Scan(
 	dest reflect.Value,
 	dbValue interface{},
 ) error {
-	// 1) raw string handling (same as before)
-	var str *string
-	switch v := dbValue.(type) {
-	case string:
-		str = &v
-	case []byte:
-		sv := string(v)
-		str = &sv
-	case nil:
-		// SQL NULL -> keep str nil
-		str = nil
-	default:
-		return fmt.Errorf("Scan: expected string/[]byte, got %T", dbValue)
-	}
-
-	// 2) dest must be valid
+	// 1) dest must be valid
 	if !dest.IsValid() {
 		return fmt.Errorf("Scan: destination reflect.Value is invalid")
 	}
@@ -87,8 +72,14 @@ func (s orsiniumEnumSerializer) Scan(
 		return fmt.Errorf("Scan: field %q is not settable", field.Name)
 	}
 
-	// 3) Build a new instance of the field's exact type
+	// 2) NULL leaves a pointer field nil and a member field zero
 	typ := fv.Type()
+	if dbValue == nil {
+		fv.Set(reflect.Zero(typ))
+		return nil
+	}
+
+	// 3) Build a new instance of the field's exact type
 	var newInst reflect.Value
 	if typ.Kind() == reflect.Ptr {
 		newInst = reflect.New(typ.Elem())
@@ -96,13 +87,18 @@ func (s orsiniumEnumSerializer) Scan(
 		newInst = reflect.New(typ)
 	}
 
-	// 4) set its .Value string field
+	// 4) set its .Value field, string or integer, from the raw column value
 	valueField := newInst.Elem().FieldByName("Value")
-	if !valueField.IsValid() || valueField.Kind() != reflect.String {
-		return fmt.Errorf("Scan: %s has no string field `Value`", typ)
+	if !valueField.IsValid() {
+		return fmt.Errorf("Scan: %s has no field `Value`", typ)
 	}
-	if str != nil {
-		valueField.SetString(*str)
+	if err := setEnumValue(valueField, dbValue); err != nil {
+		return fmt.Errorf("Scan: field %q: %w", field.Name, err)
+	}
+	if enumType, ok := LookupEnum(typ); ok {
+		if err := enumType.check(valueField.Interface()); err != nil {
+			return fmt.Errorf("Scan: field %q: %w", field.Name, err)
+		}
 	}
 
 	// 5) assign back into target field
@@ -144,20 +140,83 @@ func (s orsiniumEnumSerializer) Value(
 		rv = rv.Elem()
 	}
 
-	// convert alias → enum.Member[string] if needed
-	targetType := reflect.TypeOf(enum.Member[string]{})
-	if rv.Type() != targetType {
-		if rv.Type().ConvertibleTo(targetType) {
-			rv = rv.Convert(targetType)
-		} else {
-			return nil, fmt.Errorf("Value: cannot convert %s to %s", rv.Type(), targetType)
+	// the member's Value field, whatever alias of enum.Member it is
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Value: expected an enum.Member, got %s", rv.Type())
+	}
+	valueField := rv.FieldByName("Value")
+	if !valueField.IsValid() {
+		return nil, fmt.Errorf("Value: %s has no field `Value`", rv.Type())
+	}
+	if enumType, ok := LookupEnum(rv.Type()); ok && !valueField.IsZero() {
+		if err := enumType.check(valueField.Interface()); err != nil {
+			return nil, fmt.Errorf("Value: field %q: %w", field.Name, err)
+		}
+	}
+
+	switch valueField.Kind() {
+	case reflect.String:
+		return valueField.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return valueField.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(valueField.Uint()), nil
+	default:
+		return nil, fmt.Errorf("Value: unsupported enum value kind %s of %s", valueField.Kind(), rv.Type())
+	}
+}
+
+// setEnumValue converts what the driver returned into the kind of the member's Value.
+func setEnumValue(valueField reflect.Value, dbValue interface{}) error {
+	raw := dbValue
+	if b, ok := raw.([]byte); ok {
+		raw = string(b)
+	}
+
+	switch valueField.Kind() {
+	case reflect.String:
+		str, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("expected string/[]byte, got %T", dbValue)
+		}
+		valueField.SetString(str)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := toInt64(raw)
+		if err != nil {
+			return err
+		}
+		if valueField.OverflowInt(n) {
+			return fmt.Errorf("%d overflows %s", n, valueField.Type())
 		}
+		valueField.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := toInt64(raw)
+		if err != nil {
+			return err
+		}
+		if n < 0 || valueField.OverflowUint(uint64(n)) {
+			return fmt.Errorf("%d overflows %s", n, valueField.Type())
+		}
+		valueField.SetUint(uint64(n))
+	default:
+		return fmt.Errorf("unsupported enum value kind %s", valueField.Kind())
 	}
+	return nil
+}
 
-	// now safe to type assert
-	member, ok := rv.Interface().(enum.Member[string])
-	if !ok {
-		return nil, fmt.Errorf("expected enum.Member[string], got %T", rv.Interface())
+func toInt64(raw interface{}) (int64, error) {
+	switch v := raw.(type) {
+	case int64:
+		return v, nil
+	case int32:
+		return int64(v), nil
+	case int:
+		return int64(v), nil
+	case uint64:
+		return int64(v), nil
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	default:
+		return 0, fmt.Errorf("expected an integer, got %T", raw)
 	}
-	return member.Value, nil
 }
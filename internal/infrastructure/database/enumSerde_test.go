@@ -0,0 +1,137 @@
+package database
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/orsinium-labs/enum"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type color enum.Member[string]
+
+type priority enum.Member[int]
+
+var (
+	colorRed   = color{"red"}
+	colorGreen = color{"green"}
+
+	priorityLow  = priority{1}
+	priorityHigh = priority{3}
+
+	_ = RegisterEnum(enum.New(colorRed, colorGreen))
+	_ = RegisterEnum(enum.New(priorityLow, priorityHigh))
+)
+
+type ticket struct {
+	ID       uint
+	Color    color     `gorm:"serializer:enum"`
+	Priority priority  `gorm:"serializer:enum"`
+	Escalate *priority `gorm:"serializer:enum"`
+}
+
+func newSQLiteTickets(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	if err := db.Exec("CREATE TABLE tickets (id integer PRIMARY KEY, color text, priority integer, escalate integer)").Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestEnumSerializerRoundTrips(t *testing.T) {
+	db := newSQLiteTickets(t)
+	escalate := priorityHigh
+	if err := db.Create(&ticket{ID: 1, Color: colorGreen, Priority: priorityHigh, Escalate: &escalate}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var stored struct {
+		Color    string
+		Priority int64
+	}
+	if err := db.Raw("SELECT color, priority FROM tickets WHERE id = 1").Scan(&stored).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.Color != "green" || stored.Priority != 3 {
+		t.Fatalf("stored %+v, want the raw values green and 3", stored)
+	}
+
+	var got ticket
+	if err := db.First(&got, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.Color != colorGreen || got.Priority != priorityHigh || got.Escalate == nil || *got.Escalate != priorityHigh {
+		t.Fatalf("read back %+v", got)
+	}
+}
+
+func TestEnumSerializerKeepsNull(t *testing.T) {
+	db := newSQLiteTickets(t)
+	if err := db.Create(&ticket{ID: 1, Color: colorRed, Priority: priorityLow}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var nulls int64
+	if err := db.Raw("SELECT count(*) FROM tickets WHERE escalate IS NULL").Scan(&nulls).Error; err != nil || nulls != 1 {
+		t.Fatalf("%d NULL escalations stored: %v", nulls, err)
+	}
+	previous := priorityHigh
+	got := ticket{Escalate: &previous}
+	if err := db.First(&got, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.Escalate != nil {
+		t.Fatalf("NULL read back as %v", *got.Escalate)
+	}
+}
+
+func TestEnumSerializerRejectsUnknownValues(t *testing.T) {
+	db := newSQLiteTickets(t)
+
+	tests := []struct {
+		name   string
+		ticket ticket
+	}{
+		{"string", ticket{ID: 1, Color: color{"blue"}, Priority: priorityLow}},
+		{"integer", ticket{ID: 2, Color: colorRed, Priority: priority{2}}},
+	}
+	for _, tt := range tests {
+		t.Run("value "+tt.name, func(t *testing.T) {
+			if err := db.Create(&tt.ticket).Error; !errors.Is(err, ErrUnknownEnumValue) {
+				t.Fatalf("wrote an unknown value: %v", err)
+			}
+		})
+	}
+
+	rows := []struct {
+		name   string
+		insert string
+	}{
+		{"string", "INSERT INTO tickets (id, color, priority) VALUES (3, 'blue', 1)"},
+		{"integer", "INSERT INTO tickets (id, color, priority) VALUES (4, 'red', 2)"},
+		{"pointer", "INSERT INTO tickets (id, color, priority, escalate) VALUES (5, 'red', 1, 7)"},
+	}
+	for _, tt := range rows {
+		t.Run("scan "+tt.name, func(t *testing.T) {
+			if err := db.Exec(tt.insert).Error; err != nil {
+				t.Fatal(err)
+			}
+			var got ticket
+			if err := db.Order("id DESC").First(&got).Error; !errors.Is(err, ErrUnknownEnumValue) {
+				t.Fatalf("read an unknown value as %+v: %v", got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package database
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	// (?, ?, ?) -> (?+), IN lists and VALUES rows of any length share a fingerprint
+	placeholderList = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)+\s*\)`)
+	// (?+), (?+) -> (?+), multi-row inserts of any size share a fingerprint
+	repeatedRows = regexp.MustCompile(`\(\?\+\)(?:\s*,\s*\(\?\+\))+`)
+	booleans     = regexp.MustCompile(`(?i)\b(?:true|false)\b`)
+	// NULL is an inlined nil argument unless it follows IS or IS NOT
+	nulls = regexp.MustCompile(`(?i)(?:\bIS\s+(?:NOT\s+)?)?\bNULL\b`)
+)
+
+// Fingerprint normalizes a SQL statement so executions that only differ in their
+// literals, placeholders or list lengths are grouped together: string, number, boolean
+// and NULL literals and $n/? placeholders become ?, comments are dropped and
+// whitespace is collapsed. Quoted identifiers are kept; doubleQuotedStrings treats
+// "..." as a string literal instead, as the SQLite dialect inlines arguments that way.
+func Fingerprint(sql string, doubleQuotedStrings bool) string {
+	var b strings.Builder
+	b.Grow(len(sql))
+
+	runes := []rune(sql)
+	space := false
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			space = true
+			continue
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			space = true
+			continue
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			i += 2
+			for i+1 < len(runes) && !(runes[i] == '*' && runes[i+1] == '/') {
+				i++
+			}
+			i++
+			space = true
+			continue
+		}
+
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+
+		switch {
+		case r == '\'' || (r == '"' && doubleQuotedStrings):
+			// a doubled quote is an escaped quote inside the literal
+			for i++; i < len(runes); i++ {
+				if runes[i] == r {
+					if i+1 < len(runes) && runes[i+1] == r {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			b.WriteByte('?')
+		case r == '"' || r == '`':
+			start := i
+			for i++; i < len(runes) && runes[i] != r; i++ {
+			}
+			b.WriteString(string(runes[start:min(i+1, len(runes))]))
+		case r == '$' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			for i+1 < len(runes) && unicode.IsDigit(runes[i+1]) {
+				i++
+			}
+			b.WriteByte('?')
+		case unicode.IsDigit(r) && !isIdentifierEnd(runes, i):
+			for i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.' ||
+				runes[i+1] == 'e' || runes[i+1] == 'E') {
+				i++
+			}
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	fingerprint := booleans.ReplaceAllString(b.String(), "?")
+	fingerprint = nulls.ReplaceAllStringFunc(fingerprint, func(match string) string {
+		if len(match) > len("NULL") {
+			return match
+		}
+		return "?"
+	})
+	fingerprint = placeholderList.ReplaceAllString(fingerprint, "(?+)")
+	return repeatedRows.ReplaceAllString(fingerprint, "(?+)")
+}
+
+// isIdentifierEnd reports whether the digit at i continues an identifier, like the 2 of
+// idx_t2.
+func isIdentifierEnd(runes []rune, i int) bool {
+	if i == 0 {
+		return false
+	}
+	prev := runes[i-1]
+	return unicode.IsLetter(prev) || unicode.IsDigit(prev) || prev == '_'
+}
@@ -0,0 +1,50 @@
+package database
+
+import "testing"
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		name                string
+		sql                 string
+		doubleQuotedStrings bool
+		want                string
+	}{
+		{"literals", "SELECT * FROM users WHERE name = 'bob' AND age > 42 AND score = 1.5e3",
+			false, "SELECT * FROM users WHERE name = ? AND age > ? AND score = ?"},
+		{"escaped quote", "SELECT * FROM users WHERE name = 'o''brien' AND city = 'x'",
+			false, "SELECT * FROM users WHERE name = ? AND city = ?"},
+		{"line comment", "SELECT id -- the key\nFROM users",
+			false, "SELECT id FROM users"},
+		{"block comment", "SELECT /* hint */ id FROM   users\n\tWHERE id = 1",
+			false, "SELECT id FROM users WHERE id = ?"},
+		{"numbered placeholders", "SELECT * FROM users WHERE id = $1 AND tenant_id = $12",
+			false, "SELECT * FROM users WHERE id = ? AND tenant_id = ?"},
+		{"in list", "SELECT * FROM users WHERE id IN (1, 2, 3)",
+			false, "SELECT * FROM users WHERE id IN (?+)"},
+		{"in list of any length", "SELECT * FROM users WHERE id IN ($1,$2)",
+			false, "SELECT * FROM users WHERE id IN (?+)"},
+		{"multi-row insert", "INSERT INTO users (name, age) VALUES ('a', 1), ('b', 2), ('c', 3)",
+			false, "INSERT INTO users (name, age) VALUES (?+)"},
+		{"single-row insert", "INSERT INTO users (name, age) VALUES (?, ?)",
+			false, "INSERT INTO users (name, age) VALUES (?+)"},
+		{"identifier ending in a digit", "SELECT t2.id FROM users AS t2 JOIN idx_3 ON x1 = 7",
+			false, "SELECT t2.id FROM users AS t2 JOIN idx_3 ON x1 = ?"},
+		{"negative literal", "UPDATE accounts SET balance = -250 WHERE id = 9",
+			false, "UPDATE accounts SET balance = -? WHERE id = ?"},
+		{"booleans and null", "UPDATE users SET active = TRUE, note = NULL WHERE deleted_at IS NULL AND locked IS NOT NULL",
+			false, "UPDATE users SET active = ?, note = ? WHERE deleted_at IS NULL AND locked IS NOT NULL"},
+		{"quoted identifiers", "SELECT \"name\" FROM `users` WHERE \"id2\" = 5",
+			false, "SELECT \"name\" FROM `users` WHERE \"id2\" = ?"},
+		{"double-quoted strings", "SELECT `name` FROM `users` WHERE `name` = \"bob\" AND `bio` = \"say \"\"hi\"\"\"",
+			true, "SELECT `name` FROM `users` WHERE `name` = ? AND `bio` = ?"},
+		{"unterminated literal", "SELECT 'abc",
+			false, "SELECT ?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Fingerprint(tt.sql, tt.doubleQuotedStrings); got != tt.want {
+				t.Fatalf("Fingerprint(%q)\n got %q\nwant %q", tt.sql, got, tt.want)
+			}
+		})
+	}
+}
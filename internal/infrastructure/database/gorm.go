@@ -5,16 +5,27 @@ import (
 	"fmt"
 
 	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
 )
 
 type GormDB func(context.Context) *gorm.DB
 
+// CreateGormDB returns the ambient transaction of ctx when there is one, so repositories
+// join it transparently. Outside a transaction reads go to a replica unless WithPrimary was used.
 func CreateGormDB(db *gorm.DB) GormDB {
 	return func(ctx context.Context) *gorm.DB {
+		if tx, ok := TxFromContext(ctx); ok {
+			return tx.WithContext(ctx)
+		}
+
 		if db == nil {
 			return nil
 		}
 
+		if isPrimaryForced(ctx) {
+			return db.WithContext(ctx).Clauses(dbresolver.Write)
+		}
+
 		return db.WithContext(ctx)
 	}
 }
@@ -0,0 +1,264 @@
+package database
+
+import (
+	"hash/maphash"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"agentic/commerce/config"
+)
+
+const (
+	DefaultSlowQueryThreshold = 200 * time.Millisecond
+	DefaultQueryStatsWindow   = 15 * time.Minute
+	DefaultMaxFingerprints    = 500
+	DefaultMaxSamples         = 256
+	queryStatsBuckets         = 60
+	// queryStatsShards stripes the fingerprints so concurrent queries rarely wait on
+	// each other; each shard keeps its share of MaxFingerprints
+	queryStatsShards = 16
+)
+
+const (
+	QueryStatsSortTotal = "total"
+	QueryStatsSortP95   = "p95"
+	QueryStatsSortMax   = "max"
+	QueryStatsSortCount = "count"
+)
+
+// QueryStat is the rolling summary of one fingerprint over the stats window.
+type QueryStat struct {
+	Fingerprint string
+	Count       int64
+	SlowCount   int64
+	Rows        int64
+	Total       time.Duration
+	P50         time.Duration
+	P95         time.Duration
+	Max         time.Duration
+	LastSeen    time.Time
+}
+
+// QueryStats keeps per-fingerprint statistics of every query over a rolling window.
+// Counts, rows, total and max come from time-sliced buckets and are exact; p50 and p95
+// are computed from the latest samples of the window.
+type QueryStats struct {
+	slowThreshold time.Duration
+	window        time.Duration
+	bucketWidth   time.Duration
+	// maxShardFingerprints is the share of MaxFingerprints of a shard
+	maxShardFingerprints int
+	maxSamples           int
+	// doubleQuotedStrings is set on SQLite, where logged statements quote inlined
+	// strings with " and identifiers with backticks
+	doubleQuotedStrings bool
+	seed                maphash.Seed
+	shards              [queryStatsShards]queryStatsShard
+	now                 func() time.Time
+}
+
+// queryStatsShard holds the fingerprints hashed to it; its lock is only held to update
+// one of them, fingerprinting happens before.
+type queryStatsShard struct {
+	mu           sync.Mutex
+	fingerprints map[string]*fingerprintStats
+}
+
+type fingerprintStats struct {
+	buckets  [queryStatsBuckets]queryBucket
+	samples  []querySample
+	next     int
+	lastSeen time.Time
+}
+
+type queryBucket struct {
+	epoch int64
+	count int64
+	slow  int64
+	rows  int64
+	total time.Duration
+	max   time.Duration
+}
+
+type querySample struct {
+	at      time.Time
+	elapsed time.Duration
+}
+
+func NewQueryStats(cfg *config.DbConfig) *QueryStats {
+	stats := cfg.QueryStats
+	s := &QueryStats{
+		slowThreshold:        stats.SlowThreshold,
+		window:               stats.Window,
+		maxShardFingerprints: stats.MaxFingerprints,
+		maxSamples:           stats.MaxSamples,
+		doubleQuotedStrings:  cfg.Dialect() == config.DbDriverSQLite,
+		seed:                 maphash.MakeSeed(),
+		now:                  time.Now,
+	}
+	for i := range s.shards {
+		s.shards[i].fingerprints = make(map[string]*fingerprintStats)
+	}
+	if s.slowThreshold <= 0 {
+		s.slowThreshold = DefaultSlowQueryThreshold
+	}
+	if s.window <= 0 {
+		s.window = DefaultQueryStatsWindow
+	}
+	if s.maxShardFingerprints <= 0 {
+		s.maxShardFingerprints = DefaultMaxFingerprints
+	}
+	// rounded up, a shard keeps at least one fingerprint
+	s.maxShardFingerprints = (s.maxShardFingerprints + queryStatsShards - 1) / queryStatsShards
+	if s.maxSamples <= 0 {
+		s.maxSamples = DefaultMaxSamples
+	}
+	s.bucketWidth = s.window / queryStatsBuckets
+	if s.bucketWidth <= 0 {
+		s.bucketWidth = time.Millisecond
+	}
+	return s
+}
+
+func (s *QueryStats) SlowThreshold() time.Duration {
+	return s.slowThreshold
+}
+
+func (s *QueryStats) Window() time.Duration {
+	return s.window
+}
+
+// Record adds one execution of sql and returns its fingerprint.
+func (s *QueryStats) Record(sql string, elapsed time.Duration, rows int64) string {
+	fingerprint := Fingerprint(sql, s.doubleQuotedStrings)
+	now := s.now()
+	epoch := now.UnixNano() / int64(s.bucketWidth)
+
+	shard := &s.shards[maphash.String(s.seed, fingerprint)%queryStatsShards]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	stats, ok := shard.fingerprints[fingerprint]
+	if !ok {
+		if len(shard.fingerprints) >= s.maxShardFingerprints {
+			shard.evictOldest()
+		}
+		stats = &fingerprintStats{samples: make([]querySample, 0, s.maxSamples)}
+		shard.fingerprints[fingerprint] = stats
+	}
+	stats.lastSeen = now
+
+	bucket := &stats.buckets[epoch%queryStatsBuckets]
+	if bucket.epoch != epoch {
+		*bucket = queryBucket{epoch: epoch}
+	}
+	bucket.count++
+	bucket.total += elapsed
+	if rows > 0 {
+		bucket.rows += rows
+	}
+	if elapsed > bucket.max {
+		bucket.max = elapsed
+	}
+	if elapsed >= s.slowThreshold {
+		bucket.slow++
+	}
+
+	sample := querySample{at: now, elapsed: elapsed}
+	if len(stats.samples) < s.maxSamples {
+		stats.samples = append(stats.samples, sample)
+	} else {
+		stats.samples[stats.next] = sample
+		stats.next = (stats.next + 1) % s.maxSamples
+	}
+	return fingerprint
+}
+
+// Top returns up to limit fingerprints of the window ordered by sortBy (total, p95, max
+// or count), only those with a slow execution unless includeFast is set.
+func (s *QueryStats) Top(limit int, sortBy string, includeFast bool) []QueryStat {
+	now := s.now()
+	oldest := now.UnixNano()/int64(s.bucketWidth) - queryStatsBuckets + 1
+
+	var summaries []QueryStat
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mu.Lock()
+		for fingerprint, stats := range shard.fingerprints {
+			summary := stats.summarize(oldest, now.Add(-s.window))
+			if summary.Count == 0 || (!includeFast && summary.SlowCount == 0) {
+				continue
+			}
+			summary.Fingerprint = fingerprint
+			summaries = append(summaries, summary)
+		}
+		shard.mu.Unlock()
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		a, b := summaries[i], summaries[j]
+		switch sortBy {
+		case QueryStatsSortP95:
+			return a.P95 > b.P95
+		case QueryStatsSortMax:
+			return a.Max > b.Max
+		case QueryStatsSortCount:
+			return a.Count > b.Count
+		default:
+			return a.Total > b.Total
+		}
+	})
+	if limit > 0 && len(summaries) > limit {
+		summaries = summaries[:limit]
+	}
+	return summaries
+}
+
+func (f *fingerprintStats) summarize(oldestEpoch int64, since time.Time) QueryStat {
+	summary := QueryStat{LastSeen: f.lastSeen}
+	for _, bucket := range f.buckets {
+		if bucket.epoch < oldestEpoch {
+			continue
+		}
+		summary.Count += bucket.count
+		summary.SlowCount += bucket.slow
+		summary.Rows += bucket.rows
+		summary.Total += bucket.total
+		if bucket.max > summary.Max {
+			summary.Max = bucket.max
+		}
+	}
+
+	durations := make([]time.Duration, 0, len(f.samples))
+	for _, sample := range f.samples {
+		if !sample.at.Before(since) {
+			durations = append(durations, sample.elapsed)
+		}
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	summary.P50 = percentile(durations, 0.50)
+	summary.P95 = percentile(durations, 0.95)
+	return summary
+}
+
+// percentile uses the nearest-rank method on sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)]
+}
+
+func (s *queryStatsShard) evictOldest() {
+	var oldest string
+	var oldestSeen time.Time
+	for fingerprint, stats := range s.fingerprints {
+		if oldest == "" || stats.lastSeen.Before(oldestSeen) {
+			oldest, oldestSeen = fingerprint, stats.lastSeen
+		}
+	}
+	delete(s.fingerprints, oldest)
+}
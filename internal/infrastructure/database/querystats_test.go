@@ -0,0 +1,153 @@
+package database
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"agentic/commerce/config"
+)
+
+// newTestQueryStats has one-second buckets over a minute and a clock the test moves.
+func newTestQueryStats(stats config.DbQueryStatsConfig) (*QueryStats, *time.Time) {
+	if stats.Window == 0 {
+		stats.Window = time.Minute
+	}
+	stats.SlowThreshold = 100 * time.Millisecond
+	s := NewQueryStats(&config.DbConfig{QueryStats: stats})
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+func statOf(t *testing.T, s *QueryStats, fingerprint string) (QueryStat, bool) {
+	t.Helper()
+	for _, stat := range s.Top(0, QueryStatsSortTotal, true) {
+		if stat.Fingerprint == fingerprint {
+			return stat, true
+		}
+	}
+	return QueryStat{}, false
+}
+
+func TestQueryStatsExpireOutsideTheWindow(t *testing.T) {
+	s, now := newTestQueryStats(config.DbQueryStatsConfig{})
+
+	fingerprint := s.Record("SELECT * FROM users WHERE id = 1", 300*time.Millisecond, 1)
+	*now = now.Add(30 * time.Second)
+	s.Record("SELECT * FROM users WHERE id = 2", 10*time.Millisecond, 1)
+
+	stat, ok := statOf(t, s, fingerprint)
+	if !ok || stat.Count != 2 || stat.SlowCount != 1 || stat.Rows != 2 || stat.Max != 300*time.Millisecond {
+		t.Fatalf("within the window: %+v", stat)
+	}
+
+	// the slow execution leaves the window, the fast one stays
+	*now = now.Add(45 * time.Second)
+	stat, ok = statOf(t, s, fingerprint)
+	if !ok || stat.Count != 1 || stat.SlowCount != 0 || stat.Max != 10*time.Millisecond || stat.P95 != 10*time.Millisecond {
+		t.Fatalf("after the slow execution expired: %+v", stat)
+	}
+	if top := s.Top(0, QueryStatsSortTotal, false); len(top) != 0 {
+		t.Fatalf("a fingerprint without slow executions is listed: %+v", top)
+	}
+
+	*now = now.Add(time.Minute)
+	if _, ok := statOf(t, s, fingerprint); ok {
+		t.Fatal("a fingerprint outside the window is listed")
+	}
+}
+
+func TestQueryStatsPercentiles(t *testing.T) {
+	s, _ := newTestQueryStats(config.DbQueryStatsConfig{})
+	var fingerprint string
+	for ms := 100; ms >= 1; ms-- {
+		fingerprint = s.Record("SELECT 1", time.Duration(ms)*time.Millisecond, 0)
+	}
+
+	stat, _ := statOf(t, s, fingerprint)
+	if stat.Count != 100 || stat.SlowCount != 1 || stat.Total != 5050*time.Millisecond {
+		t.Fatalf("summed %+v", stat)
+	}
+	if stat.P50 != 50*time.Millisecond || stat.P95 != 95*time.Millisecond || stat.Max != 100*time.Millisecond {
+		t.Fatalf("p50 %s, p95 %s, max %s", stat.P50, stat.P95, stat.Max)
+	}
+}
+
+func TestQueryStatsPercentilesUseTheLatestSamples(t *testing.T) {
+	s, _ := newTestQueryStats(config.DbQueryStatsConfig{MaxSamples: 10})
+	var fingerprint string
+	for ms := 1; ms <= 100; ms++ {
+		fingerprint = s.Record("SELECT 1", time.Duration(ms)*time.Millisecond, 0)
+	}
+
+	stat, _ := statOf(t, s, fingerprint)
+	// counts stay exact, percentiles come from 91ms to 100ms
+	if stat.Count != 100 || stat.P50 != 95*time.Millisecond || stat.P95 != 100*time.Millisecond {
+		t.Fatalf("count %d, p50 %s, p95 %s", stat.Count, stat.P50, stat.P95)
+	}
+}
+
+func TestQueryStatsTopSortsAndLimits(t *testing.T) {
+	s, _ := newTestQueryStats(config.DbQueryStatsConfig{})
+	s.Record("SELECT * FROM a", 500*time.Millisecond, 0)
+	for range 3 {
+		s.Record("SELECT * FROM b", 200*time.Millisecond, 0)
+	}
+
+	tests := []struct {
+		sortBy string
+		want   string
+	}{
+		{QueryStatsSortTotal, "SELECT * FROM b"},
+		{QueryStatsSortCount, "SELECT * FROM b"},
+		{QueryStatsSortMax, "SELECT * FROM a"},
+		{QueryStatsSortP95, "SELECT * FROM a"},
+	}
+	for _, tt := range tests {
+		top := s.Top(1, tt.sortBy, false)
+		if len(top) != 1 || top[0].Fingerprint != tt.want {
+			t.Fatalf("top by %s is %+v, want %s", tt.sortBy, top, tt.want)
+		}
+	}
+}
+
+func TestQueryStatsEvictTheLeastRecentlySeen(t *testing.T) {
+	s, now := newTestQueryStats(config.DbQueryStatsConfig{MaxFingerprints: queryStatsShards})
+	var latest string
+	for i := range 200 {
+		*now = now.Add(time.Millisecond)
+		latest = s.Record(fmt.Sprintf("SELECT * FROM t%d", i), time.Millisecond, 0)
+	}
+
+	if top := s.Top(0, QueryStatsSortTotal, true); len(top) > queryStatsShards {
+		t.Fatalf("%d fingerprints kept, at most %d expected", len(top), queryStatsShards)
+	}
+	if _, ok := statOf(t, s, latest); !ok {
+		t.Fatal("the latest fingerprint was evicted")
+	}
+}
+
+func TestQueryStatsRecordConcurrently(t *testing.T) {
+	s, _ := newTestQueryStats(config.DbQueryStatsConfig{})
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 500 {
+				s.Record(fmt.Sprintf("SELECT * FROM t%d WHERE id = %d", g, i), time.Millisecond, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var count int64
+	for _, stat := range s.Top(0, QueryStatsSortTotal, true) {
+		count += stat.Count
+	}
+	if count != 8*500 {
+		t.Fatalf("%d executions recorded, want %d", count, 8*500)
+	}
+}
@@ -0,0 +1,216 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"agentic/commerce/config"
+	"agentic/commerce/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+type primaryContextKey struct{}
+
+// WithPrimary forces reads made with ctx to the primary, for read-your-writes paths.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+func isPrimaryForced(ctx context.Context) bool {
+	forced, _ := ctx.Value(primaryContextKey{}).(bool)
+	return forced
+}
+
+type replica struct {
+	name      string
+	db        *sql.DB
+	dialector gorm.Dialector
+	healthy   atomic.Bool
+	checked   bool
+}
+
+// ReplicaSet routes reads to healthy replicas and falls back to the primary when none is.
+type ReplicaSet struct {
+	primary  gorm.ConnPool
+	replicas []*replica
+	byPool   map[gorm.ConnPool]*replica
+	next     atomic.Uint64
+	interval time.Duration
+	logger   *logger.AppLogger
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// RegisterReplicas opens the configured replicas and installs the read/write resolver on db.
+// Writes, and everything inside a transaction, keep going to the primary.
+func RegisterReplicas(db *gorm.DB, cfg *config.DbConfig, logger *logger.AppLogger) (*ReplicaSet, error) {
+	set := newReplicaSet(db, cfg.ReplicaCheckInterval, logger)
+	if len(cfg.Replicas) == 0 {
+		return set, nil
+	}
+
+	for _, r := range cfg.Replicas {
+		dialector, err := NewDialector(cfg.ForReplica(r))
+		if err != nil {
+			set.Close()
+			return nil, err
+		}
+		replicaDB, err := gorm.Open(dialector, &gorm.Config{})
+		if err != nil {
+			set.Close()
+			return nil, fmt.Errorf("cannot open replica %s:%d: %w", r.Host, r.Port, err)
+		}
+		sqlDB, err := replicaDB.DB()
+		if err != nil {
+			set.Close()
+			return nil, fmt.Errorf("cannot get sql database of replica %s:%d: %w", r.Host, r.Port, err)
+		}
+		configurePool(sqlDB, cfg)
+
+		replicaDialector, err := dialectorForConn(cfg.Dialect(), sqlDB)
+		if err != nil {
+			_ = sqlDB.Close()
+			set.Close()
+			return nil, err
+		}
+		set.add(fmt.Sprintf("%s:%d", r.Host, r.Port), sqlDB, replicaDialector)
+	}
+
+	if err := set.install(db); err != nil {
+		set.Close()
+		return nil, err
+	}
+	return set, nil
+}
+
+func newReplicaSet(db *gorm.DB, interval time.Duration, logger *logger.AppLogger) *ReplicaSet {
+	set := &ReplicaSet{
+		primary:  db.ConnPool,
+		byPool:   make(map[gorm.ConnPool]*replica),
+		interval: interval,
+		logger:   logger.WithScope(ReplicaSet{}),
+		stop:     make(chan struct{}),
+	}
+	if set.interval <= 0 {
+		set.interval = 5 * time.Second
+	}
+	return set
+}
+
+// add makes an opened replica part of the set; dialector wraps its pool.
+func (s *ReplicaSet) add(name string, db *sql.DB, dialector gorm.Dialector) {
+	rep := &replica{name: name, db: db, dialector: dialector}
+	s.replicas = append(s.replicas, rep)
+	s.byPool[db] = rep
+}
+
+// install checks the replicas once, registers the resolver on db and starts the health
+// checks.
+func (s *ReplicaSet) install(db *gorm.DB) error {
+	s.checkAll()
+
+	dialectors := make([]gorm.Dialector, len(s.replicas))
+	for i, rep := range s.replicas {
+		dialectors[i] = rep.dialector
+	}
+	err := db.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: dialectors,
+		Policy:   s,
+	}))
+	if err != nil {
+		return err
+	}
+
+	s.wg.Add(1)
+	go s.healthLoop()
+	return nil
+}
+
+// Resolve implements dbresolver.Policy with round robin over the healthy replicas.
+func (s *ReplicaSet) Resolve(pools []gorm.ConnPool) gorm.ConnPool {
+	n := uint64(len(pools))
+	start := s.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		pool := pools[(start+i)%n]
+		if rep, ok := s.byPool[pool]; !ok || rep.healthy.Load() {
+			return pool
+		}
+	}
+	return s.primary
+}
+
+// Healthy reports the health of every replica by host:port.
+func (s *ReplicaSet) Healthy() map[string]bool {
+	out := make(map[string]bool, len(s.replicas))
+	for _, rep := range s.replicas {
+		out[rep.name] = rep.healthy.Load()
+	}
+	return out
+}
+
+// Pools returns the connection pool of every replica by host:port.
+func (s *ReplicaSet) Pools() map[string]*sql.DB {
+	out := make(map[string]*sql.DB, len(s.replicas))
+	for _, rep := range s.replicas {
+		out[rep.name] = rep.db
+	}
+	return out
+}
+
+func (s *ReplicaSet) healthLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.checkAll()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *ReplicaSet) checkAll() {
+	for _, rep := range s.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), s.interval)
+		healthy := rep.db.PingContext(ctx) == nil
+		cancel()
+
+		// the first check reports the state, later ones only the changes
+		changed := rep.healthy.Swap(healthy) != healthy || !rep.checked
+		rep.checked = true
+		if !changed {
+			continue
+		}
+		if healthy {
+			s.logger.Info("replica {} is healthy, reads go to it", rep.name)
+		} else {
+			s.logger.Warn("replica {} is unhealthy, reads skip it", rep.name)
+		}
+	}
+}
+
+// Close stops the health checks and closes the replica connections.
+func (s *ReplicaSet) Close() {
+	select {
+	case <-s.stop:
+		return
+	default:
+		close(s.stop)
+	}
+	s.wg.Wait()
+
+	for _, rep := range s.replicas {
+		_ = rep.db.Close()
+	}
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"agentic/commerce/config"
+	"agentic/commerce/pkg/logger"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// origin is a one-row table telling which database answered.
+type origin struct {
+	ID     uint
+	Source string
+}
+
+func openOrigin(t *testing.T, source string) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), source+".db")), &gorm.Config{Logger: gormLogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	if err := db.AutoMigrate(&origin{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&origin{Source: source}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// newSQLiteReplicaSet installs SQLite replicas on a SQLite primary, each database
+// answering with its own name. Health is only checked when the test asks.
+func newSQLiteReplicaSet(t *testing.T, names ...string) (*gorm.DB, *ReplicaSet) {
+	t.Helper()
+	appLogger := logger.NewAppLogger(&config.Config{Mode: config.ModeDev, Logger: &config.Logger{Level: config.LevelError}})
+	primary := openOrigin(t, "primary")
+	set := newReplicaSet(primary, time.Hour, appLogger)
+	for _, name := range names {
+		sqlDB, err := openOrigin(t, name).DB()
+		if err != nil {
+			t.Fatal(err)
+		}
+		set.add(name, sqlDB, sqlite.Dialector{Conn: sqlDB})
+	}
+	if err := set.install(primary); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(set.Close)
+	return primary, set
+}
+
+func sourceOf(t *testing.T, db *gorm.DB) string {
+	t.Helper()
+	var row origin
+	if err := db.First(&row).Error; err != nil {
+		t.Fatal(err)
+	}
+	return row.Source
+}
+
+func TestReplicaSetRoundRobinsReads(t *testing.T) {
+	primary, _ := newSQLiteReplicaSet(t, "a", "b")
+	db := CreateGormDB(primary)
+
+	previous := sourceOf(t, db(context.Background()))
+	seen := map[string]int{previous: 1}
+	for range 5 {
+		source := sourceOf(t, db(context.Background()))
+		if source == previous {
+			t.Fatalf("%s answered twice in a row", source)
+		}
+		previous = source
+		seen[source]++
+	}
+	if seen["a"] != 3 || seen["b"] != 3 {
+		t.Fatalf("reads went to %v", seen)
+	}
+}
+
+func TestReplicaSetFallsBackWhenUnhealthy(t *testing.T) {
+	primary, set := newSQLiteReplicaSet(t, "a", "b")
+	db := CreateGormDB(primary)
+
+	_ = set.Pools()["a"].Close()
+	set.checkAll()
+	if healthy := set.Healthy(); healthy["a"] || !healthy["b"] {
+		t.Fatalf("health %v after closing a", healthy)
+	}
+	for range 4 {
+		if source := sourceOf(t, db(context.Background())); source != "b" {
+			t.Fatalf("a read went to %s while only b is healthy", source)
+		}
+	}
+
+	_ = set.Pools()["b"].Close()
+	set.checkAll()
+	if source := sourceOf(t, db(context.Background())); source != "primary" {
+		t.Fatalf("a read went to %s without a healthy replica", source)
+	}
+}
+
+func TestReplicaSetKeepsTheRestOnThePrimary(t *testing.T) {
+	primary, _ := newSQLiteReplicaSet(t, "a")
+	db := CreateGormDB(primary)
+	ctx := context.Background()
+
+	if source := sourceOf(t, db(WithPrimary(ctx))); source != "primary" {
+		t.Fatalf("a WithPrimary read went to %s", source)
+	}
+
+	err := NewTransactionManager(primary).RunInTx(ctx, func(ctx context.Context) error {
+		if source := sourceOf(t, db(ctx)); source != "primary" {
+			t.Errorf("a read in a transaction went to %s", source)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db(ctx).Create(&origin{Source: "written"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var written int64
+	if err := db(WithPrimary(ctx)).Model(&origin{}).Where("source = ?", "written").Count(&written).Error; err != nil || written != 1 {
+		t.Fatalf("%d written rows on the primary: %v", written, err)
+	}
+}
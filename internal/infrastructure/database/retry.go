@@ -0,0 +1,36 @@
+package database
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// backoff is the delay before retry attempt n (1-based): base doubled per attempt up to
+// max, of which a random half is jittered away so restarting instances don't retry in step.
+func backoff(attempt int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	delay = min(delay, max)
+
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + rand.N(half)
+}
+
+// sleep waits for d or until ctx is done, whichever comes first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"agentic/commerce/config"
+)
+
+func TestBackoffGrowsWithinTheJitterBounds(t *testing.T) {
+	const base, maxDelay = 100 * time.Millisecond, 3 * time.Second
+
+	tests := []struct {
+		attempt int
+		nominal time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{5, 1600 * time.Millisecond},
+		{6, maxDelay},
+		{200, maxDelay},
+	}
+	for _, tt := range tests {
+		lowest, highest := tt.nominal, time.Duration(0)
+		for range 1000 {
+			delay := backoff(tt.attempt, base, maxDelay)
+			if delay < tt.nominal/2 || delay >= tt.nominal {
+				t.Fatalf("attempt %d waits %s, want [%s, %s)", tt.attempt, delay, tt.nominal/2, tt.nominal)
+			}
+			lowest, highest = min(lowest, delay), max(highest, delay)
+		}
+		// the jitter spreads the delays over most of the range
+		if highest-lowest < tt.nominal/4 {
+			t.Fatalf("attempt %d waits between %s and %s only", tt.attempt, lowest, highest)
+		}
+	}
+
+	if delay := backoff(1, time.Nanosecond, time.Second); delay != time.Nanosecond {
+		t.Fatalf("a delay too short to jitter became %s", delay)
+	}
+}
+
+func TestSleepStopsOnCancel(t *testing.T) {
+	if err := sleep(context.Background(), time.Millisecond); err != nil {
+		t.Fatalf("a full sleep returned %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start := time.Now()
+	if err := sleep(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Fatalf("a cancelled sleep returned %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("the cancelled sleep lasted %s", elapsed)
+	}
+}
+
+func TestNewGormConnectionStopsRetryingOnCancel(t *testing.T) {
+	cfg := &config.DbConfig{
+		Host:         "127.0.0.1",
+		Port:         1, // nothing listens, every attempt is refused at once
+		User:         "nobody",
+		Database:     "nothing",
+		DialTimeout:  time.Second,
+		DialRetry:    100,
+		RetryBackoff: time.Hour,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := NewGormConnection(ctx, cfg, io.Discard)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("connecting returned %v, want the cancellation", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("connecting gave up after %s", elapsed)
+	}
+}
@@ -0,0 +1,157 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"agentic/commerce/pkg/apperror"
+
+	"gorm.io/gorm"
+)
+
+// ErrStatementTimeout wraps the error of a statement cancelled by its deadline.
+var ErrStatementTimeout = errors.New("statement timeout")
+
+const statementDeadlineKey = "timeout:deadline"
+
+type statementTimeoutContextKey struct{}
+
+// WithStatementTimeout overrides the statement timeout of the queries made with ctx;
+// zero or less disables it.
+func WithStatementTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, statementTimeoutContextKey{}, timeout)
+}
+
+// StatementTimeoutFrom returns the statement timeout set on ctx with
+// WithStatementTimeout, if any.
+func StatementTimeoutFrom(ctx context.Context) (time.Duration, bool) {
+	timeout, ok := ctx.Value(statementTimeoutContextKey{}).(time.Duration)
+	return timeout, ok
+}
+
+// TimeoutPlugin gives every statement a deadline on the context GormDB hands it: the one
+// set with WithStatementTimeout, Default otherwise. A shorter deadline already on the
+// context, like the one of the HTTP request, still wins.
+type TimeoutPlugin struct {
+	Default time.Duration
+}
+
+func (p *TimeoutPlugin) Name() string {
+	return "timeout"
+}
+
+type callbackRegistrar interface {
+	Register(name string, fn func(*gorm.DB)) error
+}
+
+func (p *TimeoutPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	// Row and Rows hand back a cursor that is read after the callbacks return, so their
+	// deadline is left to expire on its own instead of being cancelled.
+	hooks := []struct {
+		start, finish callbackRegistrar
+		release       func(*gorm.DB)
+	}{
+		{callbacks.Create().Before("*"), callbacks.Create().After("*"), finish},
+		{callbacks.Query().Before("*"), callbacks.Query().After("*"), finish},
+		{callbacks.Update().Before("*"), callbacks.Update().After("*"), finish},
+		{callbacks.Delete().Before("*"), callbacks.Delete().After("*"), finish},
+		{callbacks.Raw().Before("*"), callbacks.Raw().After("*"), finish},
+		{callbacks.Row().Before("*"), callbacks.Row().After("*"), finishRow},
+	}
+	for _, hook := range hooks {
+		if err := hook.start.Register("timeout:start", p.start); err != nil {
+			return err
+		}
+		if err := hook.finish.Register("timeout:finish", hook.release); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type statementDeadline struct {
+	parent context.Context
+	cancel context.CancelFunc
+}
+
+func (p *TimeoutPlugin) start(tx *gorm.DB) {
+	timeout, ok := StatementTimeoutFrom(tx.Statement.Context)
+	if !ok {
+		timeout = p.Default
+	}
+	if timeout <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(tx.Statement.Context, timeout)
+	tx.InstanceSet(statementDeadlineKey, statementDeadline{parent: tx.Statement.Context, cancel: cancel})
+	tx.Statement.Context = ctx
+}
+
+func finish(tx *gorm.DB) {
+	if deadline, ok := restoreContext(tx); ok {
+		deadline.cancel()
+	}
+}
+
+func finishRow(tx *gorm.DB) {
+	restoreContext(tx)
+}
+
+// restoreContext marks a timed out statement's error and puts the context without the
+// deadline back, as a chained query like Count then Find shares one Statement.
+func restoreContext(tx *gorm.DB) (statementDeadline, bool) {
+	value, ok := tx.InstanceGet(statementDeadlineKey)
+	if !ok {
+		return statementDeadline{}, false
+	}
+	deadline := value.(statementDeadline)
+
+	if tx.Error != nil && !errors.Is(tx.Error, ErrStatementTimeout) &&
+		(errors.Is(tx.Statement.Context.Err(), context.DeadlineExceeded) || hasSQLState(tx.Error, "57014")) {
+		tx.Error = fmt.Errorf("%w: %w", ErrStatementTimeout, tx.Error)
+	}
+	tx.Statement.Context = deadline.parent
+	return deadline, true
+}
+
+// ResolveError maps a database error to the apperror the API answers with: a timed out
+// statement is ErrTimeout, a database that can't be reached is ErrUnavailable, a
+// violated unique key ErrConflict and anything else ErrServer.
+func ResolveError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return apperror.ErrConflict
+	case errors.Is(err, ErrStatementTimeout), errors.Is(err, context.DeadlineExceeded):
+		return apperror.ErrTimeout
+	case isUnavailable(err):
+		return apperror.ErrUnavailable
+	default:
+		return apperror.ErrServer
+	}
+}
+
+func isUnavailable(err error) bool {
+	var opErr *net.OpError
+	if errors.Is(err, driver.ErrBadConn) || errors.As(err, &opErr) {
+		return true
+	}
+	// connection exceptions, insufficient resources and server shutdowns
+	for _, prefix := range []string{"08", "53", "57P"} {
+		if hasSQLState(err, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSQLState reports whether err carries a Postgres SQLSTATE starting with prefix.
+func hasSQLState(err error, prefix string) bool {
+	var pgErr interface{ SQLState() string }
+	return errors.As(err, &pgErr) && strings.HasPrefix(pgErr.SQLState(), prefix)
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type txContextKey struct{}
+type afterCommitContextKey struct{}
+
+// afterCommit collects the hooks registered while a transaction runs.
+type afterCommit struct {
+	hooks []func(ctx context.Context)
+}
+
+// ITransactionManager runs a unit of work inside a database transaction.
+type ITransactionManager interface {
+	// RunInTx commits when fn returns nil and rolls back when it returns an error or panics.
+	// Calling it again inside fn opens a savepoint on the ambient transaction.
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type transactionManager struct {
+	db *gorm.DB
+}
+
+func NewTransactionManager(db *gorm.DB) ITransactionManager {
+	return &transactionManager{
+		db: db,
+	}
+}
+
+func (m *transactionManager) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	db := m.db
+	parent, nested := TxFromContext(ctx)
+	if nested {
+		db = parent
+	}
+
+	// gorm rolls back on error and panic, and uses savepoints when db is already a transaction.
+	hooks := &afterCommit{}
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ContextWithTx(ctx, tx), afterCommitContextKey{}, hooks))
+	})
+	if err != nil {
+		return err
+	}
+
+	// a savepoint hands its hooks to the transaction it is part of
+	if outer, ok := ctx.Value(afterCommitContextKey{}).(*afterCommit); ok && nested {
+		outer.hooks = append(outer.hooks, hooks.hooks...)
+		return nil
+	}
+	for _, hook := range hooks.hooks {
+		hook(ctx)
+	}
+	return nil
+}
+
+// AfterCommit runs fn once the transaction of ctx commits, with the context RunInTx was
+// called with; a rollback drops it. Outside RunInTx fn runs at once. Meant for side
+// effects other readers must not see before the commit, like dropping cache keys.
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if hooks, ok := ctx.Value(afterCommitContextKey{}).(*afterCommit); ok {
+		hooks.hooks = append(hooks.hooks, fn)
+		return
+	}
+	fn(ctx)
+}
+
+// ContextWithTx stores the transaction in the context so GormDB picks it up.
+func ContextWithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// TxFromContext returns the ambient transaction, if any.
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(*gorm.DB)
+	return tx, ok && tx != nil
+}
+
+type noopTransactionManager struct{}
+
+// NewNoopTransactionManager runs fn directly, for the in-memory repositories which have
+// nothing to commit or roll back.
+func NewNoopTransactionManager() ITransactionManager {
+	return noopTransactionManager{}
+}
+
+func (noopTransactionManager) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
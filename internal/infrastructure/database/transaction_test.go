@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newSQLiteTransactionManager(t *testing.T) ITransactionManager {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return NewTransactionManager(db)
+}
+
+func TestAfterCommit(t *testing.T) {
+	txManager := newSQLiteTransactionManager(t)
+	var ran []string
+	hook := func(name string) func(context.Context) {
+		return func(ctx context.Context) {
+			if _, inTx := TxFromContext(ctx); inTx {
+				t.Errorf("%s ran inside the transaction", name)
+			}
+			ran = append(ran, name)
+		}
+	}
+
+	AfterCommit(context.Background(), hook("outside"))
+
+	rollback := errors.New("rollback")
+	err := txManager.RunInTx(context.Background(), func(ctx context.Context) error {
+		AfterCommit(ctx, hook("committed"))
+		_ = txManager.RunInTx(ctx, func(ctx context.Context) error {
+			AfterCommit(ctx, hook("rolled back savepoint"))
+			return rollback
+		})
+		err := txManager.RunInTx(ctx, func(ctx context.Context) error {
+			AfterCommit(ctx, hook("released savepoint"))
+			return nil
+		})
+		if len(ran) != 1 {
+			t.Errorf("ran %v before the commit", ran)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = txManager.RunInTx(context.Background(), func(ctx context.Context) error {
+		AfterCommit(ctx, hook("rolled back"))
+		return rollback
+	})
+
+	if want := []string{"outside", "committed", "released savepoint"}; !slices.Equal(ran, want) {
+		t.Fatalf("ran %v, want %v", ran, want)
+	}
+}
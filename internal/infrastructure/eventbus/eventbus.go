@@ -0,0 +1,166 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"agentic/commerce/pkg/logger"
+
+	"go.uber.org/fx"
+)
+
+const SUBSCRIBER_GROUP_NAME = "event-subscribers"
+
+// Event is anything published on the bus; EventName must not depend on the receiver's state.
+type Event interface {
+	EventName() string
+}
+
+type Handler func(ctx context.Context, event Event) error
+
+// Subscription binds a handler to one event name.
+type Subscription struct {
+	Event   string
+	Name    string
+	Async   bool
+	Handler Handler
+}
+
+// On subscribes a synchronous handler: it runs before Publish returns and its error is
+// returned to the publisher.
+func On[TEvent Event](name string, handler func(ctx context.Context, event TEvent) error) Subscription {
+	return subscribe(name, false, handler)
+}
+
+// OnAsync subscribes a handler that runs in the background; its errors are only reported.
+func OnAsync[TEvent Event](name string, handler func(ctx context.Context, event TEvent) error) Subscription {
+	return subscribe(name, true, handler)
+}
+
+func subscribe[TEvent Event](name string, async bool, handler func(ctx context.Context, event TEvent) error) Subscription {
+	var zero TEvent
+	return Subscription{
+		Event: zero.EventName(),
+		Name:  name,
+		Async: async,
+		Handler: func(ctx context.Context, event Event) error {
+			typed, ok := event.(TEvent)
+			if !ok {
+				return fmt.Errorf("subscriber %s expects %T, got %T", name, zero, event)
+			}
+			return handler(ctx, typed)
+		},
+	}
+}
+
+// AsSubscriber registers subscription constructors with the FX group the bus reads.
+func AsSubscriber(constructors ...interface{}) fx.Option {
+	annotated := make([]interface{}, len(constructors))
+	for i, constructor := range constructors {
+		annotated[i] = fx.Annotate(
+			constructor,
+			fx.ResultTags(`group:"`+SUBSCRIBER_GROUP_NAME+`"`),
+		)
+	}
+	return fx.Provide(annotated...)
+}
+
+type IEventBus interface {
+	Publish(ctx context.Context, events ...Event) error
+}
+
+// ErrorReporter receives failures of asynchronous handlers.
+type ErrorReporter func(ctx context.Context, subscription Subscription, event Event, err error)
+
+type Bus struct {
+	handlers map[string][]Subscription
+	logger   *logger.AppLogger
+	report   ErrorReporter
+	wg       sync.WaitGroup
+}
+
+type Params struct {
+	fx.In
+	Subscriptions []Subscription `group:"event-subscribers"`
+	Logger        *logger.AppLogger
+}
+
+func NewEventBus(p Params) *Bus {
+	bus := &Bus{
+		handlers: make(map[string][]Subscription),
+		logger:   p.Logger.WithScope(Bus{}),
+	}
+	bus.report = bus.logError
+	for _, subscription := range p.Subscriptions {
+		bus.handlers[subscription.Event] = append(bus.handlers[subscription.Event], subscription)
+	}
+	return bus
+}
+
+// OnError replaces the default reporter, which logs.
+func (b *Bus) OnError(report ErrorReporter) {
+	b.report = report
+}
+
+// Publish runs the synchronous handlers in subscription order and returns their joined
+// errors; a failing or panicking handler does not stop the others.
+func (b *Bus) Publish(ctx context.Context, events ...Event) error {
+	var errs []error
+	for _, event := range events {
+		for _, subscription := range b.handlers[event.EventName()] {
+			if subscription.Async {
+				b.dispatchAsync(ctx, subscription, event)
+				continue
+			}
+			if err := invoke(ctx, subscription, event); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", subscription.Name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Wait blocks until the running asynchronous handlers are done or ctx expires.
+func (b *Bus) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *Bus) dispatchAsync(ctx context.Context, subscription Subscription, event Event) {
+	// keep the request values (tenant, actor) but not its cancellation
+	ctx = context.WithoutCancel(ctx)
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		if err := invoke(ctx, subscription, event); err != nil {
+			b.report(ctx, subscription, event, err)
+		}
+	}()
+}
+
+func (b *Bus) logError(_ context.Context, subscription Subscription, event Event, err error) {
+	b.logger.Error("event handler {} failed on {}", err, subscription.Name, event.EventName())
+}
+
+func invoke(ctx context.Context, subscription Subscription, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return subscription.Handler(ctx, event)
+}
@@ -0,0 +1,102 @@
+package migration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var nonWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+const goTemplate = `package %s
+
+import (
+	"context"
+
+	"agentic/commerce/internal/infrastructure/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	register(migration.Migration{
+		Version: %q,
+		Name:    %q,
+		Up: func(ctx context.Context, tx *gorm.DB) error {
+			return nil
+		},
+		Down: func(ctx context.Context, tx *gorm.DB) error {
+			return nil
+		},
+	})
+}
+`
+
+// Create writes an empty up/down SQL pair into every dialect subdirectory of dir, or a
+// Go migration into dir itself when asGo is set, and returns the created paths.
+func Create(dir, name string, asGo bool, now time.Time) ([]string, error) {
+	name = strings.Trim(nonWord.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, fmt.Errorf("migration name must contain letters or digits")
+	}
+	file := now.UTC().Format(VersionLayout) + "_" + name
+
+	files := map[string]string{}
+	if asGo {
+		files[filepath.Join(dir, file+".go")] = fmt.Sprintf(goTemplate, filepath.Base(dir), now.UTC().Format(VersionLayout), name)
+	} else {
+		dialects, err := dialectDirs(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, dialect := range dialects {
+			base := filepath.Join(dir, dialect, file)
+			files[base+".up.sql"] = "-- " + name + " (up, " + dialect + ")\n"
+			files[base+".down.sql"] = "-- " + name + " (down, " + dialect + ")\n"
+		}
+	}
+
+	paths := make([]string, 0, len(files))
+	for path, content := range files {
+		if err := writeNew(path, content); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// dialectDirs lists the subdirectories of dir, one per supported database.
+func dialectDirs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var dialects []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dialects = append(dialects, entry.Name())
+		}
+	}
+	if len(dialects) == 0 {
+		return nil, fmt.Errorf("%s has no dialect directories", dir)
+	}
+	return dialects, nil
+}
+
+func writeNew(path, content string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(content); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
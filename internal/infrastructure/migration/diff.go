@@ -0,0 +1,417 @@
+package migration
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"agentic/commerce/internal/infrastructure/database"
+
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/migrator"
+	"gorm.io/gorm/schema"
+)
+
+type DriftKind string
+
+const (
+	DriftMissingTable   DriftKind = "missing table"
+	DriftMissingColumn  DriftKind = "missing column"
+	DriftExtraColumn    DriftKind = "extra column"
+	DriftColumnMismatch DriftKind = "mismatched column"
+	DriftMissingIndex   DriftKind = "missing index"
+	DriftExtraIndex     DriftKind = "extra index"
+	DriftIndexMismatch  DriftKind = "mismatched index"
+	DriftMissingEnum    DriftKind = "missing enum type"
+	DriftEnumMismatch   DriftKind = "mismatched enum type"
+)
+
+// Drift is one difference between a model and its table.
+type Drift struct {
+	Table  string
+	Kind   DriftKind
+	Name   string
+	Detail string
+}
+
+func (d Drift) String() string {
+	if d.Detail == "" {
+		return fmt.Sprintf("%s: %s %s", d.Table, d.Kind, d.Name)
+	}
+	return fmt.Sprintf("%s: %s %s (%s)", d.Table, d.Kind, d.Name, d.Detail)
+}
+
+var typeSize = regexp.MustCompile(`^([a-z0-9 ]+?)\s*\((\d+)(?:\s*,\s*\d+)?\)$`)
+
+// typeAliases folds the names GORM generates and the names the database reports into
+// one spelling, e.g. bigserial and int8 are both bigint.
+var typeAliases = map[string]string{
+	"bigserial":                   "bigint",
+	"int8":                        "bigint",
+	"serial":                      "integer",
+	"int":                         "integer",
+	"int4":                        "integer",
+	"smallserial":                 "smallint",
+	"int2":                        "smallint",
+	"bool":                        "boolean",
+	"float8":                      "double precision",
+	"float4":                      "real",
+	"decimal":                     "numeric",
+	"character varying":           "varchar",
+	"timestamp with time zone":    "timestamptz",
+	"timestamp without time zone": "timestamp",
+}
+
+// Diff compares the tables of db with the GORM schema of every model and returns the
+// differences ordered by table. Primary key indexes are left out, the column check
+// covers them. The partition key Postgres requires in the unique indexes of a
+// partitioned table, and makes NOT NULL, is not reported, nor is the soft delete column
+// MySQL, lacking partial indexes, keys unique indexes with to leave deleted rows out.
+func Diff(db *gorm.DB, models ...interface{}) ([]Drift, error) {
+	var drifts []Drift
+	cache := &sync.Map{}
+	migrator := db.Migrator()
+	checkedEnums := make(map[string]bool)
+
+	for _, model := range models {
+		s, err := schema.Parse(model, cache, db.NamingStrategy)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse %T: %w", model, err)
+		}
+
+		if !migrator.HasTable(s.Table) {
+			drifts = append(drifts, Drift{Table: s.Table, Kind: DriftMissingTable, Name: s.Table})
+			continue
+		}
+
+		partitionKey, err := partitionKeyOf(db, s.Table)
+		if err != nil {
+			return nil, err
+		}
+		columnDrifts, err := diffColumns(db, model, s, partitionKey)
+		if err != nil {
+			return nil, err
+		}
+		indexDrifts, err := diffIndexes(db, model, s, partitionKey)
+		if err != nil {
+			return nil, err
+		}
+		enumDrifts, err := diffEnums(db, s, checkedEnums)
+		if err != nil {
+			return nil, err
+		}
+		drifts = append(drifts, columnDrifts...)
+		drifts = append(drifts, indexDrifts...)
+		drifts = append(drifts, enumDrifts...)
+	}
+
+	sort.SliceStable(drifts, func(i, j int) bool {
+		return drifts[i].Table < drifts[j].Table
+	})
+	return drifts, nil
+}
+
+func diffColumns(db *gorm.DB, model interface{}, s *schema.Schema, partitionKey map[string]bool) ([]Drift, error) {
+	columnTypes, err := db.Migrator().ColumnTypes(model)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read columns of %s: %w", s.Table, err)
+	}
+	actual := make(map[string]gorm.ColumnType, len(columnTypes))
+	for _, column := range columnTypes {
+		actual[column.Name()] = column
+	}
+
+	var drifts []Drift
+	for _, name := range s.DBNames {
+		field := s.FieldsByDBName[name]
+		column, ok := actual[name]
+		if !ok {
+			drifts = append(drifts, Drift{Table: s.Table, Kind: DriftMissingColumn, Name: name})
+			continue
+		}
+		delete(actual, name)
+
+		var mismatches []string
+		wantType, wantSize := canonicalType(dataTypeOf(db, field))
+		gotType, gotSize := canonicalType(column.DatabaseTypeName())
+		if length, ok := column.Length(); ok && gotSize == 0 {
+			gotSize = length
+		}
+		if precision, _, ok := column.DecimalSize(); ok && gotSize == 0 && wantType == "datetime" {
+			gotSize = precision
+		}
+		if wantType != gotType || (wantSize != 0 && wantSize != gotSize) {
+			mismatches = append(mismatches, fmt.Sprintf("type %s, want %s", formatType(gotType, gotSize), formatType(wantType, wantSize)))
+		}
+		if nullable, ok := column.Nullable(); ok && !field.PrimaryKey && !partitionKey[name] && nullable == field.NotNull {
+			mismatches = append(mismatches, fmt.Sprintf("nullable %t, want %t", nullable, !field.NotNull))
+		}
+		if len(mismatches) > 0 {
+			drifts = append(drifts, Drift{Table: s.Table, Kind: DriftColumnMismatch, Name: name, Detail: strings.Join(mismatches, "; ")})
+		}
+	}
+
+	extra := make([]string, 0, len(actual))
+	for name := range actual {
+		extra = append(extra, name)
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		drifts = append(drifts, Drift{Table: s.Table, Kind: DriftExtraColumn, Name: name})
+	}
+	return drifts, nil
+}
+
+func diffIndexes(db *gorm.DB, model interface{}, s *schema.Schema, partitionKey map[string]bool) ([]Drift, error) {
+	var indexes []gorm.Index
+	var err error
+	if len(partitionKey) > 0 {
+		indexes, err = partitionedIndexes(db, s.Table)
+	} else {
+		indexes, err = db.Migrator().GetIndexes(model)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read indexes of %s: %w", s.Table, err)
+	}
+	actual := make(map[string]gorm.Index, len(indexes))
+	for _, index := range indexes {
+		if primary, ok := index.PrimaryKey(); ok && primary {
+			continue
+		}
+		actual[index.Name()] = index
+	}
+
+	// MySQL has no partial indexes, its migrations append the soft delete column to a
+	// unique key instead: live rows all share deleted_at 0
+	softDeleteKey := ""
+	if field := s.LookUpField("DeletedAt"); field != nil && db.Dialector.Name() == "mysql" {
+		softDeleteKey = field.DBName
+	}
+
+	var drifts []Drift
+	for _, want := range s.ParseIndexes() {
+		got, ok := actual[want.Name]
+		if !ok {
+			drifts = append(drifts, Drift{Table: s.Table, Kind: DriftMissingIndex, Name: want.Name})
+			continue
+		}
+		delete(actual, want.Name)
+
+		wantColumns := make([]string, 0, len(want.Fields))
+		for _, field := range want.Fields {
+			if field.Field != nil {
+				wantColumns = append(wantColumns, field.DBName)
+			} else {
+				wantColumns = append(wantColumns, field.Expression)
+			}
+		}
+		wantUnique := want.Class == "UNIQUE"
+
+		gotColumns := lo.Filter(got.Columns(), func(column string, _ int) bool {
+			if lo.Contains(wantColumns, column) {
+				return true
+			}
+			return !partitionKey[column] && !(wantUnique && column == softDeleteKey)
+		})
+
+		var mismatches []string
+		if !equalColumns(gotColumns, wantColumns) {
+			mismatches = append(mismatches, fmt.Sprintf("columns %v, want %v", gotColumns, wantColumns))
+		}
+		if unique, ok := got.Unique(); ok && unique != wantUnique {
+			mismatches = append(mismatches, fmt.Sprintf("unique %t, want %t", unique, wantUnique))
+		}
+		if len(mismatches) > 0 {
+			drifts = append(drifts, Drift{Table: s.Table, Kind: DriftIndexMismatch, Name: want.Name, Detail: strings.Join(mismatches, "; ")})
+		}
+	}
+
+	extra := make([]string, 0, len(actual))
+	for name := range actual {
+		extra = append(extra, name)
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		drifts = append(drifts, Drift{Table: s.Table, Kind: DriftExtraIndex, Name: name, Detail: fmt.Sprintf("columns %v", actual[name].Columns())})
+	}
+	return drifts, nil
+}
+
+// partitionKeyOf returns the partition key columns of a Postgres partitioned table, nil
+// for any other table.
+func partitionKeyOf(db *gorm.DB, table string) (map[string]bool, error) {
+	if db.Dialector.Name() != "postgres" {
+		return nil, nil
+	}
+	var columns []string
+	err := db.Raw(`SELECT a.attname FROM pg_partitioned_table p
+		JOIN pg_class c ON c.oid = p.partrelid
+		JOIN pg_attribute a ON a.attrelid = p.partrelid AND a.attnum = ANY (p.partattrs::int2[])
+		WHERE c.relname = ? AND c.relnamespace = current_schema()::regnamespace`, table).
+		Scan(&columns).Error
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the partition key of %s: %w", table, err)
+	}
+	return lo.SliceToMap(columns, func(column string) (string, bool) {
+		return column, true
+	}), nil
+}
+
+// partitionedIndexes reads the indexes of a partitioned table, which the Postgres
+// migrator of GORM only looks up on plain tables. Like there, indexes backing a
+// constraint are left out.
+func partitionedIndexes(db *gorm.DB, table string) ([]gorm.Index, error) {
+	var rows []struct {
+		IndexName  string
+		ColumnName string
+		Unique     bool
+		Primary    bool
+	}
+	err := db.Raw(`SELECT ci.relname AS index_name, a.attname AS column_name,
+			i.indisunique AS "unique", i.indisprimary AS "primary"
+		FROM pg_index i
+		JOIN pg_class ct ON ct.oid = i.indrelid
+		JOIN pg_class ci ON ci.oid = i.indexrelid
+		JOIN pg_attribute a ON a.attrelid = ct.oid AND a.attnum = ANY (i.indkey)
+		LEFT JOIN pg_constraint con ON con.conindid = i.indexrelid
+		WHERE con.oid IS NULL AND ct.relkind = 'p' AND ct.relname = ?
+			AND ct.relnamespace = current_schema()::regnamespace
+		ORDER BY ci.relname, array_position(i.indkey::int2[], a.attnum)`, table).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("cannot read indexes of %s: %w", table, err)
+	}
+
+	var indexes []gorm.Index
+	byName := make(map[string]*migrator.Index)
+	for _, row := range rows {
+		index, ok := byName[row.IndexName]
+		if !ok {
+			index = &migrator.Index{
+				TableName:       table,
+				NameValue:       row.IndexName,
+				PrimaryKeyValue: sql.NullBool{Bool: row.Primary, Valid: true},
+				UniqueValue:     sql.NullBool{Bool: row.Unique, Valid: true},
+			}
+			byName[row.IndexName] = index
+			indexes = append(indexes, index)
+		}
+		index.ColumnList = append(index.ColumnList, row.ColumnName)
+	}
+	return indexes, nil
+}
+
+// dataTypeOf is the type GORM creates the column with: the native type of an enum
+// registered with one on Postgres, the field's GormDBDataType when it has one, like
+// core.JSON, otherwise the dialect's mapping of its Go type.
+func dataTypeOf(db *gorm.DB, field *schema.Field) string {
+	if enumType := postgresEnum(db, field); enumType != nil {
+		return enumType.PostgresType
+	}
+	if typer, ok := reflect.New(field.IndirectFieldType).Interface().(migrator.GormDataTypeInterface); ok {
+		if dataType := typer.GormDBDataType(db, field); dataType != "" {
+			return dataType
+		}
+	}
+	return db.Dialector.DataTypeOf(field)
+}
+
+// columnModifiers are spelled into the type by SQLite and MySQL but are not part of the
+// type the database reports; the column check doesn't compare keys or nullability here.
+// "not null" goes before "null", which would leave a dangling "not".
+var columnModifiers = []string{"primary key autoincrement", "auto_increment", "unsigned", "not null", "null"}
+
+func postgresEnum(db *gorm.DB, field *schema.Field) *database.EnumType {
+	if db.Dialector.Name() != "postgres" || field.Serializer == nil {
+		return nil
+	}
+	enumType, ok := database.LookupEnum(field.IndirectFieldType)
+	if !ok || enumType.PostgresType == "" {
+		return nil
+	}
+	return enumType
+}
+
+// diffEnums compares the labels of the native Postgres enum types the model's columns
+// use with the registered values; each type is checked once across all models.
+func diffEnums(db *gorm.DB, s *schema.Schema, checked map[string]bool) ([]Drift, error) {
+	var drifts []Drift
+	for _, field := range s.Fields {
+		enumType := postgresEnum(db, field)
+		if enumType == nil || checked[enumType.PostgresType] {
+			continue
+		}
+		checked[enumType.PostgresType] = true
+
+		var labels []string
+		err := db.Raw(`SELECT e.enumlabel FROM pg_type t JOIN pg_enum e ON e.enumtypid = t.oid
+			WHERE t.typname = ? ORDER BY e.enumsortorder`, enumType.PostgresType).
+			Scan(&labels).Error
+		if err != nil {
+			return nil, fmt.Errorf("cannot read enum type %s: %w", enumType.PostgresType, err)
+		}
+		if len(labels) == 0 {
+			drifts = append(drifts, Drift{Table: s.Table, Kind: DriftMissingEnum, Name: enumType.PostgresType})
+			continue
+		}
+
+		missing, extra := lo.Difference(enumType.Labels(), labels)
+		var mismatches []string
+		if len(missing) > 0 {
+			mismatches = append(mismatches, fmt.Sprintf("missing labels %v", missing))
+		}
+		if len(extra) > 0 {
+			mismatches = append(mismatches, fmt.Sprintf("extra labels %v", extra))
+		}
+		if len(mismatches) > 0 {
+			drifts = append(drifts, Drift{Table: s.Table, Kind: DriftEnumMismatch, Name: enumType.PostgresType, Detail: strings.Join(mismatches, "; ")})
+		}
+	}
+	return drifts, nil
+}
+
+func canonicalType(dataType string) (string, int64) {
+	dataType = strings.ToLower(strings.TrimSpace(dataType))
+	for trimmed := true; trimmed; {
+		trimmed = false
+		for _, modifier := range columnModifiers {
+			if rest, ok := strings.CutSuffix(dataType, modifier); ok {
+				dataType, trimmed = strings.TrimSpace(rest), true
+			}
+		}
+	}
+	var size int64
+	if match := typeSize.FindStringSubmatch(dataType); match != nil {
+		dataType = match[1]
+		size, _ = strconv.ParseInt(match[2], 10, 64)
+	}
+	if alias, ok := typeAliases[dataType]; ok {
+		dataType = alias
+	}
+	return dataType, size
+}
+
+func formatType(dataType string, size int64) string {
+	if size == 0 {
+		return dataType
+	}
+	return fmt.Sprintf("%s(%d)", dataType, size)
+}
+
+func equalColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
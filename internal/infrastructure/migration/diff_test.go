@@ -0,0 +1,118 @@
+package migration
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestCanonicalType(t *testing.T) {
+	tests := []struct {
+		dataType string
+		want     string
+		wantSize int64
+	}{
+		{"bigint", "bigint", 0},
+		{"BIGSERIAL", "bigint", 0},
+		{"int8", "bigint", 0},
+		{"integer PRIMARY KEY AUTOINCREMENT", "integer", 0},
+		{"bigint unsigned auto_increment", "bigint", 0},
+		{"bigint unsigned not null", "bigint", 0},
+		{"bigint not null", "bigint", 0},
+		{"text null", "text", 0},
+		{"int unsigned not null auto_increment", "integer", 0},
+		{"varchar(64)", "varchar", 64},
+		{"character varying(255)", "varchar", 255},
+		{"numeric(10, 2)", "numeric", 10},
+		{"decimal(10,2)", "numeric", 10},
+		{"datetime(3)", "datetime", 3},
+		{"timestamp with time zone", "timestamptz", 0},
+		{" Boolean ", "boolean", 0},
+		{"float8", "double precision", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dataType, func(t *testing.T) {
+			got, size := canonicalType(tt.dataType)
+			if got != tt.want || size != tt.wantSize {
+				t.Fatalf("canonicalType(%q) = %q, %d, want %q, %d", tt.dataType, got, size, tt.want, tt.wantSize)
+			}
+		})
+	}
+}
+
+type gadget struct {
+	ID     uint   `gorm:"primarykey"`
+	Name   string `gorm:"Column:name;size:64;not null;index"`
+	Weight int    `gorm:"Column:weight"`
+	Serial string `gorm:"Column:serial;size:32;uniqueIndex"`
+}
+
+type gizmo struct {
+	ID uint `gorm:"primarykey"`
+}
+
+func newDiffDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return db
+}
+
+func TestDiffMatchesAMigratedModel(t *testing.T) {
+	db := newDiffDB(t)
+	if err := db.AutoMigrate(&gadget{}); err != nil {
+		t.Fatal(err)
+	}
+
+	drifts, err := Diff(db, &gadget{})
+	if err != nil || len(drifts) != 0 {
+		t.Fatalf("drifts %v: %v", drifts, err)
+	}
+}
+
+func TestDiffReportsDrift(t *testing.T) {
+	db := newDiffDB(t)
+	// weight is missing, legacy is extra, serial is an integer and its index is missing
+	err := db.Exec(`CREATE TABLE gadgets (
+		id integer PRIMARY KEY AUTOINCREMENT,
+		name text NOT NULL,
+		serial integer,
+		legacy text
+	)`).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("CREATE INDEX idx_gadgets_name ON gadgets (name)").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	drifts, err := Diff(db, &gizmo{}, &gadget{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Drift{
+		{Table: "gadgets", Kind: DriftMissingColumn, Name: "weight"},
+		{Table: "gadgets", Kind: DriftColumnMismatch, Name: "serial", Detail: "type integer, want text"},
+		{Table: "gadgets", Kind: DriftExtraColumn, Name: "legacy"},
+		{Table: "gadgets", Kind: DriftMissingIndex, Name: "idx_gadgets_serial"},
+		{Table: "gizmos", Kind: DriftMissingTable, Name: "gizmos"},
+	}
+	if len(drifts) != len(want) {
+		t.Fatalf("drifts %v, want %v", drifts, want)
+	}
+	for i, drift := range want {
+		if drifts[i] != drift {
+			t.Fatalf("drift %d is %v, want %v", i, drifts[i], drift)
+		}
+	}
+}
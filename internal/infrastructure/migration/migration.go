@@ -0,0 +1,118 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// VersionLayout is the UTC timestamp every migration version is formatted with.
+const VersionLayout = "20060102150405"
+
+var (
+	ErrPendingMigrations = errors.New("database schema is behind, run `migrate up`")
+	ErrUnknownMigrations = errors.New("database has migrations this build doesn't know about")
+	ErrDuplicateVersion  = errors.New("duplicate migration version")
+	ErrIrreversible      = errors.New("migration has no down step")
+)
+
+var fileNamePattern = regexp.MustCompile(`^(\d{14})_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Func changes the schema inside the transaction of its migration.
+type Func func(ctx context.Context, tx *gorm.DB) error
+
+// Migration is one versioned change, either loaded from a pair of SQL files or written in
+// Go for data backfills that are awkward in SQL.
+type Migration struct {
+	Version string
+	Name    string
+	Up      Func
+	Down    Func
+}
+
+// Load reads <version>_<name>.up.sql and .down.sql files from fsys, merges them with the
+// Go migrations and returns everything ordered by version.
+func Load(fsys fs.FS, goMigrations ...Migration) ([]Migration, error) {
+	byVersion := make(map[string]*Migration)
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %q doesn't match <version>_<name>.(up|down).sql", entry.Name())
+		}
+		version, name, direction := match[1], match[2], match[3]
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("%w: %s is both %q and %q", ErrDuplicateVersion, version, m.Name, name)
+		}
+		if direction == "up" {
+			m.Up = execSQL(string(content))
+		} else {
+			m.Down = execSQL(string(content))
+		}
+	}
+
+	for _, m := range goMigrations {
+		if _, ok := byVersion[m.Version]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateVersion, m.Version)
+		}
+		byVersion[m.Version] = &m
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == nil {
+			return nil, fmt.Errorf("migration %s_%s has no up step", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+func execSQL(content string) Func {
+	return func(ctx context.Context, tx *gorm.DB) error {
+		if isBlank(content) {
+			return nil
+		}
+		return tx.WithContext(ctx).Exec(content).Error
+	}
+}
+
+// isBlank reports whether content has nothing but comments, which is how a migration
+// that only applies to some dialects is left empty for the others; MySQL rejects
+// executing it.
+func isBlank(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,243 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"agentic/commerce/internal/infrastructure/database"
+
+	"gorm.io/gorm"
+)
+
+// SchemaMigration is a row of schema_migrations, one per applied migration.
+type SchemaMigration struct {
+	Version   string    `gorm:"Column:version;primaryKey;size:14"`
+	Name      string    `gorm:"Column:name;size:255;not null"`
+	AppliedAt time.Time `gorm:"Column:applied_at;not null"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Status is a migration together with when it was applied, if it was.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+	// Unknown is set for versions recorded in the database that this build doesn't ship.
+	Unknown bool
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *gorm.DB, migrations []Migration) *Migrator {
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}
+}
+
+// Up applies up to steps pending migrations, all of them when steps <= 0. Each migration
+// runs in its own transaction together with its schema_migrations row.
+func (m *Migrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+	var applied []Migration
+	err := m.locked(ctx, func(ctx context.Context, db *gorm.DB) error {
+		done, err := m.applied(ctx, db)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if steps > 0 && len(applied) == steps {
+				break
+			}
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				if err := migration.Up(ctx, tx); err != nil {
+					return err
+				}
+				return tx.Create(&SchemaMigration{
+					Version:   migration.Version,
+					Name:      migration.Name,
+					AppliedAt: time.Now().UTC(),
+				}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %s_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the last steps applied migrations, one when steps <= 0.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+
+	var reverted []Migration
+	err := m.locked(ctx, func(ctx context.Context, db *gorm.DB) error {
+		done, err := m.applied(ctx, db)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == nil {
+				return fmt.Errorf("%w: %s_%s", ErrIrreversible, migration.Version, migration.Name)
+			}
+			err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				if err := migration.Down(ctx, tx); err != nil {
+					return err
+				}
+				return tx.Delete(&SchemaMigration{Version: migration.Version}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("rolling back %s_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status lists every known migration in order, followed by the applied versions this
+// build doesn't know about.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	if err := m.ensureTable(ctx, m.db); err != nil {
+		return nil, err
+	}
+	done, err := m.applied(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if row, ok := done[migration.Version]; ok {
+			status.AppliedAt = &row.AppliedAt
+			delete(done, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, row := range done {
+		statuses = append(statuses, Status{
+			Migration: Migration{Version: row.Version, Name: row.Name},
+			AppliedAt: &row.AppliedAt,
+			Unknown:   true,
+		})
+	}
+	return statuses, nil
+}
+
+// Version returns the last applied version, empty when nothing is applied.
+func (m *Migrator) Version(ctx context.Context) (string, error) {
+	if err := m.ensureTable(ctx, m.db); err != nil {
+		return "", err
+	}
+	var row SchemaMigration
+	err := m.db.WithContext(ctx).Order("version DESC").Limit(1).Find(&row).Error
+	return row.Version, err
+}
+
+// Verify fails unless every known migration is applied and nothing else is.
+func (m *Migrator) Verify(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	var pending, unknown []string
+	for _, status := range statuses {
+		switch {
+		case status.Unknown:
+			unknown = append(unknown, status.Version)
+		case status.AppliedAt == nil:
+			pending = append(pending, status.Version)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("%w: %v", ErrUnknownMigrations, unknown)
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %d pending %v", ErrPendingMigrations, len(pending), pending)
+	}
+	return nil
+}
+
+func (m *Migrator) applied(ctx context.Context, db *gorm.DB) (map[string]SchemaMigration, error) {
+	var rows []SchemaMigration
+	if err := db.WithContext(ctx).Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	done := make(map[string]SchemaMigration, len(rows))
+	for _, row := range rows {
+		done[row.Version] = row
+	}
+	return done, nil
+}
+
+func (m *Migrator) ensureTable(ctx context.Context, db *gorm.DB) error {
+	db = db.WithContext(ctx)
+	if db.Migrator().HasTable(&SchemaMigration{}) {
+		return nil
+	}
+	return db.Migrator().CreateTable(&SchemaMigration{})
+}
+
+// locked runs fn on a single connection holding an advisory lock on Postgres or a named
+// lock on MySQL, so two instances deploying at once don't apply the same migration
+// twice. SQLite is single-writer and needs neither. Neither the lock wait nor the
+// migrations are cut short by the statement timeouts, they run as long as they take.
+func (m *Migrator) locked(ctx context.Context, fn func(ctx context.Context, db *gorm.DB) error) error {
+	ctx = database.WithStatementTimeout(ctx, 0)
+	return m.db.WithContext(ctx).Connection(func(db *gorm.DB) error {
+		switch db.Dialector.Name() {
+		case "postgres":
+			if err := db.Exec("SET statement_timeout = 0").Error; err != nil {
+				return err
+			}
+			// back to the statement_timeout the connection was opened with
+			defer db.Exec("RESET statement_timeout")
+			key := lockKey()
+			if err := db.Exec("SELECT pg_advisory_lock(?)", key).Error; err != nil {
+				return err
+			}
+			defer db.Exec("SELECT pg_advisory_unlock(?)", key)
+		case "mysql":
+			name := SchemaMigration{}.TableName()
+			var acquired *int
+			if err := db.Raw("SELECT GET_LOCK(?, -1)", name).Scan(&acquired).Error; err != nil {
+				return err
+			}
+			if acquired == nil || *acquired != 1 {
+				return fmt.Errorf("cannot acquire the %s lock", name)
+			}
+			defer db.Exec("SELECT RELEASE_LOCK(?)", name)
+		}
+		if err := m.ensureTable(ctx, db); err != nil {
+			return err
+		}
+		return fn(ctx, db)
+	})
+}
+
+func lockKey() int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(SchemaMigration{}.TableName()))
+	return int64(h.Sum64())
+}
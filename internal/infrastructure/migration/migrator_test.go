@@ -0,0 +1,294 @@
+package migration_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"agentic/commerce/internal/app"
+	"agentic/commerce/internal/infrastructure/migration"
+	"agentic/commerce/internal/testkit"
+	"agentic/commerce/migrations"
+
+	"github.com/glebarez/sqlite"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return db
+}
+
+// widgets creates a table, adds a column to it and fills it from Go.
+func widgets(t *testing.T) []migration.Migration {
+	t.Helper()
+	all, err := migration.Load(fstest.MapFS{
+		"20260101000000_create_widgets.up.sql":    {Data: []byte("CREATE TABLE widgets (id integer PRIMARY KEY)")},
+		"20260101000000_create_widgets.down.sql":  {Data: []byte("DROP TABLE widgets")},
+		"20260102000000_add_widget_name.up.sql":   {Data: []byte("ALTER TABLE widgets ADD COLUMN name text")},
+		"20260102000000_add_widget_name.down.sql": {Data: []byte("ALTER TABLE widgets DROP COLUMN name")},
+	}, migration.Migration{
+		Version: "20260103000000",
+		Name:    "seed_widgets",
+		Up: func(ctx context.Context, tx *gorm.DB) error {
+			return tx.Exec("INSERT INTO widgets (id, name) VALUES (1, 'first')").Error
+		},
+		Down: func(ctx context.Context, tx *gorm.DB) error {
+			return tx.Exec("DELETE FROM widgets").Error
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return all
+}
+
+func versions(migrations []migration.Migration) []string {
+	out := make([]string, 0, len(migrations))
+	for _, m := range migrations {
+		out = append(out, m.Version)
+	}
+	return out
+}
+
+func currentVersion(t *testing.T, migrator *migration.Migrator) string {
+	t.Helper()
+	version, err := migrator.Version(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return version
+}
+
+func TestMigratorUpAppliesPendingMigrationsInOrder(t *testing.T) {
+	db := newSQLite(t)
+	all := widgets(t)
+	ctx := context.Background()
+
+	applied, err := migration.NewMigrator(db, all[:1]).Up(ctx, 0)
+	if err != nil || len(applied) != 1 {
+		t.Fatalf("applied %v: %v", versions(applied), err)
+	}
+	migrator := migration.NewMigrator(db, all)
+	if applied, err := migrator.Up(ctx, 1); err != nil || len(applied) != 1 || applied[0].Version != "20260102000000" {
+		t.Fatalf("one step applied %v: %v", versions(applied), err)
+	}
+	if applied, err := migrator.Up(ctx, 0); err != nil || len(applied) != 1 || applied[0].Version != "20260103000000" {
+		t.Fatalf("the rest applied %v: %v", versions(applied), err)
+	}
+	if applied, err := migrator.Up(ctx, 0); err != nil || len(applied) != 0 {
+		t.Fatalf("an up to date schema applied %v: %v", versions(applied), err)
+	}
+
+	var name string
+	if err := db.Raw("SELECT name FROM widgets WHERE id = 1").Scan(&name).Error; err != nil || name != "first" {
+		t.Fatalf("the go migration wrote %q: %v", name, err)
+	}
+	if version := currentVersion(t, migrator); version != "20260103000000" {
+		t.Fatalf("version %q", version)
+	}
+}
+
+func TestMigratorUpRollsBackAFailingMigration(t *testing.T) {
+	db := newSQLite(t)
+	broken := append(widgets(t), migration.Migration{
+		Version: "20260104000000",
+		Name:    "broken",
+		Up: func(ctx context.Context, tx *gorm.DB) error {
+			if err := tx.Exec("INSERT INTO widgets (id, name) VALUES (2, 'second')").Error; err != nil {
+				return err
+			}
+			return tx.Exec("INSERT INTO missing_table VALUES (1)").Error
+		},
+	})
+	migrator := migration.NewMigrator(db, broken)
+
+	applied, err := migrator.Up(context.Background(), 0)
+	if err == nil || len(applied) != 3 {
+		t.Fatalf("applied %v: %v", versions(applied), err)
+	}
+	if version := currentVersion(t, migrator); version != "20260103000000" {
+		t.Fatalf("the failed migration was recorded: %q", version)
+	}
+	var count int64
+	if err := db.Raw("SELECT count(*) FROM widgets").Scan(&count).Error; err != nil || count != 1 {
+		t.Fatalf("the failed migration left %d widgets: %v", count, err)
+	}
+}
+
+func TestMigratorDownRollsBackToAnEarlierVersion(t *testing.T) {
+	db := newSQLite(t)
+	migrator := migration.NewMigrator(db, widgets(t))
+	ctx := context.Background()
+	if _, err := migrator.Up(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	reverted, err := migrator.Down(ctx, 0)
+	if err != nil || len(reverted) != 1 || reverted[0].Version != "20260103000000" {
+		t.Fatalf("a default down reverted %v: %v", versions(reverted), err)
+	}
+	reverted, err = migrator.Down(ctx, 1)
+	if err != nil || len(reverted) != 1 || reverted[0].Version != "20260102000000" {
+		t.Fatalf("reverted %v: %v", versions(reverted), err)
+	}
+	if version := currentVersion(t, migrator); version != "20260101000000" {
+		t.Fatalf("version %q", version)
+	}
+	if db.Migrator().HasColumn("widgets", "name") {
+		t.Fatal("the down step didn't drop the column")
+	}
+
+	// going up again replays the reverted migrations only
+	applied, err := migrator.Up(ctx, 0)
+	if err != nil || len(applied) != 2 {
+		t.Fatalf("applied %v: %v", versions(applied), err)
+	}
+	if reverted, err := migrator.Down(ctx, 10); err != nil || len(reverted) != 3 {
+		t.Fatalf("rolling everything back reverted %v: %v", versions(reverted), err)
+	}
+	if version := currentVersion(t, migrator); version != "" || db.Migrator().HasTable("widgets") {
+		t.Fatalf("version %q left after rolling everything back", version)
+	}
+}
+
+func TestMigratorDownStopsAtAnIrreversibleMigration(t *testing.T) {
+	db := newSQLite(t)
+	all := append(widgets(t), migration.Migration{
+		Version: "20260104000000",
+		Name:    "irreversible",
+		Up:      func(ctx context.Context, tx *gorm.DB) error { return nil },
+	})
+	migrator := migration.NewMigrator(db, all)
+	if _, err := migrator.Up(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := migrator.Down(context.Background(), 2); !errors.Is(err, migration.ErrIrreversible) {
+		t.Fatalf("expected ErrIrreversible, got %v", err)
+	}
+	if version := currentVersion(t, migrator); version != "20260104000000" {
+		t.Fatalf("version %q", version)
+	}
+}
+
+func TestMigratorStatusListsPendingAndUnknownMigrations(t *testing.T) {
+	db := newSQLite(t)
+	all := widgets(t)
+	ctx := context.Background()
+	if _, err := migration.NewMigrator(db, all).Up(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := migration.NewMigrator(db, all).Status(ctx)
+	if err != nil || len(statuses) != 3 {
+		t.Fatalf("statuses %+v: %v", statuses, err)
+	}
+	if statuses[0].AppliedAt == nil || statuses[1].AppliedAt == nil || statuses[2].AppliedAt != nil {
+		t.Fatalf("statuses %+v", statuses)
+	}
+
+	// an older build doesn't know the second migration
+	statuses, err = migration.NewMigrator(db, all[:1]).Status(ctx)
+	if err != nil || len(statuses) != 2 || !statuses[1].Unknown || statuses[1].Version != "20260102000000" {
+		t.Fatalf("statuses %+v: %v", statuses, err)
+	}
+}
+
+// startVerified starts what serve adds to verify the schema.
+func startVerified(migrator *migration.Migrator) error {
+	return fx.New(
+		fx.Supply(migrator),
+		app.VerifySchemaModule,
+		fx.NopLogger,
+	).Start(context.Background())
+}
+
+func TestVerifySchemaRefusesAnOutOfDateSchema(t *testing.T) {
+	db := newSQLite(t)
+	all := widgets(t)
+	migrator := migration.NewMigrator(db, all)
+	if _, err := migrator.Up(context.Background(), 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := startVerified(migrator); !errors.Is(err, migration.ErrPendingMigrations) {
+		t.Fatalf("expected ErrPendingMigrations, got %v", err)
+	}
+	if err := startVerified(migration.NewMigrator(db, all[:1])); !errors.Is(err, migration.ErrUnknownMigrations) {
+		t.Fatalf("expected ErrUnknownMigrations, got %v", err)
+	}
+	if _, err := migrator.Up(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := startVerified(migrator); err != nil {
+		t.Fatalf("an up to date schema was refused: %v", err)
+	}
+}
+
+func TestMigrationsRollBackCleanlyOnSQLite(t *testing.T) {
+	db := newSQLite(t)
+	all, err := migrations.All("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator := migration.NewMigrator(db, all)
+	ctx := context.Background()
+
+	for range 2 {
+		if _, err := migrator.Up(ctx, 0); err != nil {
+			t.Fatal(err)
+		}
+		if reverted, err := migrator.Down(ctx, len(all)); err != nil || len(reverted) != len(all) {
+			t.Fatalf("reverted %d of %d migrations: %v", len(reverted), len(all), err)
+		}
+	}
+}
+
+func TestMigratorLockAppliesAMigrationOnce(t *testing.T) {
+	db := testkit.Postgres(t)
+	var runs atomic.Int32
+	all := []migration.Migration{{
+		Version: "20260101000000",
+		Name:    "slow",
+		Up: func(ctx context.Context, tx *gorm.DB) error {
+			runs.Add(1)
+			time.Sleep(100 * time.Millisecond)
+			return tx.Exec("CREATE TABLE slow (id integer)").Error
+		},
+	}}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = migration.NewMigrator(db, all).Up(context.Background(), 0)
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		t.Fatal(err)
+	}
+	if runs.Load() != 1 {
+		t.Fatalf("the migration ran %d times", runs.Load())
+	}
+}
@@ -0,0 +1,45 @@
+package outbox
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaPublisher keys messages by aggregate, so one aggregate's events share a partition
+// and keep their order.
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaPublisher(brokers []string, topic string) *KafkaPublisher {
+	return &KafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+		},
+	}
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, msg Message) error {
+	data, err := encode(msg)
+	if err != nil {
+		return err
+	}
+
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(msg.AggregateType + ":" + msg.AggregateID),
+		Value: data,
+		Headers: []kafka.Header{
+			{Key: "event_type", Value: []byte(msg.EventType)},
+			{Key: "message_id", Value: []byte(strconv.FormatUint(msg.ID, 10))},
+		},
+	})
+}
+
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
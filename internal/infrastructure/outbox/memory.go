@@ -0,0 +1,43 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// InMemoryOutbox keeps the enqueued messages in a slice so tests can assert on them.
+type InMemoryOutbox struct {
+	mu       sync.Mutex
+	messages []MessageModel
+}
+
+func NewInMemoryOutbox() *InMemoryOutbox {
+	return &InMemoryOutbox{}
+}
+
+func (o *InMemoryOutbox) Add(_ context.Context, aggregateType, aggregateID, eventType string, payload map[string]interface{}) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := time.Now()
+	o.messages = append(o.messages, MessageModel{
+		ID:            uint64(len(o.messages) + 1),
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        StatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	})
+	return nil
+}
+
+// Messages returns a copy of everything added so far.
+func (o *InMemoryOutbox) Messages() []MessageModel {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return append([]MessageModel(nil), o.messages...)
+}
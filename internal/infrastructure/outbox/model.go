@@ -0,0 +1,66 @@
+package outbox
+
+import (
+	"time"
+
+	"agentic/commerce/internal/core"
+	"agentic/commerce/internal/infrastructure/database"
+
+	"github.com/orsinium-labs/enum"
+)
+
+type Status enum.Member[string]
+
+var (
+	StatusPending   = Status{"pending"}
+	StatusDelivered = Status{"delivered"}
+	StatusFailed    = Status{"failed"}
+
+	Statuses = database.RegisterEnum(enum.New(StatusPending, StatusDelivered, StatusFailed))
+)
+
+// MessageModel is an event waiting to be published, written in the same transaction as
+// the change it describes.
+type MessageModel struct {
+	ID            uint64     `gorm:"primarykey"`
+	TenantID      string     `gorm:"Column:tenant_id;size:64;not null;default:'default';index"`
+	AggregateType string     `gorm:"Column:aggregate_type;size:64;not null;index:idx_outbox_messages_aggregate"`
+	AggregateID   string     `gorm:"Column:aggregate_id;size:128;not null;index:idx_outbox_messages_aggregate"`
+	EventType     string     `gorm:"Column:event_type;size:128;not null"`
+	Payload       core.JSON  `gorm:"Column:payload"`
+	Status        Status     `gorm:"Column:status;serializer:enum;size:16;not null;index:idx_outbox_messages_pending"`
+	Attempts      int        `gorm:"Column:attempts;not null;default:0"`
+	NextAttemptAt time.Time  `gorm:"Column:next_attempt_at;index:idx_outbox_messages_pending"`
+	LastError     string     `gorm:"Column:last_error"`
+	CreatedAt     time.Time  `gorm:"Column:created_at"`
+	PublishedAt   *time.Time `gorm:"Column:published_at;index"`
+	// LockedUntil is the end of the lease of the relay publishing the message.
+	LockedUntil *time.Time `gorm:"Column:locked_until"`
+}
+
+func (MessageModel) TableName() string {
+	return "outbox_messages"
+}
+
+// Message is what publishers receive.
+type Message struct {
+	ID            uint64
+	TenantID      string
+	AggregateType string
+	AggregateID   string
+	EventType     string
+	Payload       map[string]interface{}
+	CreatedAt     time.Time
+}
+
+func (m *MessageModel) toMessage() Message {
+	return Message{
+		ID:            m.ID,
+		TenantID:      m.TenantID,
+		AggregateType: m.AggregateType,
+		AggregateID:   m.AggregateID,
+		EventType:     m.EventType,
+		Payload:       m.Payload,
+		CreatedAt:     m.CreatedAt,
+	}
+}
@@ -0,0 +1,49 @@
+package outbox
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSPublisher publishes each message on "<prefix>.<event type>".
+type NATSPublisher struct {
+	conn          *nats.Conn
+	subjectPrefix string
+}
+
+func NewNATSPublisher(url, subjectPrefix string) (*NATSPublisher, error) {
+	conn, err := nats.Connect(url)
+	if err != nil {
+		return nil, err
+	}
+	return &NATSPublisher{
+		conn:          conn,
+		subjectPrefix: subjectPrefix,
+	}, nil
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, msg Message) error {
+	data, err := encode(msg)
+	if err != nil {
+		return err
+	}
+
+	subject := msg.EventType
+	if p.subjectPrefix != "" {
+		subject = p.subjectPrefix + "." + subject
+	}
+
+	natsMsg := nats.NewMsg(subject)
+	natsMsg.Data = data
+	natsMsg.Header.Set(nats.MsgIdHdr, strconv.FormatUint(msg.ID, 10))
+	if err := p.conn.PublishMsg(natsMsg); err != nil {
+		return err
+	}
+	return p.conn.FlushWithContext(ctx)
+}
+
+func (p *NATSPublisher) Close() error {
+	return p.conn.Drain()
+}
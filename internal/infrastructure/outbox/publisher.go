@@ -0,0 +1,68 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-json-experiment/json/v1"
+)
+
+// Publisher delivers outbox messages to the outside world. Publish must only return nil
+// once the broker has accepted the message.
+type Publisher interface {
+	Publish(ctx context.Context, msg Message) error
+	Close() error
+}
+
+// InMemoryPublisher fans messages out to in-process subscribers, for development and tests.
+type InMemoryPublisher struct {
+	mu          sync.RWMutex
+	subscribers []func(ctx context.Context, msg Message) error
+}
+
+func NewInMemoryPublisher() *InMemoryPublisher {
+	return &InMemoryPublisher{}
+}
+
+func (p *InMemoryPublisher) Subscribe(fn func(ctx context.Context, msg Message) error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.subscribers = append(p.subscribers, fn)
+}
+
+func (p *InMemoryPublisher) Publish(ctx context.Context, msg Message) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, fn := range p.subscribers {
+		if err := fn(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *InMemoryPublisher) Close() error {
+	return nil
+}
+
+type envelope struct {
+	ID            uint64                 `json:"id"`
+	TenantID      string                 `json:"tenant_id"`
+	AggregateType string                 `json:"aggregate_type"`
+	AggregateID   string                 `json:"aggregate_id"`
+	EventType     string                 `json:"event_type"`
+	Payload       map[string]interface{} `json:"payload"`
+	OccurredAt    string                 `json:"occurred_at"`
+}
+
+func encode(msg Message) ([]byte, error) {
+	return json.Marshal(envelope{
+		ID:            msg.ID,
+		TenantID:      msg.TenantID,
+		AggregateType: msg.AggregateType,
+		AggregateID:   msg.AggregateID,
+		EventType:     msg.EventType,
+		Payload:       msg.Payload,
+		OccurredAt:    msg.CreatedAt.UTC().Format("2006-01-02T15:04:05.000Z07:00"),
+	})
+}
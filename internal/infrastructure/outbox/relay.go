@@ -0,0 +1,246 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"agentic/commerce/internal/infrastructure/tenancy"
+	"agentic/commerce/pkg/logger"
+
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RelayOptions struct {
+	PollInterval    time.Duration
+	BatchSize       int
+	MaxAttempts     int
+	RetryBackoff    time.Duration
+	MaxBackoff      time.Duration
+	Retention       time.Duration
+	CleanupInterval time.Duration
+	// Lease is how long a relay holds the messages it picked while publishing them,
+	// another instance takes over the ones it didn't settle by then.
+	Lease time.Duration
+}
+
+func (o RelayOptions) withDefaults() RelayOptions {
+	if o.PollInterval <= 0 {
+		o.PollInterval = time.Second
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 100
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 10
+	}
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = time.Second
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = 5 * time.Minute
+	}
+	if o.Retention <= 0 {
+		o.Retention = 7 * 24 * time.Hour
+	}
+	if o.CleanupInterval <= 0 {
+		o.CleanupInterval = time.Hour
+	}
+	if o.Lease <= 0 {
+		o.Lease = time.Minute
+	}
+	return o
+}
+
+// Relay publishes pending outbox rows. Messages of one aggregate are delivered strictly
+// in insertion order: a message waits while an older one of the same aggregate is still
+// pending, so a failing message holds back its successors until it is delivered or it
+// runs out of attempts and is marked failed.
+type Relay struct {
+	db        *gorm.DB
+	publisher Publisher
+	opts      RelayOptions
+	logger    *logger.AppLogger
+	now       func() time.Time
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewRelay(db *gorm.DB, publisher Publisher, opts RelayOptions, logger *logger.AppLogger) *Relay {
+	return &Relay{
+		db:        db,
+		publisher: publisher,
+		opts:      opts.withDefaults(),
+		logger:    logger.WithScope(Relay{}),
+		now:       time.Now,
+		stop:      make(chan struct{}),
+	}
+}
+
+func (r *Relay) Start() {
+	r.wg.Add(1)
+	go r.run()
+}
+
+func (r *Relay) Stop(ctx context.Context) error {
+	close(r.stop)
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *Relay) run() {
+	defer r.wg.Done()
+
+	ctx, cancel := context.WithCancel(tenancy.WithoutTenantScope(context.Background()))
+	defer cancel()
+	go func() {
+		<-r.stop
+		cancel()
+	}()
+
+	poll := time.NewTicker(r.opts.PollInterval)
+	defer poll.Stop()
+	cleanup := time.NewTicker(r.opts.CleanupInterval)
+	defer cleanup.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-poll.C:
+			for {
+				n, err := r.RelayBatch(ctx)
+				if err != nil {
+					r.logger.Error("outbox relay failed", err)
+					break
+				}
+				if n < r.opts.BatchSize {
+					break
+				}
+			}
+		case <-cleanup.C:
+			if _, err := r.Cleanup(ctx); err != nil {
+				r.logger.Error("outbox cleanup failed", err)
+			}
+		}
+	}
+}
+
+// RelayBatch publishes up to BatchSize due messages and returns how many it picked up.
+// The messages are claimed for Lease in a short transaction and published outside of
+// it, so a slow broker holds no connection nor row lock.
+func (r *Relay) RelayBatch(ctx context.Context) (int, error) {
+	batch, err := r.claim(ctx)
+	if err != nil || len(batch) == 0 {
+		return 0, err
+	}
+
+	blocked := make(map[string]bool)
+	for i := range batch {
+		msg := &batch[i]
+		aggregate := msg.AggregateType + ":" + msg.AggregateID
+		if blocked[aggregate] {
+			continue
+		}
+
+		if err := r.publisher.Publish(ctx, msg.toMessage()); err != nil {
+			blocked[aggregate] = true
+			if err := r.markFailedAttempt(ctx, msg, err); err != nil {
+				return len(batch), err
+			}
+			continue
+		}
+
+		err := r.settle(ctx, msg, map[string]interface{}{
+			"status":       StatusDelivered.Value,
+			"attempts":     msg.Attempts + 1,
+			"published_at": r.now(),
+			"last_error":   "",
+		})
+		if err != nil {
+			return len(batch), err
+		}
+	}
+	return len(batch), nil
+}
+
+// claim picks the due messages whose aggregate has no older one pending and leases them.
+func (r *Relay) claim(ctx context.Context) ([]MessageModel, error) {
+	var batch []MessageModel
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := r.now()
+		err := tx.
+			Where("status = ? AND next_attempt_at <= ?", StatusPending.Value, now).
+			Where("(locked_until IS NULL OR locked_until <= ?)", now).
+			Where(`NOT EXISTS (SELECT 1 FROM outbox_messages earlier
+				WHERE earlier.aggregate_type = outbox_messages.aggregate_type
+				AND earlier.aggregate_id = outbox_messages.aggregate_id
+				AND earlier.status = ? AND earlier.id < outbox_messages.id)`, StatusPending.Value).
+			Order("id").
+			Limit(r.opts.BatchSize).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Find(&batch).Error
+		if err != nil || len(batch) == 0 {
+			return err
+		}
+
+		ids := lo.Map(batch, func(msg MessageModel, _ int) uint64 { return msg.ID })
+		return tx.Model(&MessageModel{}).Where("id IN ?", ids).Update("locked_until", now.Add(r.opts.Lease)).Error
+	})
+	return batch, err
+}
+
+// settle records the outcome of a publish and releases the lease. A message another
+// instance delivered after the lease ran out is left alone.
+func (r *Relay) settle(ctx context.Context, msg *MessageModel, updates map[string]interface{}) error {
+	updates["locked_until"] = nil
+	return r.db.WithContext(ctx).Model(&MessageModel{}).
+		Where("id = ? AND status = ?", msg.ID, StatusPending.Value).
+		Updates(updates).Error
+}
+
+func (r *Relay) markFailedAttempt(ctx context.Context, msg *MessageModel, cause error) error {
+	attempts := msg.Attempts + 1
+	status := StatusPending
+	if attempts >= r.opts.MaxAttempts {
+		status = StatusFailed
+		r.logger.Error("outbox message {} gave up after {} attempts", cause, msg.ID, attempts)
+	}
+
+	return r.settle(ctx, msg, map[string]interface{}{
+		"status":          status.Value,
+		"attempts":        attempts,
+		"next_attempt_at": r.now().Add(r.backoff(attempts)),
+		"last_error":      cause.Error(),
+	})
+}
+
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.opts.RetryBackoff
+	for i := 1; i < attempts && delay < r.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, r.opts.MaxBackoff)
+}
+
+// Cleanup deletes delivered messages older than the retention window.
+func (r *Relay) Cleanup(ctx context.Context) (int64, error) {
+	tx := r.db.WithContext(ctx).
+		Where("status = ? AND published_at < ?", StatusDelivered.Value, r.now().Add(-r.opts.Retention)).
+		Delete(&MessageModel{})
+	return tx.RowsAffected, tx.Error
+}
@@ -0,0 +1,242 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/infrastructure/migration"
+	"agentic/commerce/migrations"
+	"agentic/commerce/pkg/logger"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+var errBroker = errors.New("broker unavailable")
+
+// newRelay runs the relay on a migrated SQLite database and a clock the test moves.
+func newRelay(t *testing.T, publisher Publisher, opts RelayOptions) (*Relay, *gorm.DB, *time.Time) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	all, err := migrations.All("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migration.NewMigrator(db, all).Up(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	appLogger := logger.NewAppLogger(&config.Config{Mode: config.ModeDev, Logger: &config.Logger{Level: config.LevelWarn}})
+	relay := NewRelay(db, publisher, opts, appLogger)
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	relay.now = func() time.Time { return now }
+	return relay, db, &now
+}
+
+func enqueue(t *testing.T, db *gorm.DB, at time.Time, aggregateID, eventType string) {
+	t.Helper()
+	err := db.Create(&MessageModel{
+		AggregateType: "order",
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       map[string]interface{}{},
+		Status:        StatusPending,
+		NextAttemptAt: at,
+		CreatedAt:     at,
+	}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func message(t *testing.T, db *gorm.DB, eventType string) MessageModel {
+	t.Helper()
+	var msg MessageModel
+	if err := db.Where("event_type = ?", eventType).First(&msg).Error; err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+// recorder publishes through an InMemoryPublisher, failing the event types in failing.
+func recorder(failing map[string]bool) (*InMemoryPublisher, *[]string) {
+	publisher := NewInMemoryPublisher()
+	var published []string
+	publisher.Subscribe(func(_ context.Context, msg Message) error {
+		if failing[msg.EventType] {
+			return errBroker
+		}
+		published = append(published, msg.EventType)
+		return nil
+	})
+	return publisher, &published
+}
+
+func TestRelayKeepsTheOrderOfAnAggregate(t *testing.T) {
+	failing := map[string]bool{"a1": true}
+	publisher, published := recorder(failing)
+	relay, db, now := newRelay(t, publisher, RelayOptions{RetryBackoff: time.Second})
+	enqueue(t, db, *now, "a", "a1")
+	enqueue(t, db, *now, "a", "a2")
+	enqueue(t, db, *now, "b", "b1")
+
+	if _, err := relay.RelayBatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(*published) != 1 || (*published)[0] != "b1" {
+		t.Fatalf("published %v, a2 must wait for a1", *published)
+	}
+	if msg := message(t, db, "a2"); msg.Status != StatusPending || msg.Attempts != 0 {
+		t.Fatalf("a2 was tried while a1 was pending: %+v", msg)
+	}
+
+	delete(failing, "a1")
+	*now = now.Add(time.Second)
+	for range 2 {
+		if _, err := relay.RelayBatch(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(*published) != 3 || (*published)[1] != "a1" || (*published)[2] != "a2" {
+		t.Fatalf("published %v", *published)
+	}
+	for _, eventType := range []string{"a1", "a2", "b1"} {
+		msg := message(t, db, eventType)
+		if msg.Status != StatusDelivered || msg.PublishedAt == nil || msg.LockedUntil != nil {
+			t.Fatalf("%s wasn't settled: %+v", eventType, msg)
+		}
+	}
+}
+
+func TestRelayBacksOffAFailingMessage(t *testing.T) {
+	publisher, published := recorder(map[string]bool{"a1": true})
+	relay, db, now := newRelay(t, publisher, RelayOptions{RetryBackoff: time.Second, MaxBackoff: 5 * time.Second})
+	enqueue(t, db, *now, "a", "a1")
+
+	for attempt, delay := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if n, err := relay.RelayBatch(context.Background()); err != nil || n != 1 {
+			t.Fatalf("attempt %d picked %d messages: %v", attempt+1, n, err)
+		}
+		msg := message(t, db, "a1")
+		if msg.Attempts != attempt+1 || !msg.NextAttemptAt.Equal(now.Add(delay)) || msg.LastError != errBroker.Error() {
+			t.Fatalf("attempt %d left %+v, want a retry in %s", attempt+1, msg, delay)
+		}
+		if n, err := relay.RelayBatch(context.Background()); err != nil || n != 0 {
+			t.Fatalf("a1 was retried before its backoff: %d, %v", n, err)
+		}
+		*now = now.Add(delay)
+	}
+	if len(*published) != 0 {
+		t.Fatalf("published %v", *published)
+	}
+}
+
+func TestRelayMarksAMessageFailedAfterItsLastAttempt(t *testing.T) {
+	publisher, published := recorder(map[string]bool{"a1": true})
+	relay, db, now := newRelay(t, publisher, RelayOptions{MaxAttempts: 2, RetryBackoff: time.Second})
+	enqueue(t, db, *now, "a", "a1")
+	enqueue(t, db, *now, "a", "a2")
+
+	for range 2 {
+		if _, err := relay.RelayBatch(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		*now = now.Add(time.Minute)
+	}
+	if msg := message(t, db, "a1"); msg.Status != StatusFailed || msg.Attempts != 2 {
+		t.Fatalf("a1 wasn't marked failed: %+v", msg)
+	}
+
+	// a failed message no longer holds back the rest of its aggregate
+	if _, err := relay.RelayBatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(*published) != 1 || (*published)[0] != "a2" {
+		t.Fatalf("published %v", *published)
+	}
+}
+
+func TestRelayLeasesTheMessagesItPublishes(t *testing.T) {
+	publisher := NewInMemoryPublisher()
+	relay, db, now := newRelay(t, publisher, RelayOptions{Lease: time.Minute})
+	enqueue(t, db, *now, "a", "a1")
+
+	var during int
+	publisher.Subscribe(func(ctx context.Context, _ Message) error {
+		// a second relay polling meanwhile finds nothing to take
+		n, err := relay.claim(ctx)
+		during = len(n)
+		if err != nil {
+			return err
+		}
+		return errBroker
+	})
+	if _, err := relay.RelayBatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if during != 0 {
+		t.Fatalf("a leased message was claimed again")
+	}
+	if msg := message(t, db, "a1"); msg.LockedUntil != nil {
+		t.Fatalf("the lease outlived the attempt: %+v", msg)
+	}
+
+	// a relay that died mid-publish leaves a lease the next one waits out
+	if err := db.Model(&MessageModel{}).Where("event_type = ?", "a1").
+		Updates(map[string]interface{}{"next_attempt_at": *now, "locked_until": now.Add(time.Minute)}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if batch, err := relay.claim(context.Background()); err != nil || len(batch) != 0 {
+		t.Fatalf("claimed %d leased messages: %v", len(batch), err)
+	}
+	*now = now.Add(time.Minute)
+	if batch, err := relay.claim(context.Background()); err != nil || len(batch) != 1 {
+		t.Fatalf("claimed %d messages past their lease: %v", len(batch), err)
+	}
+}
+
+func TestRelayCleansUpDeliveredMessagesPastRetention(t *testing.T) {
+	publisher, _ := recorder(map[string]bool{"stuck": true})
+	relay, db, now := newRelay(t, publisher, RelayOptions{MaxAttempts: 1, Retention: time.Hour})
+	enqueue(t, db, *now, "a", "old")
+	enqueue(t, db, *now, "b", "stuck")
+	if _, err := relay.RelayBatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	*now = now.Add(30 * time.Minute)
+	enqueue(t, db, *now, "c", "recent")
+	enqueue(t, db, *now, "d", "pending")
+	if err := db.Model(&MessageModel{}).Where("event_type = ?", "pending").Update("next_attempt_at", now.Add(time.Hour)).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := relay.RelayBatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	*now = now.Add(45 * time.Minute)
+	deleted, err := relay.Cleanup(context.Background())
+	if err != nil || deleted != 1 {
+		t.Fatalf("cleaned up %d messages: %v", deleted, err)
+	}
+	var left []string
+	if err := db.Model(&MessageModel{}).Order("id").Pluck("event_type", &left).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 3 || left[0] != "stuck" || left[1] != "recent" || left[2] != "pending" {
+		t.Fatalf("left %v, only the delivered message past retention goes", left)
+	}
+}
@@ -0,0 +1,37 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"agentic/commerce/internal/infrastructure/database"
+)
+
+// IOutbox enqueues events. Call it inside ITransactionManager.RunInTx so the event is
+// stored atomically with the change that caused it.
+type IOutbox interface {
+	Add(ctx context.Context, aggregateType, aggregateID, eventType string, payload map[string]interface{}) error
+}
+
+type store struct {
+	database database.GormDB
+}
+
+func NewOutbox(database database.GormDB) IOutbox {
+	return &store{
+		database: database,
+	}
+}
+
+func (s *store) Add(ctx context.Context, aggregateType, aggregateID, eventType string, payload map[string]interface{}) error {
+	now := time.Now()
+	return s.database(ctx).Create(&MessageModel{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        StatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}).Error
+}
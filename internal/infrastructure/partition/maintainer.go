@@ -0,0 +1,358 @@
+package partition
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"agentic/commerce/internal/infrastructure/database"
+	"agentic/commerce/internal/infrastructure/tenancy"
+	"agentic/commerce/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// lockTimeout bounds the wait for the locks ATTACH and DETACH take; queries queue up
+// behind a waiting ACCESS EXCLUSIVE request, so the step gives up and the next run
+// tries again instead.
+const lockTimeout = 5 * time.Second
+
+type Options struct {
+	Interval time.Duration
+	// Premake is how many months ahead of the current one get a partition.
+	Premake int
+	// Retention is how many months before the current one stay attached, zero keeps
+	// everything.
+	Retention int
+	// ArchiveSchema receives the expired partitions once detached.
+	ArchiveSchema string
+	// Drop deletes the expired partitions instead.
+	Drop bool
+}
+
+func (o Options) withDefaults() Options {
+	if o.Interval <= 0 {
+		o.Interval = 6 * time.Hour
+	}
+	if o.Premake <= 0 {
+		o.Premake = 3
+	}
+	if o.Retention < 0 {
+		o.Retention = 0
+	}
+	return o
+}
+
+// Report is what one run changed on a table.
+type Report struct {
+	Table    string
+	Created  []string
+	Detached []string
+	Dropped  []string
+}
+
+// Maintainer runs Maintain at startup and then every Interval.
+type Maintainer struct {
+	db     *gorm.DB
+	tables []Table
+	opts   Options
+	logger *logger.AppLogger
+	now    func() time.Time
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewMaintainer(db *gorm.DB, tables []Table, opts Options, logger *logger.AppLogger) *Maintainer {
+	return &Maintainer{
+		db:     db,
+		tables: tables,
+		opts:   opts.withDefaults(),
+		logger: logger.WithScope(Maintainer{}),
+		now:    time.Now,
+		stop:   make(chan struct{}),
+	}
+}
+
+func (m *Maintainer) Start() {
+	m.wg.Add(1)
+	go m.run()
+}
+
+func (m *Maintainer) Stop(ctx context.Context) error {
+	close(m.stop)
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *Maintainer) run() {
+	defer m.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-m.stop
+		cancel()
+	}()
+
+	ticker := time.NewTicker(m.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		reports, err := m.Maintain(ctx)
+		if err != nil && ctx.Err() == nil {
+			m.logger.Error("partition maintenance failed", err)
+		}
+		for _, report := range reports {
+			if len(report.Created)+len(report.Detached)+len(report.Dropped) > 0 {
+				m.logger.Info("partitions of {}: created {}, detached {}, dropped {}",
+					report.Table, report.Created, report.Detached, report.Dropped)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Maintain gives every registered table a partition for the current month and the
+// Premake months after it, moving their rows out of the default partition, then
+// detaches the partitions older than Retention. Tables that aren't partitioned, which
+// is all of them on MySQL and SQLite, are skipped. Each step is a transaction of its
+// own, serialized across instances by an advisory lock.
+func (m *Maintainer) Maintain(ctx context.Context) ([]Report, error) {
+	if m.db.Dialector.Name() != "postgres" {
+		return nil, nil
+	}
+	ctx = database.WithStatementTimeout(database.WithPrimary(tenancy.WithoutTenantScope(ctx)), 0)
+
+	var reports []Report
+	for _, table := range m.tables {
+		name, err := m.tableName(table)
+		if err != nil {
+			return reports, err
+		}
+		partitioned, err := IsPartitioned(ctx, m.db, name)
+		if err != nil {
+			return reports, err
+		}
+		if !partitioned {
+			continue
+		}
+		report, err := m.maintainTable(ctx, name, table.Column)
+		reports = append(reports, *report)
+		if err != nil {
+			return reports, fmt.Errorf("cannot maintain the partitions of %s: %w", name, err)
+		}
+	}
+	return reports, nil
+}
+
+// Partitions lists the partitions of every registered table that is partitioned.
+func (m *Maintainer) Partitions(ctx context.Context) (map[string][]Partition, error) {
+	result := make(map[string][]Partition)
+	if m.db.Dialector.Name() != "postgres" {
+		return result, nil
+	}
+	ctx = database.WithPrimary(tenancy.WithoutTenantScope(ctx))
+	for _, table := range m.tables {
+		name, err := m.tableName(table)
+		if err != nil {
+			return nil, err
+		}
+		partitioned, err := IsPartitioned(ctx, m.db, name)
+		if err != nil {
+			return nil, err
+		}
+		if !partitioned {
+			continue
+		}
+		if result[name], err = List(ctx, m.db, name); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (m *Maintainer) tableName(table Table) (string, error) {
+	stmt := &gorm.Statement{DB: m.db}
+	if err := stmt.Parse(table.Model); err != nil {
+		return "", fmt.Errorf("cannot parse %T: %w", table.Model, err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+func (m *Maintainer) maintainTable(ctx context.Context, table, column string) (*Report, error) {
+	report := &Report{Table: table}
+
+	partitions, err := List(ctx, m.db, table)
+	if err != nil {
+		return report, err
+	}
+	attached := make(map[string]bool, len(partitions))
+	hasDefault := false
+	for _, partition := range partitions {
+		attached[partition.Name] = true
+		hasDefault = hasDefault || partition.Default
+	}
+
+	current := MonthOf(m.now())
+	for i := 0; i <= m.opts.Premake; i++ {
+		month := current.AddDate(0, i, 0)
+		name := Name(table, month)
+		if attached[name] {
+			continue
+		}
+		created, err := m.create(ctx, table, column, name, month, hasDefault)
+		if err != nil {
+			return report, fmt.Errorf("cannot create %s: %w", name, err)
+		}
+		if created {
+			report.Created = append(report.Created, name)
+		}
+	}
+
+	if m.opts.Retention == 0 {
+		return report, nil
+	}
+	cutoff := current.AddDate(0, -m.opts.Retention, 0)
+	for _, partition := range partitions {
+		if partition.Month.IsZero() || !partition.Month.Before(cutoff) {
+			continue
+		}
+		detached, err := m.expire(ctx, table, partition.Name)
+		if err != nil {
+			return report, fmt.Errorf("cannot detach %s: %w", partition.Name, err)
+		}
+		if !detached {
+			continue
+		}
+		if m.opts.Drop {
+			report.Dropped = append(report.Dropped, partition.Name)
+		} else {
+			report.Detached = append(report.Detached, partition.Name)
+		}
+	}
+	return report, nil
+}
+
+// create builds the partition as a plain table and attaches it. The rows of its month
+// would make the attach fail while in the default partition, so they are parked in a
+// temporary table and inserted back through the parent once attached: leaving and
+// entering through the parent fires its row triggers both ways, which keep the claims of
+// meta_data_uuids in step. It reports false when another instance got there first.
+func (m *Maintainer) create(ctx context.Context, table, column, name string, month time.Time, hasDefault bool) (bool, error) {
+	from, to := month, month.AddDate(0, 1, 0)
+	return m.step(ctx, table, name, false, func(tx *gorm.DB) error {
+		err := tx.Exec("CREATE TABLE IF NOT EXISTS ? (LIKE ? INCLUDING DEFAULTS INCLUDING CONSTRAINTS)",
+			clause.Table{Name: name}, clause.Table{Name: table}).Error
+		if err != nil {
+			return err
+		}
+		parked := name + "_moving"
+		if hasDefault {
+			err := tx.Exec("CREATE TEMPORARY TABLE ? (LIKE ?) ON COMMIT DROP",
+				clause.Table{Name: parked}, clause.Table{Name: table}).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Exec("WITH moved AS (DELETE FROM ? WHERE ? >= ? AND ? < ? RETURNING *) INSERT INTO ? SELECT * FROM moved",
+				clause.Table{Name: DefaultName(table)}, clause.Column{Name: column}, from, clause.Column{Name: column}, to,
+				clause.Table{Name: parked}).Error
+			if err != nil {
+				return err
+			}
+		}
+		// partition bounds are literals, DDL takes no parameters
+		err = tx.Exec(fmt.Sprintf("ALTER TABLE ? ATTACH PARTITION ? FOR VALUES FROM (%s) TO (%s)", bound(from), bound(to)),
+			clause.Table{Name: table}, clause.Table{Name: name}).Error
+		if err != nil || !hasDefault {
+			return err
+		}
+		return tx.Exec("INSERT INTO ? SELECT * FROM ?", clause.Table{Name: table}, clause.Table{Name: parked}).Error
+	})
+}
+
+// expire detaches the partition, then drops it or moves it to the archive schema. A
+// partition about to be dropped is emptied through the parent's row triggers first, a
+// DROP TABLE fires none and would leave its uuids claimed in meta_data_uuids; archived
+// partitions keep theirs so they can be attached back. It reports false when another
+// instance got there first.
+func (m *Maintainer) expire(ctx context.Context, table, name string) (bool, error) {
+	return m.step(ctx, table, name, true, func(tx *gorm.DB) error {
+		if m.opts.Drop {
+			if err := tx.Exec("DELETE FROM ?", clause.Table{Name: name}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Exec("ALTER TABLE ? DETACH PARTITION ?", clause.Table{Name: table}, clause.Table{Name: name}).Error; err != nil {
+			return err
+		}
+		switch {
+		case m.opts.Drop:
+			return tx.Exec("DROP TABLE ?", clause.Table{Name: name}).Error
+		case m.opts.ArchiveSchema != "":
+			if err := tx.Exec("CREATE SCHEMA IF NOT EXISTS ?", clause.Table{Name: m.opts.ArchiveSchema}).Error; err != nil {
+				return err
+			}
+			return tx.Exec("ALTER TABLE ? SET SCHEMA ?", clause.Table{Name: name}, clause.Table{Name: m.opts.ArchiveSchema}).Error
+		}
+		return nil
+	})
+}
+
+// step runs fn in a transaction holding the advisory lock of table, so two instances
+// don't change the same partitions at once, if partition is still attached as expected
+// once the lock is held.
+func (m *Maintainer) step(ctx context.Context, table, partition string, attached bool, fn func(tx *gorm.DB) error) (bool, error) {
+	ran := false
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockKey(table)).Error; err != nil {
+			return err
+		}
+		var count int64
+		err := tx.Raw("SELECT count(*) FROM pg_inherits WHERE inhrelid = to_regclass(?) AND inhparent = to_regclass(?)", partition, table).
+			Scan(&count).Error
+		if err != nil || (count > 0) != attached {
+			return err
+		}
+
+		err = tx.Exec(fmt.Sprintf("SET LOCAL lock_timeout = %d", lockTimeout.Milliseconds())).Error
+		if err == nil {
+			err = tx.Exec("SET LOCAL statement_timeout = 0").Error
+		}
+		if err != nil {
+			return err
+		}
+		ran = true
+		return fn(tx)
+	})
+	return ran && err == nil, err
+}
+
+func bound(t time.Time) string {
+	return "'" + t.UTC().Format("2006-01-02 15:04:05") + "+00'"
+}
+
+func lockKey(table string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte("partition:" + table))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,111 @@
+package partition_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/domains/metadata"
+	"agentic/commerce/internal/infrastructure/migration"
+	"agentic/commerce/internal/infrastructure/partition"
+	"agentic/commerce/internal/testkit"
+	"agentic/commerce/migrations"
+	"agentic/commerce/pkg/logger"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// newMaintainer migrates a Postgres schema of its own, which partitions meta_data_models
+// and claims its uuids in meta_data_uuids.
+func newMaintainer(t *testing.T, opts partition.Options) (*partition.Maintainer, *gorm.DB) {
+	t.Helper()
+	db := testkit.Postgres(t)
+	all, err := migrations.All("postgres")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migration.NewMigrator(db, all).Up(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+	appLogger := logger.NewAppLogger(&config.Config{Mode: config.ModeDev, Logger: &config.Logger{Level: config.LevelWarn}})
+	tables := []partition.Table{{Model: &metadata.MetaDataModel{}, Column: "created_at"}}
+	return partition.NewMaintainer(db, tables, opts, appLogger), db
+}
+
+func insertPost(db *gorm.DB, id string, createdAt time.Time) error {
+	return db.Exec("INSERT INTO meta_data_models (created_at, uuid, user_id) VALUES (?, ?, 1)", createdAt, id).Error
+}
+
+func claimed(t *testing.T, db *gorm.DB, id string) bool {
+	t.Helper()
+	var count int64
+	if err := db.Raw("SELECT count(*) FROM meta_data_uuids WHERE uuid = ?", id).Scan(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count > 0
+}
+
+func TestMaintainerKeepsTheClaimsOfMovedRows(t *testing.T) {
+	maintainer, db := newMaintainer(t, partition.Options{Premake: 3})
+
+	// the migration creates two months ahead, the third lands in the default partition
+	month := partition.MonthOf(time.Now()).AddDate(0, 3, 0)
+	moved := uuid.NewString()
+	if err := insertPost(db, moved, month.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	reports, err := maintainer.Maintain(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 || len(reports[0].Created) != 1 || reports[0].Created[0] != partition.Name("meta_data_models", month) {
+		t.Fatalf("maintenance reported %+v", reports)
+	}
+
+	var left int64
+	if err := db.Raw("SELECT count(*) FROM meta_data_models_default").Scan(&left).Error; err != nil || left != 0 {
+		t.Fatalf("%d rows left in the default partition: %v", left, err)
+	}
+	if !claimed(t, db, moved) {
+		t.Fatal("the moved row lost its uuid claim")
+	}
+	// another month routes the copy to another partition, only the claim catches it
+	if err := insertPost(db, moved, time.Now()); err == nil {
+		t.Fatal("a moved uuid was inserted twice")
+	}
+}
+
+func TestMaintainerReleasesTheClaimsOfDroppedPartitions(t *testing.T) {
+	maintainer, db := newMaintainer(t, partition.Options{Premake: 2, Retention: 1, Drop: true})
+
+	month := partition.MonthOf(time.Now()).AddDate(0, -6, 0)
+	name := partition.Name("meta_data_models", month)
+	err := db.Exec(fmt.Sprintf("CREATE TABLE %s PARTITION OF meta_data_models FOR VALUES FROM ('%s') TO ('%s')",
+		name, month.Format(time.RFC3339), month.AddDate(0, 1, 0).Format(time.RFC3339))).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	dropped := uuid.NewString()
+	if err := insertPost(db, dropped, month.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	reports, err := maintainer.Maintain(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 || len(reports[0].Dropped) != 1 || reports[0].Dropped[0] != name {
+		t.Fatalf("maintenance reported %+v", reports)
+	}
+
+	if claimed(t, db, dropped) {
+		t.Fatal("the dropped row kept its uuid claim")
+	}
+	if err := insertPost(db, dropped, time.Now()); err != nil {
+		t.Fatalf("the uuid of a dropped row couldn't be used again: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+// Package partition keeps Postgres tables range partitioned by month in shape: it creates
+// the partitions of the months ahead and detaches, archives or drops the expired ones.
+// A partition is named <table>_YYYY_MM and covers one UTC calendar month; rows no
+// partition covers land in <table>_default. The partitioned table itself is created by a
+// migration, see migrations/postgres/*_partition_metadata.up.sql.
+package partition
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+const TABLE_GROUP_NAME = "partitioned-table"
+
+// Table is the table of Model, partitioned by month on Column.
+type Table struct {
+	Model  interface{}
+	Column string
+}
+
+type Registry struct {
+	fx.In
+	Tables []Table `group:"partitioned-table"`
+}
+
+// AsMonthly registers the table of model, partitioned by month on column, with the
+// maintainer.
+func AsMonthly(model interface{}, column string) fx.Option {
+	return fx.Provide(fx.Annotate(
+		func() Table { return Table{Model: model, Column: column} },
+		fx.ResultTags(`group:"`+TABLE_GROUP_NAME+`"`),
+	))
+}
+
+// Partition is one partition attached to a table.
+type Partition struct {
+	Name string
+	// Month is the first instant of the month the partition covers, zero for the
+	// default partition and partitions not named after a month.
+	Month   time.Time
+	Default bool
+	// Rows is the planner's estimate, zero until the partition is analyzed.
+	Rows int64
+}
+
+// Name is the partition of table for the month of t.
+func Name(table string, t time.Time) string {
+	return fmt.Sprintf("%s_%s", table, MonthOf(t).Format("2006_01"))
+}
+
+// DefaultName is the default partition of table.
+func DefaultName(table string) string {
+	return table + "_default"
+}
+
+// MonthOf returns the first instant of the UTC month of t.
+func MonthOf(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// IsPartitioned reports whether table is a partitioned table; always false on MySQL
+// and SQLite.
+func IsPartitioned(ctx context.Context, db *gorm.DB, table string) (bool, error) {
+	if db.Dialector.Name() != "postgres" {
+		return false, nil
+	}
+	var count int64
+	err := db.WithContext(ctx).Raw(`SELECT count(*) FROM pg_partitioned_table p
+		JOIN pg_class c ON c.oid = p.partrelid
+		WHERE c.relname = ? AND c.relnamespace = current_schema()::regnamespace`, table).
+		Scan(&count).Error
+	return count > 0, err
+}
+
+// List returns the partitions attached to table ordered by month, the default
+// partition and the ones not named after a month last.
+func List(ctx context.Context, db *gorm.DB, table string) ([]Partition, error) {
+	var rows []struct {
+		Name string
+		Rows int64
+	}
+	err := db.WithContext(ctx).Raw(`SELECT c.relname AS name, GREATEST(c.reltuples, 0)::bigint AS rows
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_class p ON p.oid = i.inhparent
+		WHERE p.relname = ? AND p.relnamespace = current_schema()::regnamespace`, table).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	monthly := regexp.MustCompile(`^` + regexp.QuoteMeta(table) + `_(\d{4}_\d{2})$`)
+	partitions := make([]Partition, 0, len(rows))
+	for _, row := range rows {
+		partition := Partition{Name: row.Name, Rows: row.Rows, Default: row.Name == DefaultName(table)}
+		if match := monthly.FindStringSubmatch(row.Name); match != nil {
+			if month, err := time.Parse("2006_01", match[1]); err == nil {
+				partition.Month = month
+			}
+		}
+		partitions = append(partitions, partition)
+	}
+	sort.SliceStable(partitions, func(i, j int) bool {
+		a, b := partitions[i], partitions[j]
+		if a.Month.IsZero() != b.Month.IsZero() {
+			return !a.Month.IsZero()
+		}
+		if !a.Month.Equal(b.Month) {
+			return a.Month.Before(b.Month)
+		}
+		return a.Name < b.Name
+	})
+	return partitions, nil
+}
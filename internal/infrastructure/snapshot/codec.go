@@ -0,0 +1,99 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+
+	"agentic/commerce/internal/infrastructure/database"
+
+	"github.com/go-json-experiment/json/v1"
+	"gorm.io/gorm/schema"
+)
+
+// columns are the fields of s stored in the database, in declaration order.
+func columns(s *schema.Schema) []*schema.Field {
+	fields := make([]*schema.Field, 0, len(s.Fields))
+	for _, field := range s.Fields {
+		if field.DBName != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func columnNames(fields []*schema.Field) []string {
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = field.DBName
+	}
+	return names
+}
+
+// encodeRow writes rv as one JSON object keyed by column name. Values are the JSON form
+// of the Go fields, enum members are written as their plain value.
+func encodeRow(ctx context.Context, buf *bytes.Buffer, fields []*schema.Field, rv reflect.Value) error {
+	buf.WriteByte('{')
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(field.DBName)
+		if err != nil {
+			return err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+
+		value := field.ReflectValueOf(ctx, rv)
+		if member, ok := enumValue(value); ok {
+			value = member
+		}
+		raw, err := json.Marshal(value.Interface())
+		if err != nil {
+			return fmt.Errorf("cannot encode column %s: %w", field.DBName, err)
+		}
+		buf.Write(raw)
+	}
+	buf.WriteString("}\n")
+	return nil
+}
+
+// decodeRow is the reverse of encodeRow. Columns missing from the line keep their zero
+// value; columns the model doesn't have are an error.
+func decodeRow(ctx context.Context, line []byte, byColumn map[string]*schema.Field, rv reflect.Value) error {
+	var row map[string]json.RawMessage
+	if err := json.Unmarshal(line, &row); err != nil {
+		return err
+	}
+	for column, raw := range row {
+		field, ok := byColumn[column]
+		if !ok {
+			return fmt.Errorf("unknown column %s", column)
+		}
+		value := field.ReflectValueOf(ctx, rv)
+		target := value
+		if member, ok := enumValue(value); ok {
+			target = member
+		}
+		if err := json.Unmarshal(raw, target.Addr().Interface()); err != nil {
+			return fmt.Errorf("cannot decode column %s: %w", column, err)
+		}
+		if enumType, ok := database.LookupEnum(value.Type()); ok && !target.IsZero() && !enumType.Contains(target.Interface()) {
+			return fmt.Errorf("column %s: %w %v", column, database.ErrUnknownEnumValue, target.Interface())
+		}
+	}
+	return nil
+}
+
+// enumValue returns the Value field of a registered enum member.
+func enumValue(value reflect.Value) (reflect.Value, bool) {
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	if _, ok := database.LookupEnum(value.Type()); !ok {
+		return reflect.Value{}, false
+	}
+	return value.Field(0), true
+}
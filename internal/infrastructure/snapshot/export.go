@@ -0,0 +1,165 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"agentic/commerce/internal/infrastructure/tenancy"
+
+	"github.com/go-json-experiment/json/v1"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Export writes every row of models, soft-deleted ones and those of every tenant
+// included, to w. The tables are read in one read-only transaction so the archive is a
+// consistent picture of the database. Rows are staged in temporary files since the
+// manifest, with the counts and checksums, comes first in the archive.
+func Export(ctx context.Context, db *gorm.DB, w io.Writer, models []interface{}, opts Options) (*Manifest, error) {
+	ctx = tenancy.WithoutTenantScope(ctx)
+
+	schemas, err := Order(db, models...)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "snapshot-export-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	manifest := &Manifest{
+		FormatVersion: FormatVersion,
+		SchemaVersion: opts.SchemaVersion,
+		Dialect:       db.Dialector.Name(),
+		CreatedAt:     time.Now().UTC(),
+	}
+
+	txOptions := &sql.TxOptions{ReadOnly: true, Isolation: sql.LevelRepeatableRead}
+	if db.Dialector.Name() == "sqlite" {
+		// SQLite transactions are always serializable and the driver refuses other levels
+		txOptions.Isolation = sql.LevelDefault
+	}
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, s := range schemas {
+			table, err := exportTable(ctx, tx, s, filepath.Join(dir, s.Table+".ndjson"), opts.batchSize())
+			if err != nil {
+				return fmt.Errorf("cannot export %s: %w", s.Table, err)
+			}
+			manifest.Tables = append(manifest.Tables, *table)
+		}
+		return nil
+	}, txOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeArchive(w, dir, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func exportTable(ctx context.Context, tx *gorm.DB, s *schema.Schema, path string, batchSize int) (*TableManifest, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	out := bufio.NewWriter(io.MultiWriter(file, hash))
+	fields := columns(s)
+	table := &TableManifest{
+		Table:   s.Table,
+		Model:   s.ModelType.String(),
+		File:    filepath.Base(path),
+		Columns: columnNames(fields),
+	}
+
+	var buf bytes.Buffer
+	rows := reflect.New(reflect.SliceOf(s.ModelType))
+	result := tx.Unscoped().Model(reflect.New(s.ModelType).Interface()).
+		FindInBatches(rows.Interface(), batchSize, func(batch *gorm.DB, _ int) error {
+			slice := rows.Elem()
+			for i := 0; i < slice.Len(); i++ {
+				buf.Reset()
+				if err := encodeRow(ctx, &buf, fields, slice.Index(i)); err != nil {
+					return err
+				}
+				if _, err := out.Write(buf.Bytes()); err != nil {
+					return err
+				}
+			}
+			table.Rows += int64(slice.Len())
+			return nil
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if err := out.Flush(); err != nil {
+		return nil, err
+	}
+	table.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return table, file.Close()
+}
+
+func writeArchive(w io.Writer, dir string, manifest *Manifest) error {
+	compressed := gzip.NewWriter(w)
+	archive := tar.NewWriter(compressed)
+
+	raw, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeEntry(archive, ManifestName, int64(len(raw)), manifest.CreatedAt, bytes.NewReader(raw)); err != nil {
+		return err
+	}
+
+	for _, table := range manifest.Tables {
+		file, err := os.Open(filepath.Join(dir, table.File))
+		if err != nil {
+			return err
+		}
+		info, err := file.Stat()
+		if err == nil {
+			err = writeEntry(archive, table.File, info.Size(), manifest.CreatedAt, file)
+		}
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return compressed.Close()
+}
+
+func writeEntry(archive *tar.Writer, name string, size int64, modified time.Time, content io.Reader) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    size,
+		ModTime: modified,
+	}
+	if err := archive.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := io.Copy(archive, content)
+	return err
+}
@@ -0,0 +1,262 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"agentic/commerce/internal/infrastructure/tenancy"
+
+	"github.com/go-json-experiment/json/v1"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Import restores an archive written by Export into a database whose tables of models
+// are all empty. Every file is checked against the manifest before anything is written,
+// then the tables are filled in dependency order in a single transaction, keeping the
+// primary keys, with tenant scoping and model hooks off. Callbacks such as the audit
+// recorder still run unless the context turns them off.
+func Import(ctx context.Context, db *gorm.DB, r io.Reader, models []interface{}, opts Options) (*Manifest, error) {
+	ctx = tenancy.WithoutTenantScope(ctx)
+
+	schemas, err := Order(db, models...)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "snapshot-import-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	manifest, err := extract(r, dir)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.SchemaVersion != opts.SchemaVersion && !opts.AllowSchemaMismatch {
+		return nil, fmt.Errorf("%w: snapshot %q, database %q", ErrSchemaVersion, manifest.SchemaVersion, opts.SchemaVersion)
+	}
+
+	tables := make(map[string]TableManifest, len(manifest.Tables))
+	for _, table := range manifest.Tables {
+		tables[table.Table] = table
+	}
+	known := make(map[string]bool, len(schemas))
+	for _, s := range schemas {
+		known[s.Table] = true
+	}
+	for _, table := range manifest.Tables {
+		if !known[table.Table] {
+			return nil, fmt.Errorf("snapshot has table %s which is not a registered model", table.Table)
+		}
+	}
+
+	for _, s := range schemas {
+		if _, ok := tables[s.Table]; !ok {
+			continue
+		}
+		var found int
+		err := db.WithContext(ctx).Unscoped().Model(reflect.New(s.ModelType).Interface()).
+			Select("1").Limit(1).Find(&found).Error
+		if err != nil {
+			return nil, err
+		}
+		if found > 0 {
+			return nil, fmt.Errorf("%w: %s", ErrNotEmpty, s.Table)
+		}
+	}
+
+	err = db.WithContext(ctx).Session(&gorm.Session{SkipHooks: true}).Transaction(func(tx *gorm.DB) error {
+		for _, s := range schemas {
+			table, ok := tables[s.Table]
+			if !ok {
+				continue
+			}
+			if err := importTable(ctx, tx, s, filepath.Join(dir, table.File), opts.batchSize()); err != nil {
+				return fmt.Errorf("cannot import %s: %w", s.Table, err)
+			}
+			if err := resetSequence(tx, s, table.Rows); err != nil {
+				return fmt.Errorf("cannot reset the id sequence of %s: %w", s.Table, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// extract writes the files of the archive to dir and checks their row counts and
+// checksums against the manifest.
+func extract(r io.Reader, dir string) (*Manifest, error) {
+	compressed, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+	defer compressed.Close()
+	archive := tar.NewReader(compressed)
+
+	header, err := archive.Next()
+	if err != nil || header.Name != ManifestName {
+		return nil, fmt.Errorf("%w: the archive doesn't start with %s", ErrCorrupted, ManifestName)
+	}
+	var manifest Manifest
+	raw, err := io.ReadAll(damageReader{archive})
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+	if manifest.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("unsupported snapshot format %d, this build reads %d", manifest.FormatVersion, FormatVersion)
+	}
+
+	expected := make(map[string]TableManifest, len(manifest.Tables))
+	for _, table := range manifest.Tables {
+		if table.File != filepath.Base(table.File) {
+			return nil, fmt.Errorf("%w: invalid file name %q", ErrCorrupted, table.File)
+		}
+		expected[table.File] = table
+	}
+
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+		}
+		table, ok := expected[header.Name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unexpected file %s", ErrCorrupted, header.Name)
+		}
+		if err := extractTable(damageReader{archive}, filepath.Join(dir, table.File), table); err != nil {
+			return nil, err
+		}
+		delete(expected, header.Name)
+	}
+	for name := range expected {
+		return nil, fmt.Errorf("%w: %s is missing", ErrCorrupted, name)
+	}
+	return &manifest, nil
+}
+
+func extractTable(r io.Reader, path string, table TableManifest) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	lines := &lineCounter{}
+	if _, err := io.Copy(io.MultiWriter(file, hash, lines), r); err != nil {
+		return err
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != table.SHA256 {
+		return fmt.Errorf("%w: checksum of %s is %s, the manifest has %s", ErrCorrupted, table.File, sum, table.SHA256)
+	}
+	if lines.count != table.Rows {
+		return fmt.Errorf("%w: %s has %d rows, the manifest has %d", ErrCorrupted, table.File, lines.count, table.Rows)
+	}
+	return file.Close()
+}
+
+// damageReader reports the read errors of a truncated or damaged archive as ErrCorrupted.
+type damageReader struct {
+	r io.Reader
+}
+
+func (d damageReader) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		err = fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+	return n, err
+}
+
+type lineCounter struct {
+	count int64
+}
+
+func (c *lineCounter) Write(p []byte) (int, error) {
+	c.count += int64(bytes.Count(p, []byte{'\n'}))
+	return len(p), nil
+}
+
+func importTable(ctx context.Context, tx *gorm.DB, s *schema.Schema, path string, batchSize int) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	byColumn := make(map[string]*schema.Field, len(s.Fields))
+	for _, field := range columns(s) {
+		byColumn[field.DBName] = field
+	}
+
+	batch := reflect.MakeSlice(reflect.SliceOf(s.ModelType), 0, batchSize)
+	flush := func() error {
+		if batch.Len() == 0 {
+			return nil
+		}
+		rows := reflect.New(batch.Type())
+		rows.Elem().Set(batch)
+		if err := tx.Create(rows.Interface()).Error; err != nil {
+			return err
+		}
+		batch = batch.Slice(0, 0)
+		return nil
+	}
+
+	reader := bufio.NewReader(file)
+	for line := int64(1); ; line++ {
+		raw, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(raw)) > 0 {
+			batch = reflect.Append(batch, reflect.Zero(s.ModelType))
+			if decodeErr := decodeRow(ctx, raw, byColumn, batch.Index(batch.Len()-1)); decodeErr != nil {
+				return fmt.Errorf("line %d: %w", line, decodeErr)
+			}
+			if batch.Len() == batchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return flush()
+}
+
+// resetSequence moves the Postgres sequence of an auto-increment primary key past the
+// imported ids; MySQL and SQLite do it by themselves on explicit inserts.
+func resetSequence(tx *gorm.DB, s *schema.Schema, rows int64) error {
+	pk := s.PrioritizedPrimaryField
+	if tx.Dialector.Name() != "postgres" || rows == 0 || pk == nil || !pk.AutoIncrement {
+		return nil
+	}
+	return tx.Exec("SELECT setval(pg_get_serial_sequence(?, ?), (SELECT MAX(?) FROM ?))",
+		s.Table, pk.DBName, clause.Column{Name: pk.DBName}, clause.Table{Name: s.Table}).Error
+}
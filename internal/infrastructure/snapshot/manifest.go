@@ -0,0 +1,61 @@
+// Package snapshot takes logical backups of the registered entities: a tar.gz archive
+// with a manifest followed by one NDJSON file per table, keyed by column name so it can
+// be restored into any supported dialect.
+package snapshot
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// FormatVersion changes whenever an older build could no longer read the archives.
+	FormatVersion = 1
+	ManifestName  = "manifest.json"
+)
+
+var (
+	ErrNotEmpty      = errors.New("table is not empty")
+	ErrSchemaVersion = errors.New("snapshot was taken at another schema version")
+	ErrCorrupted     = errors.New("snapshot is corrupted")
+)
+
+// Manifest is the first entry of the archive. Tables are listed in the order they were
+// written, parents before the tables referencing them.
+type Manifest struct {
+	FormatVersion int `json:"format_version"`
+	// SchemaVersion is the last migration applied to the exported database.
+	SchemaVersion string          `json:"schema_version"`
+	Dialect       string          `json:"dialect"`
+	CreatedAt     time.Time       `json:"created_at"`
+	Tables        []TableManifest `json:"tables"`
+}
+
+type TableManifest struct {
+	Table   string   `json:"table"`
+	Model   string   `json:"model"`
+	File    string   `json:"file"`
+	Columns []string `json:"columns"`
+	Rows    int64    `json:"rows"`
+	// SHA256 is the hex digest of the NDJSON file.
+	SHA256 string `json:"sha256"`
+}
+
+// Options are shared by Export and Import; zero values take the defaults.
+type Options struct {
+	// BatchSize is how many rows are read or inserted per statement.
+	BatchSize int
+	// SchemaVersion is the last migration applied to the database. Import refuses an
+	// archive of another version unless AllowSchemaMismatch is set.
+	SchemaVersion       string
+	AllowSchemaMismatch bool
+}
+
+const DefaultBatchSize = 1000
+
+func (o Options) batchSize() int {
+	if o.BatchSize <= 0 {
+		return DefaultBatchSize
+	}
+	return o.BatchSize
+}
@@ -0,0 +1,82 @@
+package snapshot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Order parses models and sorts them so every table comes after the tables it has a
+// foreign key to; unrelated tables are ordered by name.
+func Order(db *gorm.DB, models ...interface{}) ([]*schema.Schema, error) {
+	byTable := make(map[string]*schema.Schema, len(models))
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("cannot parse %T: %w", model, err)
+		}
+		byTable[stmt.Schema.Table] = stmt.Schema
+	}
+
+	dependencies := make(map[string]map[string]bool, len(byTable))
+	depend := func(table, on string) {
+		if _, known := byTable[on]; !known || table == on {
+			return
+		}
+		if dependencies[table] == nil {
+			dependencies[table] = make(map[string]bool)
+		}
+		dependencies[table][on] = true
+	}
+	for table, s := range byTable {
+		for _, rel := range s.Relationships.Relations {
+			switch rel.Type {
+			case schema.BelongsTo:
+				depend(table, rel.FieldSchema.Table)
+			case schema.HasOne, schema.HasMany:
+				depend(rel.FieldSchema.Table, table)
+			}
+		}
+	}
+
+	tables := make([]string, 0, len(byTable))
+	for table := range byTable {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	ordered := make([]*schema.Schema, 0, len(tables))
+	state := make(map[string]int) // 1 visiting, 2 done
+	var visit func(table string, path []string) error
+	visit = func(table string, path []string) error {
+		switch state[table] {
+		case 1:
+			return fmt.Errorf("foreign keys form a cycle: %s", strings.Join(append(path, table), " -> "))
+		case 2:
+			return nil
+		}
+		state[table] = 1
+		parents := make([]string, 0, len(dependencies[table]))
+		for parent := range dependencies[table] {
+			parents = append(parents, parent)
+		}
+		sort.Strings(parents)
+		for _, parent := range parents {
+			if err := visit(parent, append(path, table)); err != nil {
+				return err
+			}
+		}
+		state[table] = 2
+		ordered = append(ordered, byTable[table])
+		return nil
+	}
+	for _, table := range tables {
+		if err := visit(table, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
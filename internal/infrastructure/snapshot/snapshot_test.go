@@ -0,0 +1,303 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"agentic/commerce/internal/core"
+	"agentic/commerce/internal/infrastructure/database"
+
+	"github.com/glebarez/sqlite"
+	"github.com/orsinium-labs/enum"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/plugin/soft_delete"
+)
+
+type species enum.Member[string]
+
+var (
+	speciesCat = species{"cat"}
+	speciesDog = species{"dog"}
+
+	_ = database.RegisterEnum(enum.New(speciesCat, speciesDog))
+)
+
+type owner struct {
+	ID   uint64
+	Name string
+	Pets []pet
+}
+
+type pet struct {
+	ID        uint64
+	OwnerID   uint64
+	Name      string
+	Species   species `gorm:"serializer:enum"`
+	Tags      core.JSON
+	BornAt    *time.Time
+	DeletedAt soft_delete.DeletedAt
+}
+
+// vet has no foreign key, it sorts by name among the independent tables.
+type vet struct {
+	ID   uint64
+	Name string
+}
+
+var models = []interface{}{&pet{}, &vet{}, &owner{}}
+
+func newSQLiteDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// exported fills a database and exports it in batches of two.
+func exported(t *testing.T) (*gorm.DB, []byte) {
+	t.Helper()
+	db := newSQLiteDB(t)
+	born := time.Date(2020, 2, 29, 10, 30, 0, 0, time.UTC)
+	owners := []owner{
+		{Name: "ann", Pets: []pet{
+			{Name: "tom", Species: speciesCat, Tags: core.JSON{"indoor": true, "toys": []interface{}{"ball"}}, BornAt: &born},
+			{Name: "rex", Species: speciesDog},
+		}},
+		{Name: "bob", Pets: []pet{{Name: "felix", Species: speciesCat}}},
+		{Name: "cy"},
+	}
+	if err := db.Create(&owners).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(&owners[0].Pets[1]).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&vet{Name: "dr who"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var archive bytes.Buffer
+	if _, err := Export(context.Background(), db, &archive, models, Options{BatchSize: 2, SchemaVersion: "v1"}); err != nil {
+		t.Fatal(err)
+	}
+	return db, archive.Bytes()
+}
+
+func allRows[T any](t *testing.T, db *gorm.DB) []T {
+	t.Helper()
+	var rows []T
+	if err := db.Unscoped().Order("id").Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	source, archive := exported(t)
+	target := newSQLiteDB(t)
+
+	manifest, err := Import(context.Background(), target, bytes.NewReader(archive), models, Options{BatchSize: 3, SchemaVersion: "v1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tables []string
+	rows := map[string]int64{}
+	for _, table := range manifest.Tables {
+		tables = append(tables, table.Table)
+		rows[table.Table] = table.Rows
+	}
+	if strings.Join(tables, ",") != "owners,pets,vets" || rows["owners"] != 3 || rows["pets"] != 3 || rows["vets"] != 1 {
+		t.Fatalf("manifest lists %v with %v rows", tables, rows)
+	}
+
+	if got, want := allRows[owner](t, target), allRows[owner](t, source); !reflect.DeepEqual(got, want) {
+		t.Fatalf("owners\n got %+v\nwant %+v", got, want)
+	}
+	got, want := allRows[pet](t, target), allRows[pet](t, source)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("pets\n got %+v\nwant %+v", got, want)
+	}
+	if got[1].DeletedAt == 0 || got[0].BornAt == nil || got[0].Tags["indoor"] != true {
+		t.Fatalf("the soft delete, time or json of the pets was lost: %+v", got)
+	}
+
+	// new rows take ids after the imported ones
+	added := owner{Name: "dee"}
+	if err := target.Create(&added).Error; err != nil || added.ID != 4 {
+		t.Fatalf("a new owner got the id %d: %v", added.ID, err)
+	}
+}
+
+func TestImportRefuses(t *testing.T) {
+	_, archive := exported(t)
+	ctx := context.Background()
+
+	filled := newSQLiteDB(t)
+	if err := filled.Create(&vet{Name: "already there"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Import(ctx, filled, bytes.NewReader(archive), models, Options{SchemaVersion: "v1"}); !errors.Is(err, ErrNotEmpty) {
+		t.Fatalf("imported into a table with rows: %v", err)
+	}
+
+	target := newSQLiteDB(t)
+	if _, err := Import(ctx, target, bytes.NewReader(archive), models, Options{SchemaVersion: "v2"}); !errors.Is(err, ErrSchemaVersion) {
+		t.Fatalf("imported an archive of another schema version: %v", err)
+	}
+	if _, err := Import(ctx, target, bytes.NewReader(archive), models, Options{SchemaVersion: "v2", AllowSchemaMismatch: true}); err != nil {
+		t.Fatalf("an allowed schema mismatch failed: %v", err)
+	}
+
+	if _, err := Import(ctx, newSQLiteDB(t), bytes.NewReader(archive), []interface{}{&owner{}, &pet{}}, Options{SchemaVersion: "v1"}); err == nil {
+		t.Fatal("imported a table that is no registered model")
+	}
+}
+
+func TestImportRejectsCorruptedArchives(t *testing.T) {
+	_, archive := exported(t)
+
+	tests := []struct {
+		name   string
+		tamper func(entries map[string][]byte)
+	}{
+		{"changed row", func(entries map[string][]byte) {
+			entries["owners.ndjson"] = bytes.Replace(entries["owners.ndjson"], []byte(`"ann"`), []byte(`"eve"`), 1)
+		}},
+		{"dropped row", func(entries map[string][]byte) {
+			lines := bytes.SplitAfter(entries["vets.ndjson"], []byte("\n"))
+			entries["vets.ndjson"] = bytes.Join(lines[1:], nil)
+		}},
+		{"row count", func(entries map[string][]byte) {
+			var manifest Manifest
+			if err := json.Unmarshal(entries[ManifestName], &manifest); err != nil {
+				t.Fatal(err)
+			}
+			manifest.Tables[0].Rows++
+			entries[ManifestName], _ = json.Marshal(manifest)
+		}},
+		{"missing file", func(entries map[string][]byte) {
+			delete(entries, "pets.ndjson")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := newSQLiteDB(t)
+			_, err := Import(context.Background(), target, bytes.NewReader(tampered(t, archive, tt.tamper)), models, Options{SchemaVersion: "v1"})
+			if !errors.Is(err, ErrCorrupted) {
+				t.Fatalf("imported a corrupted archive: %v", err)
+			}
+			if rows := allRows[owner](t, target); len(rows) != 0 {
+				t.Fatalf("%d owners were written before the archive was checked", len(rows))
+			}
+		})
+	}
+
+	if _, err := Import(context.Background(), newSQLiteDB(t), bytes.NewReader(archive[:len(archive)/2]), models, Options{SchemaVersion: "v1"}); !errors.Is(err, ErrCorrupted) {
+		t.Fatalf("imported a truncated archive: %v", err)
+	}
+}
+
+// tampered rewrites archive with the entries tamper leaves, in their original order.
+func tampered(t *testing.T, archive []byte, tamper func(entries map[string][]byte)) []byte {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := tar.NewReader(gz)
+	var names []string
+	entries := map[string][]byte{}
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, header.Name)
+		entries[header.Name] = content
+	}
+	tamper(entries)
+
+	var out bytes.Buffer
+	gzOut := gzip.NewWriter(&out)
+	writer := tar.NewWriter(gzOut)
+	for _, name := range names {
+		content, ok := entries[name]
+		if !ok {
+			continue
+		}
+		if err := writer.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzOut.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+type chicken struct {
+	ID    uint64
+	EggID uint64
+	Egg   *egg
+}
+
+type egg struct {
+	ID        uint64
+	ChickenID uint64
+	Chicken   *chicken
+}
+
+func TestOrder(t *testing.T) {
+	db := newSQLiteDB(t)
+
+	schemas, err := Order(db, models...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tables []string
+	for _, s := range schemas {
+		tables = append(tables, s.Table)
+	}
+	// pets follow the owners they reference, vets has no key and comes by name
+	if strings.Join(tables, ",") != "owners,pets,vets" {
+		t.Fatalf("ordered %v", tables)
+	}
+
+	if _, err := Order(db, &chicken{}, &egg{}); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("ordered tables referencing each other: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package tenancy
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrMissingTenant = errors.New("no tenant in context for a tenant-scoped model")
+
+type tenantContextKey struct{}
+type bypassContextKey struct{}
+
+// WithTenant stores the tenant of the current request.
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// FromContext returns the tenant stored by WithTenant.
+func FromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	tenantID, ok := ctx.Value(tenantContextKey{}).(string)
+	return tenantID, ok && tenantID != ""
+}
+
+// WithoutTenantScope disables tenant scoping for ctx. Only meant for system jobs such as
+// migrations, relays and seeders that legitimately work across tenants.
+func WithoutTenantScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassContextKey{}, true)
+}
+
+// Resolve returns the tenant the statements made with ctx are scoped to: the tenant of
+// ctx, else defaultTenant. scoped is false under WithoutTenantScope; ErrMissingTenant is
+// returned when there is no tenant to scope them to.
+func Resolve(ctx context.Context, defaultTenant string) (tenantID string, scoped bool, err error) {
+	if isBypassed(ctx) {
+		return "", false, nil
+	}
+	tenantID, ok := FromContext(ctx)
+	if !ok {
+		tenantID = defaultTenant
+	}
+	if tenantID == "" {
+		return "", false, ErrMissingTenant
+	}
+	return tenantID, true, nil
+}
+
+func isBypassed(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	bypassed, _ := ctx.Value(bypassContextKey{}).(bool)
+	return bypassed
+}
@@ -0,0 +1,183 @@
+package tenancy
+
+import (
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	FieldName  = "TenantID"
+	ColumnName = "tenant_id"
+)
+
+// Plugin scopes every statement on a model with a TenantID field to the tenant of the
+// statement's context: reads, updates and deletes get a tenant_id condition and creates
+// get the column filled in. Raw SQL is left untouched.
+type Plugin struct {
+	// DefaultTenant is used when the context carries no tenant; empty means such
+	// statements fail with ErrMissingTenant.
+	DefaultTenant string
+}
+
+func (p *Plugin) Name() string {
+	return "tenancy"
+}
+
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("tenancy:create", p.beforeCreate); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().Before("gorm:query").Register("tenancy:query", p.scope); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("tenancy:update", p.scopeWrite); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("tenancy:delete", p.scopeWrite); err != nil {
+		return err
+	}
+	return db.Callback().Row().Before("gorm:row").Register("tenancy:row", p.scope)
+}
+
+func (p *Plugin) scope(tx *gorm.DB) {
+	field, tenantID, ok := p.resolve(tx)
+	if !ok {
+		return
+	}
+	tx.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tenantID},
+	}})
+}
+
+// scopeWrite leaves unconditioned updates and deletes alone so gorm still rejects them
+// with ErrMissingWhereClause instead of silently touching every row of the tenant.
+func (p *Plugin) scopeWrite(tx *gorm.DB) {
+	if _, ok := tx.Statement.Clauses["WHERE"]; !ok && !tx.Statement.AllowGlobalUpdate && !hasPrimaryKey(tx) {
+		return
+	}
+	p.scope(tx)
+}
+
+func (p *Plugin) beforeCreate(tx *gorm.DB) {
+	field, tenantID, ok := p.resolve(tx)
+	if !ok {
+		return
+	}
+
+	ctx := tx.Statement.Context
+	assign := func(rv reflect.Value) {
+		current, zero := field.ValueOf(ctx, rv)
+		if !zero && current != tenantID {
+			_ = tx.AddError(fmt.Errorf("cannot create a %s row for tenant %v from tenant %s", tx.Statement.Schema.Name, current, tenantID))
+			return
+		}
+		if err := field.Set(ctx, rv, tenantID); err != nil {
+			_ = tx.AddError(err)
+		}
+	}
+
+	rv := reflect.Indirect(tx.Statement.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			assign(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		assign(rv)
+	}
+
+	// an upsert must not take over a conflicting row of another tenant
+	if c, ok := tx.Statement.Clauses[clause.OnConflict{}.Name()]; ok {
+		if onConflict, ok := c.Expression.(clause.OnConflict); ok && !onConflict.DoNothing {
+			if tx.Dialector.Name() == "mysql" {
+				onConflict = guardDuplicateKeyUpdate(tx, onConflict, field, tenantID)
+			} else {
+				onConflict.Where.Exprs = append(onConflict.Where.Exprs, clause.Eq{
+					Column: clause.Column{Table: tx.Statement.Table, Name: field.DBName},
+					Value:  tenantID,
+				})
+			}
+			tx.Statement.AddClause(onConflict)
+		}
+	}
+}
+
+// guardDuplicateKeyUpdate makes every assignment of MySQL's ON DUPLICATE KEY UPDATE,
+// which has no WHERE, keep the current value unless the row belongs to the tenant.
+// UpdateAll is expanded here the way gorm does it; the tenant column itself is never
+// assigned since later assignments would see its new value.
+func guardDuplicateKeyUpdate(tx *gorm.DB, onConflict clause.OnConflict, field *schema.Field, tenantID string) clause.OnConflict {
+	tenant := clause.Column{Name: field.DBName}
+
+	updates := onConflict.DoUpdates
+	if onConflict.UpdateAll {
+		updates = nil
+		for _, f := range tx.Statement.Schema.Fields {
+			if f.DBName == "" || f.PrimaryKey || !f.Creatable || f.AutoCreateTime != 0 {
+				continue
+			}
+			updates = append(updates, clause.Assignment{
+				Column: clause.Column{Name: f.DBName},
+				Value:  clause.Expr{SQL: "VALUES(?)", Vars: []interface{}{clause.Column{Name: f.DBName}}},
+			})
+		}
+		onConflict.UpdateAll = false
+	}
+
+	guarded := make([]clause.Assignment, 0, len(updates))
+	for _, assignment := range updates {
+		if assignment.Column.Name == field.DBName {
+			continue
+		}
+		value := assignment.Value
+		if column, ok := value.(clause.Column); ok && column.Table == "excluded" {
+			value = clause.Expr{SQL: "VALUES(?)", Vars: []interface{}{clause.Column{Name: column.Name}}}
+		}
+		guarded = append(guarded, clause.Assignment{
+			Column: assignment.Column,
+			Value: clause.Expr{
+				SQL:  "IF(? = ?, ?, ?)",
+				Vars: []interface{}{tenant, tenantID, value, clause.Column{Name: assignment.Column.Name}},
+			},
+		})
+	}
+	onConflict.DoUpdates = guarded
+	return onConflict
+}
+
+func (p *Plugin) resolve(tx *gorm.DB) (*schema.Field, string, bool) {
+	if tx.Error != nil || tx.Statement.Schema == nil {
+		return nil, "", false
+	}
+	field := tx.Statement.Schema.LookUpField(FieldName)
+	if field == nil {
+		return nil, "", false
+	}
+
+	tenantID, scoped, err := Resolve(tx.Statement.Context, p.DefaultTenant)
+	if err != nil {
+		_ = tx.AddError(err)
+		return nil, "", false
+	}
+	return field, tenantID, scoped
+}
+
+func hasPrimaryKey(tx *gorm.DB) bool {
+	if tx.Statement.Schema == nil {
+		return false
+	}
+	rv := reflect.Indirect(tx.Statement.ReflectValue)
+	if rv.Kind() != reflect.Struct {
+		return rv.Kind() == reflect.Slice && rv.Len() > 0
+	}
+	for _, field := range tx.Statement.Schema.PrimaryFields {
+		if _, zero := field.ValueOf(tx.Statement.Context, rv); !zero {
+			return true
+		}
+	}
+	return false
+}
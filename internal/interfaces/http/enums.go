@@ -0,0 +1,80 @@
+package http
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"agentic/commerce/internal/infrastructure/database"
+
+	"github.com/TickLabVN/tonic/core/docs"
+	"github.com/TickLabVN/tonic/core/utils"
+)
+
+// EnumTag names the registered enum of a field by its Go type, such as
+// `enum:"audit.Action"`; the spec lists the enum's values for the field.
+const EnumTag = "enum"
+
+// parsingKeys are the tags tonic builds the schemas of a route from.
+var parsingKeys = []string{"param", "query", "header", "json"}
+
+// documentEnums sets the enum of every tagged field in the schemas tonic built for t.
+func documentEnums(spec *docs.OpenApi, t reflect.Type) {
+	for _, key := range parsingKeys {
+		schema, ok := spec.Components.Schemas[utils.GetSchemaName(t)+"_"+key]
+		if !ok || schema.SchemaObject == nil {
+			continue
+		}
+		applyEnums(schema.SchemaObject, t, key)
+	}
+}
+
+// applyEnums walks schema along t, the schemas being inlined rather than referenced.
+func applyEnums(schema *docs.SchemaObject, t reflect.Type, key string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if schema.Array != nil && schema.Items != nil {
+			applyEnums(schema.Items, t.Elem(), key)
+		}
+	case reflect.Map:
+		if schema.Object != nil && schema.AdditionalProperties != nil {
+			applyEnums(schema.AdditionalProperties, t.Elem(), key)
+		}
+	case reflect.Struct:
+		if schema.Object == nil {
+			return
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.Anonymous {
+				applyEnums(schema, field.Type, key)
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+			property, ok := schema.Properties[name]
+			if name == "" || !ok {
+				continue
+			}
+			if enumName := field.Tag.Get(EnumTag); enumName != "" {
+				property.Enum = append([]any(nil), enumNamed(enumName).Values...)
+			} else {
+				applyEnums(&property, field.Type, key)
+			}
+			schema.Properties[name] = property
+		}
+	}
+}
+
+// enumNamed panics on a tag naming no registered enum, like RegisterEnum on a mistake
+// made in a declaration.
+func enumNamed(name string) *database.EnumType {
+	for _, enumType := range database.Enums() {
+		if enumType.GoType.String() == name {
+			return enumType
+		}
+	}
+	panic(fmt.Sprintf("no enum %s is registered", name))
+}
@@ -0,0 +1,78 @@
+package http_test
+
+import (
+	nethttp "net/http"
+	"reflect"
+	"testing"
+
+	"agentic/commerce/internal/infrastructure/database"
+	internalhttp "agentic/commerce/internal/interfaces/http"
+	"agentic/commerce/internal/interfaces/http/middleware"
+
+	"github.com/TickLabVN/tonic/core/docs"
+	"github.com/TickLabVN/tonic/core/utils"
+	"github.com/labstack/echo/v4"
+	"github.com/orsinium-labs/enum"
+)
+
+type shade enum.Member[string]
+
+type level enum.Member[int]
+
+var (
+	shades = database.RegisterEnum(enum.New(shade{"light"}, shade{"dark"}))
+	levels = database.RegisterEnum(enum.New(level{1}, level{2}, level{3}))
+)
+
+type paintRequest struct {
+	Shade string `query:"shade" enum:"http_test.shade"`
+	Level int    `query:"level" enum:"http_test.level"`
+	Name  string `query:"name"`
+}
+
+type paintItem struct {
+	Shade string `json:"shade" enum:"http_test.shade"`
+}
+
+type paintResponse struct {
+	Items []paintItem `json:"items"`
+	Level *int        `json:"level" enum:"http_test.level"`
+}
+
+func TestAddRouteDocumentsTaggedEnums(t *testing.T) {
+	server := newSecuredServer(t)
+	ok := func(c echo.Context) error { return c.NoContent(nethttp.StatusOK) }
+	internalhttp.AddRoute[paintRequest, paintResponse](server, server.Router.GET("/paint", ok), middleware.SecurityPublic)
+
+	query := schemaOf[paintRequest](t, server, "query")
+	assertEnum(t, "query shade", query.Properties["shade"], shades.Values())
+	assertEnum(t, "query level", query.Properties["level"], levels.Values())
+	if query.Properties["name"].Enum != nil {
+		t.Fatalf("an untagged field has the enum %v", query.Properties["name"].Enum)
+	}
+
+	response := schemaOf[paintResponse](t, server, "json")
+	assertEnum(t, "item shade", response.Properties["items"].Items.Properties["shade"], shades.Values())
+	assertEnum(t, "response level", response.Properties["level"], levels.Values())
+}
+
+func schemaOf[T any](t *testing.T, server *internalhttp.Server, key string) *docs.SchemaObject {
+	t.Helper()
+	schema, ok := server.Spec.Components.Schemas[utils.GetSchemaName(reflect.TypeOf(new(T)))+"_"+key]
+	if !ok || schema.SchemaObject == nil {
+		t.Fatalf("no %s schema of %T", key, *new(T))
+	}
+	return schema.SchemaObject
+}
+
+func assertEnum[V comparable](t *testing.T, name string, schema docs.SchemaObject, values []V) {
+	t.Helper()
+	if len(schema.Enum) != len(values) {
+		t.Fatalf("%s has the enum %v, want %v", name, schema.Enum, values)
+	}
+	for i, value := range values {
+		if schema.Enum[i] != any(value) {
+			t.Fatalf("%s has the enum %v, want %v", name, schema.Enum, values)
+		}
+	}
+}
@@ -2,34 +2,108 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 
+	"agentic/commerce/internal/core"
+	"agentic/commerce/internal/infrastructure/auth"
+	"agentic/commerce/internal/utils"
+	"agentic/commerce/pkg/apperror"
+	"agentic/commerce/pkg/jwt"
+
 	"github.com/labstack/echo/v4"
 )
 
-func WithAuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		if c.Request().Header.Get("Authorization") == "" && !strings.Contains(c.Request().URL.String(), "swagger") {
-			c.Error(echo.NewHTTPError(http.StatusUnauthorized, "please login first"))
-			return nil
+const APIKeyHeader = "X-API-Key"
+
+var (
+	errAmbiguousCredentials = errors.New("both a bearer token and an api key")
+	errTokenNotAccepted     = errors.New("the route doesn't take a bearer token")
+	errAPIKeyNotAccepted    = errors.New("the route doesn't take an api key")
+)
+
+// WithAuthMiddleware verifies the credential the route declared, a bearer token or the
+// X-API-Key header, and puts its principal in the context, the subject being the actor
+// of the audit fields. Public routes are served as they come.
+func WithAuthMiddleware(authenticator *auth.Authenticator, routes *RouteSecurity) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			security := routes.Of(c)
+			if security.Public() {
+				return next(c)
+			}
+
+			principal, err := authenticate(c.Request(), authenticator, security)
+			var appErr *apperror.ErrorWithStatus
+			if errors.As(err, &appErr) {
+				return utils.ErrorResponse(c, appErr, "Cant verify the credentials")
+			}
+			if err != nil {
+				return unauthorized(c, err)
+			}
+
+			ctx := auth.WithPrincipal(c.Request().Context(), principal)
+			ctx = core.WithActor(ctx, principal.Subject)
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
 		}
+	}
+}
 
-		userID := int64(111)
+func authenticate(req *http.Request, authenticator *auth.Authenticator, security Security) (*auth.Principal, error) {
+	apiKey := strings.TrimSpace(req.Header.Get(APIKeyHeader))
+	token := bearerToken(req.Header.Get(echo.HeaderAuthorization))
+	switch {
+	case apiKey != "" && token != "":
+		return nil, errAmbiguousCredentials
+	case apiKey != "" && !security.AcceptsAPIKey():
+		return nil, errAPIKeyNotAccepted
+	case apiKey != "":
+		return authenticator.AuthenticateAPIKey(req.Context(), apiKey)
+	case token != "" && !security.AcceptsToken():
+		return nil, errTokenNotAccepted
+	default:
+		return authenticator.AuthenticateToken(req.Context(), token)
+	}
+}
 
-		ctx := context.WithValue(c.Request().Context(), "userId", userID)
+func bearerToken(header string) string {
+	scheme, token, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
 
-		req := c.Request().WithContext(ctx)
-		c.SetRequest(req)
-		return next(c)
+// unauthorized answers 401 with the uniform error body and the RFC 6750 challenge. Only
+// expiry is spelled out, the client can act on it; other failures stay vague.
+func unauthorized(c echo.Context, err error) error {
+	challenge, message := `Bearer error="invalid_token"`, "invalid token"
+	switch {
+	case errors.Is(err, auth.ErrNoCredentials):
+		challenge, message = "Bearer", "please login first"
+	case errors.Is(err, jwt.ErrExpired):
+		message = "token is expired"
+	case errors.Is(err, errAmbiguousCredentials):
+		challenge, message = `Bearer error="invalid_request"`, "send either a bearer token or an api key"
+	case errors.Is(err, errTokenNotAccepted):
+		challenge, message = `Bearer error="invalid_request"`, "send an api key"
+	case errors.Is(err, errAPIKeyNotAccepted):
+		challenge, message = `Bearer error="invalid_request"`, "send a bearer token"
+	case errors.Is(err, auth.ErrAPIKeyExpired):
+		message = "api key is expired"
+	case errors.Is(err, auth.ErrInvalidAPIKey):
+		message = "invalid api key"
 	}
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, challenge)
+	return utils.ErrorResponse(c, apperror.ErrUnauthorized, message)
 }
 
+// GetUserID returns the user id of the principal of the request, 0 without one.
 func GetUserID(ctx context.Context) int64 {
-	if v := ctx.Value("userId"); v != nil {
-		if id, ok := v.(int64); ok {
-			return id
-		}
+	if principal, ok := auth.PrincipalFrom(ctx); ok {
+		return principal.UserID
 	}
 	return 0
 }
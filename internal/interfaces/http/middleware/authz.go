@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"agentic/commerce/internal/infrastructure/auth"
+	"agentic/commerce/internal/infrastructure/authz"
+	"agentic/commerce/internal/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequirePermission is declared with a route, "resource:action:scope". It only lets
+// through principals holding the permission; the service still checks the owner of the
+// resource at hand.
+func RequirePermission(policy *authz.Policy, permission string) echo.MiddlewareFunc {
+	wanted := authz.MustParsePermission(permission)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if err := policy.Require(c.Request().Context(), wanted); err != nil {
+				return utils.ErrorResponse(c, err)
+			}
+			return next(c)
+		}
+	}
+}
+
+// WithAdminMiddleware lets through the routes declared SecurityAdmin only for principals
+// holding the admin role. It runs after the tenant is resolved, roles are assigned per
+// tenant.
+func WithAdminMiddleware(policy *authz.Policy, routes *RouteSecurity) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if routes.Of(c).Admin() {
+				if err := policy.RequireRole(c.Request().Context(), auth.RoleAdmin); err != nil {
+					return utils.ErrorResponse(c, err)
+				}
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import "github.com/labstack/echo/v4"
+
+// Security is what a route declares a caller must present. The levels combine, such as
+// SecurityUser | SecurityAPIKey for a route serving people and machine clients alike.
+type Security uint8
+
+const (
+	// SecurityPublic routes are served without a credential, one sent is ignored.
+	SecurityPublic Security = 1 << iota
+	// SecurityUser routes take a bearer token.
+	SecurityUser
+	// SecurityAPIKey routes take an X-API-Key header.
+	SecurityAPIKey
+	// SecurityAdmin routes take a bearer token whose principal holds the admin role.
+	SecurityAdmin
+)
+
+// securityUndeclared applies to routes registered without a declaration: any
+// credential, as before routes declared theirs.
+const securityUndeclared = SecurityUser | SecurityAPIKey
+
+func (s Security) Public() bool {
+	return s&SecurityPublic != 0
+}
+
+// AcceptsToken reports whether a bearer token is a credential of the route.
+func (s Security) AcceptsToken() bool {
+	return s&(SecurityUser|SecurityAdmin) != 0
+}
+
+// AcceptsAPIKey reports whether an X-API-Key header is a credential of the route.
+func (s Security) AcceptsAPIKey() bool {
+	return s&SecurityAPIKey != 0
+}
+
+// Admin reports whether only administrators may call the route.
+func (s Security) Admin() bool {
+	return s&SecurityAdmin != 0
+}
+
+// RouteSecurity holds the declaration of every route by method and path, as echo
+// reports them in c.Path(). Routes are declared while registering, before serving.
+type RouteSecurity struct {
+	routes map[string]Security
+}
+
+func NewRouteSecurity() *RouteSecurity {
+	return &RouteSecurity{routes: map[string]Security{}}
+}
+
+// Declare records the security of a route.
+func (r *RouteSecurity) Declare(method, path string, security Security) {
+	r.routes[routeKey(method, path)] = security
+}
+
+// Of returns the declaration of the route c matched. A request matching no route is
+// public, echo answers it 404 rather than the auth middleware 401.
+func (r *RouteSecurity) Of(c echo.Context) Security {
+	if c.Path() == "" {
+		return SecurityPublic
+	}
+	if security, ok := r.routes[routeKey(c.Request().Method, c.Path())]; ok {
+		return security
+	}
+	return securityUndeclared
+}
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net"
+	"strings"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/infrastructure/auth"
+	"agentic/commerce/internal/infrastructure/tenancy"
+	"agentic/commerce/internal/utils"
+	"agentic/commerce/pkg/apperror"
+
+	"github.com/labstack/echo/v4"
+)
+
+const DefaultTenantHeader = "X-Tenant-ID"
+
+// WithTenantMiddleware resolves the tenant from the configured header, falling back to
+// the subdomain of the configured base domain. A principal always acts in its own tenant,
+// the tenant claim of its token or the tenant of its API key, else the default tenant;
+// asking for another one is forbidden.
+func WithTenantMiddleware(cfg *config.TenancyConfig) echo.MiddlewareFunc {
+	header := DefaultTenantHeader
+	var baseDomain, defaultTenant string
+	if cfg != nil {
+		if cfg.Header != "" {
+			header = cfg.Header
+		}
+		baseDomain = strings.TrimPrefix(strings.ToLower(cfg.BaseDomain), ".")
+		defaultTenant = cfg.DefaultTenant
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			tenantID := strings.TrimSpace(c.Request().Header.Get(header))
+			if tenantID == "" && baseDomain != "" {
+				tenantID = subdomainOf(c.Request().Host, baseDomain)
+			}
+			if principal, ok := auth.PrincipalFrom(c.Request().Context()); ok {
+				bound := principal.Tenant
+				if bound == "" {
+					bound = defaultTenant
+				}
+				if tenantID != "" && tenantID != bound {
+					return utils.ErrorResponse(c, apperror.ErrForbidden, "the credential belongs to another tenant")
+				}
+				tenantID = bound
+			}
+
+			if tenantID != "" {
+				ctx := tenancy.WithTenant(c.Request().Context(), tenantID)
+				c.SetRequest(c.Request().WithContext(ctx))
+			}
+			return next(c)
+		}
+	}
+}
+
+func subdomainOf(host, baseDomain string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+
+	sub, found := strings.CutSuffix(host, "."+baseDomain)
+	if !found || sub == "" || strings.Contains(sub, ".") {
+		return ""
+	}
+	return sub
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/infrastructure/auth"
+	"agentic/commerce/internal/infrastructure/tenancy"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestTenantMiddlewareBindsThePrincipal(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		header    string
+		status    int
+		tenant    string
+	}{
+		{name: "anonymous picks the header", header: "b", status: http.StatusOK, tenant: "b"},
+		{name: "token tenant", principal: &auth.Principal{Kind: auth.PrincipalUser, Tenant: "a"}, status: http.StatusOK, tenant: "a"},
+		{name: "token tenant and matching header", principal: &auth.Principal{Kind: auth.PrincipalUser, Tenant: "a"}, header: "a", status: http.StatusOK, tenant: "a"},
+		{name: "token tenant and other header", principal: &auth.Principal{Kind: auth.PrincipalUser, Tenant: "a"}, header: "b", status: http.StatusForbidden},
+		{name: "token without tenant", principal: &auth.Principal{Kind: auth.PrincipalUser}, status: http.StatusOK, tenant: "default"},
+		{name: "token without tenant and other header", principal: &auth.Principal{Kind: auth.PrincipalUser}, header: "b", status: http.StatusForbidden},
+		{name: "api key and other header", principal: &auth.Principal{Kind: auth.PrincipalAPIKey, Tenant: "a"}, header: "b", status: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tenant string
+			e := echo.New()
+			e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					if tt.principal != nil {
+						c.SetRequest(c.Request().WithContext(auth.WithPrincipal(c.Request().Context(), tt.principal)))
+					}
+					return next(c)
+				}
+			})
+			e.Use(WithTenantMiddleware(&config.TenancyConfig{DefaultTenant: "default"}))
+			e.GET("/", func(c echo.Context) error {
+				tenant, _ = tenancy.FromContext(c.Request().Context())
+				return c.NoContent(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(DefaultTenantHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status %d, want %d", rec.Code, tt.status)
+			}
+			if tenant != tt.tenant {
+				t.Fatalf("tenant %q, want %q", tenant, tt.tenant)
+			}
+		})
+	}
+}
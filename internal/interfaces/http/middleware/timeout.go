@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"strings"
+	"time"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/infrastructure/database"
+
+	"github.com/labstack/echo/v4"
+)
+
+// WithStatementTimeoutMiddleware applies the statement timeout configured for the
+// matched route to the queries of the request; other routes keep the default one.
+func WithStatementTimeoutMiddleware(cfg *config.DbConfig) echo.MiddlewareFunc {
+	routes := make(map[string]time.Duration)
+	if cfg != nil {
+		for _, route := range cfg.RouteTimeouts {
+			routes[routeKey(strings.ToUpper(strings.TrimSpace(route.Method)), strings.TrimSpace(route.Path))] = route.Timeout
+		}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if timeout, ok := routes[routeKey(c.Request().Method, c.Path())]; ok {
+				ctx := database.WithStatementTimeout(c.Request().Context(), timeout)
+				c.SetRequest(c.Request().WithContext(ctx))
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/infrastructure/database"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestStatementTimeoutMiddlewareMatchesTheRoute(t *testing.T) {
+	cfg := &config.DbConfig{RouteTimeouts: []config.RouteTimeout{
+		{Method: "get", Path: "/posts/:postId", Timeout: 30 * time.Second},
+	}}
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		timeout time.Duration
+	}{
+		{name: "mixed case path", method: http.MethodGet, path: "/posts/1", timeout: 30 * time.Second},
+		{name: "other method", method: http.MethodDelete, path: "/posts/1"},
+		{name: "other route", method: http.MethodGet, path: "/posts"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var timeout time.Duration
+			var ok bool
+			handler := func(c echo.Context) error {
+				timeout, ok = database.StatementTimeoutFrom(c.Request().Context())
+				return c.NoContent(http.StatusOK)
+			}
+			e := echo.New()
+			e.Use(WithStatementTimeoutMiddleware(cfg))
+			e.GET("/posts/:postId", handler)
+			e.DELETE("/posts/:postId", handler)
+			e.GET("/posts", handler)
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status %d", rec.Code)
+			}
+			if ok != (tt.timeout > 0) || timeout != tt.timeout {
+				t.Fatalf("timeout %s (set %t), want %s", timeout, ok, tt.timeout)
+			}
+		})
+	}
+}
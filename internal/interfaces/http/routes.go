@@ -5,30 +5,45 @@ import (
 
 	"agentic/commerce/config"
 	"agentic/commerce/internal/interfaces/http/handlers"
+	"agentic/commerce/internal/interfaces/http/middleware"
 
 	echoAdapter "github.com/TickLabVN/tonic/adapters/echo"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"gorm.io/gorm"
 )
 
+const swaggerPath = "/swagger-ui"
+
 func CommonRoutes(
 	s *Server,
 	db *gorm.DB,
 	mode config.ModeEnum,
+	registry *prometheus.Registry,
 ) *Server {
 
-	echoAdapter.UIHandle(s.Router, s.Spec, "/swagger-ui")
+	echoAdapter.UIHandle(s.Router, s.Spec, swaggerPath)
+	s.Declare(middleware.SecurityPublic, echo.GET, swaggerPath+".json")
+	s.Declare(middleware.SecurityPublic, echo.GET, swaggerPath+"/*")
+
+	metrics := s.Router.GET("/metrics", echo.WrapHandler(promhttp.HandlerFor(registry, promhttp.HandlerOpts{})))
+	s.Declare(middleware.SecurityUser|middleware.SecurityAPIKey, metrics.Method, metrics.Path)
 
 	healthResource := handlers.NewHealthResource(db, mode)
 	healthGroup := s.Router.Group("/health")
 
-	echoAdapter.AddRoute[types.Nil, types.Nil](s.Spec,
+	AddRoute[types.Nil, types.Nil](s,
 		healthGroup.GET("/ping", healthResource.Ping()),
+		middleware.SecurityPublic,
 	)
-	echoAdapter.AddRoute[types.Nil, types.Nil](s.Spec,
+	AddRoute[types.Nil, types.Nil](s,
 		healthGroup.GET("/liveness", healthResource.Liveness()),
+		middleware.SecurityPublic,
 	)
-	echoAdapter.AddRoute[types.Nil, types.Nil](s.Spec,
+	AddRoute[types.Nil, types.Nil](s,
 		healthGroup.GET("/readiness", healthResource.Readiness()),
+		middleware.SecurityPublic,
 	)
 
 	return s
@@ -0,0 +1,49 @@
+package http
+
+import (
+	"reflect"
+
+	"agentic/commerce/internal/interfaces/http/middleware"
+
+	echoAdapter "github.com/TickLabVN/tonic/adapters/echo"
+	"github.com/TickLabVN/tonic/core/docs"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	BearerAuthScheme = "BearerAuth"
+	APIKeyAuthScheme = "ApiKeyAuth"
+)
+
+// AddRoute documents route like echoAdapter.AddRoute and declares its security: the auth
+// middleware enforces it and the operation carries the matching requirements. Fields
+// tagged with EnumTag list the values of their registered enum.
+func AddRoute[D any, R any](s *Server, route *echo.Route, security middleware.Security) {
+	s.Declare(security, route.Method, route.Path)
+	echoAdapter.AddRoute[D, R](s.Spec, route, docs.OperationObject{
+		Security: securityRequirements(security),
+	})
+	documentEnums(s.Spec, reflect.TypeOf(new(D)).Elem())
+	documentEnums(s.Spec, reflect.TypeOf(new(R)).Elem())
+}
+
+// Declare sets the security of a route that isn't documented, such as the swagger UI.
+func (s *Server) Declare(security middleware.Security, method, path string) {
+	s.security.Declare(method, path, security)
+}
+
+// securityRequirements lists the alternatives of an operation; none for a public one, the
+// spec declaring no global requirement.
+func securityRequirements(security middleware.Security) []docs.SecurityRequirement {
+	if security.Public() {
+		return nil
+	}
+	var requirements []docs.SecurityRequirement
+	if security.AcceptsToken() {
+		requirements = append(requirements, docs.SecurityRequirement{BearerAuthScheme: {}})
+	}
+	if security.AcceptsAPIKey() {
+		requirements = append(requirements, docs.SecurityRequirement{APIKeyAuthScheme: {}})
+	}
+	return requirements
+}
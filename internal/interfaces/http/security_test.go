@@ -0,0 +1,147 @@
+package http_test
+
+import (
+	"context"
+	"go/types"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+
+	"agentic/commerce/internal/infrastructure/auth"
+	internalhttp "agentic/commerce/internal/interfaces/http"
+	"agentic/commerce/internal/interfaces/http/middleware"
+	"agentic/commerce/internal/testkit"
+
+	"github.com/TickLabVN/tonic/core/docs"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+const testAPIKey = "test-api-key"
+
+// apiKeys knows testAPIKey only.
+type apiKeys struct{}
+
+func (apiKeys) ResolveAPIKey(_ context.Context, key string) (*auth.Principal, error) {
+	if key != testAPIKey {
+		return nil, auth.ErrInvalidAPIKey
+	}
+	return &auth.Principal{Kind: auth.PrincipalAPIKey, Subject: "key:1"}, nil
+}
+
+var securityRoutes = map[string]middleware.Security{
+	"/secured/public": middleware.SecurityPublic,
+	"/secured/user":   middleware.SecurityUser,
+	"/secured/apikey": middleware.SecurityAPIKey,
+	"/secured/any":    middleware.SecurityUser | middleware.SecurityAPIKey,
+	"/secured/admin":  middleware.SecurityAdmin,
+}
+
+// newSecuredServer adds a route of every security level to the testkit server, and one
+// registered without a declaration.
+func newSecuredServer(t *testing.T) *internalhttp.Server {
+	t.Helper()
+	var server *internalhttp.Server
+	fxtest.New(t,
+		testkit.Module,
+		fx.Supply(fx.Annotate(apiKeys{}, fx.As(new(auth.APIKeyResolver)))),
+		fx.Populate(&server),
+		fx.NopLogger,
+	).RequireStart()
+
+	ok := func(c echo.Context) error { return c.NoContent(nethttp.StatusOK) }
+	for path, security := range securityRoutes {
+		internalhttp.AddRoute[types.Nil, types.Nil](server, server.Router.GET(path, ok), security)
+	}
+	server.Router.GET("/secured/undeclared", ok)
+	return server
+}
+
+func TestRoutesEnforceTheirSecurity(t *testing.T) {
+	server := newSecuredServer(t)
+	userToken, err := testkit.Token(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminToken, err := testkit.Token(1, auth.RoleAdmin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		token  string
+		apiKey string
+		want   int
+	}{
+		{"public without credential", "/secured/public", "", "", nethttp.StatusOK},
+		{"public ignores an invalid token", "/secured/public", "invalid", "", nethttp.StatusOK},
+		{"user without credential", "/secured/user", "", "", nethttp.StatusUnauthorized},
+		{"user with a token", "/secured/user", userToken, "", nethttp.StatusOK},
+		{"user with an invalid token", "/secured/user", "invalid", "", nethttp.StatusUnauthorized},
+		{"user refuses an api key", "/secured/user", "", testAPIKey, nethttp.StatusUnauthorized},
+		{"api key without credential", "/secured/apikey", "", "", nethttp.StatusUnauthorized},
+		{"api key with a key", "/secured/apikey", "", testAPIKey, nethttp.StatusOK},
+		{"api key with an unknown key", "/secured/apikey", "", "unknown", nethttp.StatusUnauthorized},
+		{"api key refuses a token", "/secured/apikey", userToken, "", nethttp.StatusUnauthorized},
+		{"any with a token", "/secured/any", userToken, "", nethttp.StatusOK},
+		{"any with a key", "/secured/any", "", testAPIKey, nethttp.StatusOK},
+		{"any with both", "/secured/any", userToken, testAPIKey, nethttp.StatusUnauthorized},
+		{"admin without credential", "/secured/admin", "", "", nethttp.StatusUnauthorized},
+		{"admin with a user token", "/secured/admin", userToken, "", nethttp.StatusForbidden},
+		{"admin with an admin token", "/secured/admin", adminToken, "", nethttp.StatusOK},
+		{"undeclared without credential", "/secured/undeclared", "", "", nethttp.StatusUnauthorized},
+		{"undeclared with a key", "/secured/undeclared", "", testAPIKey, nethttp.StatusOK},
+		{"no route", "/nowhere", "", "", nethttp.StatusNotFound},
+		{"no route with a token", "/secured/nowhere/deeper", userToken, "", nethttp.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(nethttp.MethodGet, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+			}
+			if tt.apiKey != "" {
+				req.Header.Set(middleware.APIKeyHeader, tt.apiKey)
+			}
+			rec := httptest.NewRecorder()
+			server.Router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestOperationsDocumentTheirSecurity(t *testing.T) {
+	server := newSecuredServer(t)
+	bearer := docs.SecurityRequirement{internalhttp.BearerAuthScheme: {}}
+	apiKey := docs.SecurityRequirement{internalhttp.APIKeyAuthScheme: {}}
+
+	want := map[string][]docs.SecurityRequirement{
+		"/secured/public": nil,
+		"/secured/user":   {bearer},
+		"/secured/apikey": {apiKey},
+		"/secured/any":    {bearer, apiKey},
+		"/secured/admin":  {bearer},
+	}
+	for path, requirements := range want {
+		item, ok := server.Spec.Paths[path]
+		if !ok || item.Get == nil {
+			t.Fatalf("%s isn't documented", path)
+		}
+		got := item.Get.Security
+		if len(got) != len(requirements) {
+			t.Fatalf("%s requires %v, want %v", path, got, requirements)
+		}
+		for i := range requirements {
+			for scheme := range requirements[i] {
+				if _, ok := got[i][scheme]; !ok || len(got[i]) != 1 {
+					t.Fatalf("%s requires %v, want %v", path, got, requirements)
+				}
+			}
+		}
+	}
+}
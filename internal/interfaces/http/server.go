@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"agentic/commerce/config"
+	"agentic/commerce/internal/infrastructure/auth"
+	"agentic/commerce/internal/infrastructure/authz"
 	"agentic/commerce/internal/interfaces/http/middleware"
 
 	m "github.com/labstack/echo/v4/middleware"
@@ -19,14 +21,21 @@ import (
 type Server struct {
 	Router *echo.Echo
 	Spec   *docs.OpenApi
+
+	security *middleware.RouteSecurity
 }
 
-func NewServer() *Server {
+func NewServer(tenancyCfg *config.TenancyConfig, dbCfg *config.DbConfig, authenticator *auth.Authenticator, policy *authz.Policy) *Server {
+	security := middleware.NewRouteSecurity()
+
 	engine := echo.New()
 	engine.JSONSerializer = &middleware.JsonV2{}
 	engine.Use(m.RemoveTrailingSlash())
 	engine.Use(middleware.WithRecoverMiddleware)
-	engine.Use(middleware.WithAuthMiddleware)
+	engine.Use(middleware.WithAuthMiddleware(authenticator, security))
+	engine.Use(middleware.WithTenantMiddleware(tenancyCfg))
+	engine.Use(middleware.WithAdminMiddleware(policy, security))
+	engine.Use(middleware.WithStatementTimeoutMiddleware(dbCfg))
 
 	apiDoc := &docs.OpenApi{
 		OpenAPI: "3.0.1",
@@ -41,23 +50,28 @@ func NewServer() *Server {
 		},
 		Components: docs.ComponentsObject{
 			SecuritySchemes: map[string]docs.SecuritySchemeOrReference{
-				"BearerAuth": {
+				BearerAuthScheme: {
+					SecuritySchemeObject: &docs.SecuritySchemeObject{
+						Type:         "http",
+						Scheme:       "bearer",
+						BearerFormat: "JWT",
+					},
+				},
+				APIKeyAuthScheme: {
 					SecuritySchemeObject: &docs.SecuritySchemeObject{
 						Type: "apiKey",
 						In:   "header",
-						Name: "Authorization",
+						Name: middleware.APIKeyHeader,
 					},
 				},
 			},
 		},
-		Security: []docs.SecurityRequirement{
-			{"BearerAuth": {}},
-		},
 	}
 
 	return &Server{
-		Router: engine,
-		Spec:   apiDoc,
+		Router:   engine,
+		Spec:     apiDoc,
+		security: security,
 	}
 }
 
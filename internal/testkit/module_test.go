@@ -0,0 +1,130 @@
+package testkit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agentic/commerce/config"
+	"agentic/commerce/internal/core"
+	"agentic/commerce/internal/domains/metadata"
+	"agentic/commerce/internal/infrastructure/tenancy"
+	internalhttp "agentic/commerce/internal/interfaces/http"
+	"agentic/commerce/internal/testkit"
+
+	"github.com/samber/lo"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+type response struct {
+	Status string          `json:"status"`
+	Data   json.RawMessage `json:"data"`
+}
+
+func call(t *testing.T, server *internalhttp.Server, method, path, token, body string) (int, response) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	var res response
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("%s %s answered %d %q", method, path, rec.Code, rec.Body.String())
+	}
+	return rec.Code, res
+}
+
+func token(t *testing.T, userID int64, tenantID string) string {
+	t.Helper()
+	token, err := testkit.TenantToken(userID, tenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestModuleScopesRequestsToTheTenant(t *testing.T) {
+	var server *internalhttp.Server
+	fxtest.New(t, testkit.Module, fx.Populate(&server)).RequireStart().RequireStop()
+
+	tokenA := token(t, 1, "a")
+	status, created := call(t, server, http.MethodPost, "/metadata", tokenA, `{"user_id":"1","meta_data":{"desc":"of tenant a"}}`)
+	if status != http.StatusOK {
+		t.Fatalf("create answered %d", status)
+	}
+	var post struct {
+		UUID string `json:"uuid"`
+	}
+	if err := json.Unmarshal(created.Data, &post); err != nil || post.UUID == "" {
+		t.Fatalf("create answered %s", created.Data)
+	}
+
+	listed := func(token string) []json.RawMessage {
+		t.Helper()
+		status, res := call(t, server, http.MethodGet, "/metadata/list?user_id=1", token, "")
+		if status != http.StatusOK {
+			t.Fatalf("list answered %d", status)
+		}
+		var items []json.RawMessage
+		_ = json.Unmarshal(res.Data, &items)
+		return items
+	}
+	if items := listed(tokenA); len(items) != 1 {
+		t.Fatalf("tenant a listed %d posts", len(items))
+	}
+
+	for name, other := range map[string]string{"tenant b": token(t, 1, "b"), "default tenant": token(t, 1, "")} {
+		if items := listed(other); len(items) != 0 {
+			t.Errorf("%s listed the post of tenant a", name)
+		}
+		if status, res := call(t, server, http.MethodGet, "/metadata/"+post.UUID, other, ""); status != http.StatusNotFound {
+			t.Errorf("%s read the post of tenant a: %d %s", name, status, res.Data)
+		}
+	}
+}
+
+func TestModuleRepositoryResolvesTheTenantLikeThePlugin(t *testing.T) {
+	var repository metadata.IContentRepository
+	fxtest.New(t, testkit.Module, fx.Populate(&repository)).RequireStart().RequireStop()
+
+	ctxA := tenancy.WithTenant(context.Background(), "a")
+	if err := repository.Create(ctxA, &metadata.MetaDataModel{UUid: lo.ToPtr(core.UUID("post-of-a")), UserId: lo.ToPtr(int64(1))}); err != nil {
+		t.Fatal(err)
+	}
+
+	// without a tenant the default one applies, as with tenancy.Plugin
+	if found, err := repository.GetByUUID(context.Background(), "post-of-a"); err != nil || found != nil {
+		t.Fatalf("a context without a tenant read the post of tenant a: %v, %v", found, err)
+	}
+	found, err := repository.GetByUUID(tenancy.WithoutTenantScope(context.Background()), "post-of-a")
+	if err != nil || found == nil || found.TenantID != "a" {
+		t.Fatalf("an unscoped context read %v, %v", found, err)
+	}
+
+	forged := &metadata.MetaDataModel{BaseModel: core.BaseModel{TenantID: "a"}, UserId: lo.ToPtr(int64(1))}
+	if err := repository.Create(tenancy.WithTenant(context.Background(), "b"), forged); err == nil {
+		t.Fatal("tenant b created a post for tenant a")
+	}
+}
+
+func TestModuleRepositoryNeedsATenantWithoutADefault(t *testing.T) {
+	cfg := testkit.Config()
+	cfg.Tenancy = &config.TenancyConfig{}
+
+	var repository metadata.IContentRepository
+	fxtest.New(t, testkit.Module, fx.Replace(cfg), fx.Populate(&repository)).RequireStart().RequireStop()
+
+	if _, err := repository.FindAll(context.Background()); !errors.Is(err, tenancy.ErrMissingTenant) {
+		t.Fatalf("a context without a tenant listed: %v", err)
+	}
+	if _, err := repository.FindAll(tenancy.WithoutTenantScope(context.Background())); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,34 @@
+// Package migrations holds the versioned schema changes, applied with `migrate up`.
+// Create new ones with `migrate create <name>`; SQL files are embedded per dialect from
+// the postgres, mysql and sqlite directories, Go migrations register themselves from
+// init and are shared by every dialect.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+
+	"agentic/commerce/internal/infrastructure/migration"
+)
+
+//go:embed postgres/*.sql mysql/*.sql sqlite/*.sql
+var files embed.FS
+
+var goMigrations []migration.Migration
+
+func register(m migration.Migration) {
+	goMigrations = append(goMigrations, m)
+}
+
+// All returns every migration of dialect ordered by version.
+func All(dialect string) ([]migration.Migration, error) {
+	if _, err := fs.Stat(files, dialect); err != nil {
+		return nil, fmt.Errorf("no migrations for dialect %q", dialect)
+	}
+	sub, err := fs.Sub(files, dialect)
+	if err != nil {
+		return nil, err
+	}
+	return migration.Load(sub, goMigrations...)
+}
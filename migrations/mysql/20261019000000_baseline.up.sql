@@ -0,0 +1,58 @@
+-- Schema of the models at the time of the Postgres baseline, in the types GORM maps
+-- them to on MySQL. Indexed strings without a size are varchar(191) so they fit the
+-- utf8mb4 index key limit. MySQL commits DDL implicitly, a failed baseline has to be
+-- cleaned up by hand.
+
+CREATE TABLE IF NOT EXISTS meta_data_models (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    tenant_id  VARCHAR(64) NOT NULL DEFAULT 'default',
+    deleted_at BIGINT UNSIGNED,
+    created_at DATETIME(3) NULL,
+    created_by LONGTEXT,
+    updated_at DATETIME(3) NULL,
+    updated_by LONGTEXT,
+    uuid       CHAR(36),
+    user_id    BIGINT,
+    metadata   JSON,
+    PRIMARY KEY (id),
+    INDEX idx_meta_data_models_tenant_id (tenant_id),
+    INDEX idx_meta_data_models_deleted_at (deleted_at),
+    UNIQUE INDEX idx_meta_data_models_u_uid (uuid)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    tenant_id  VARCHAR(64) NOT NULL DEFAULT 'default',
+    entity     VARCHAR(191),
+    entity_id  VARCHAR(191),
+    action     LONGTEXT,
+    actor      VARCHAR(191),
+    `before`   JSON,
+    `after`    JSON,
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    INDEX idx_audit_logs_tenant_id (tenant_id),
+    INDEX idx_audit_logs_entity (entity, entity_id),
+    INDEX idx_audit_logs_actor (actor),
+    INDEX idx_audit_logs_created_at (created_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id              BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    tenant_id       VARCHAR(64) NOT NULL DEFAULT 'default',
+    aggregate_type  VARCHAR(64) NOT NULL,
+    aggregate_id    VARCHAR(128) NOT NULL,
+    event_type      VARCHAR(128) NOT NULL,
+    payload         JSON,
+    status          VARCHAR(16) NOT NULL,
+    attempts        BIGINT NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME(3) NULL,
+    last_error      LONGTEXT,
+    created_at      DATETIME(3) NULL,
+    published_at    DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    INDEX idx_outbox_messages_tenant_id (tenant_id),
+    INDEX idx_outbox_messages_aggregate (aggregate_type, aggregate_id),
+    INDEX idx_outbox_messages_pending (status, next_attempt_at),
+    INDEX idx_outbox_messages_published_at (published_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1 @@
+-- Nothing to roll back, see the up migration.
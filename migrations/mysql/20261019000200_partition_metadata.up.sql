@@ -0,0 +1 @@
+-- Declarative partitioning is Postgres only, meta_data_models stays a single table here.
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    tenant_id    VARCHAR(64) NOT NULL DEFAULT 'default',
+    deleted_at   BIGINT UNSIGNED,
+    created_at   DATETIME(3) NULL,
+    created_by   LONGTEXT,
+    updated_at   DATETIME(3) NULL,
+    updated_by   LONGTEXT,
+    name         VARCHAR(128) NOT NULL,
+    prefix       VARCHAR(16) NOT NULL,
+    hash         VARCHAR(64) NOT NULL,
+    scopes       LONGTEXT,
+    expires_at   DATETIME(3) NULL,
+    last_used_at DATETIME(3) NULL,
+    revoked_at   DATETIME(3) NULL,
+    replaced_by  VARCHAR(16),
+    PRIMARY KEY (id),
+    INDEX idx_api_keys_tenant_id (tenant_id),
+    INDEX idx_api_keys_deleted_at (deleted_at),
+    UNIQUE INDEX idx_api_keys_prefix (prefix)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
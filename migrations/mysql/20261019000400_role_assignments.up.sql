@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS role_assignments (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    tenant_id  VARCHAR(64) NOT NULL DEFAULT 'default',
+    deleted_at BIGINT UNSIGNED,
+    created_at DATETIME(3) NULL,
+    created_by LONGTEXT,
+    updated_at DATETIME(3) NULL,
+    updated_by LONGTEXT,
+    subject    VARCHAR(128) NOT NULL,
+    role       VARCHAR(64) NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_role_assignments_tenant_id (tenant_id),
+    INDEX idx_role_assignments_deleted_at (deleted_at),
+    -- MySQL has no partial indexes, the deletion time keys soft deleted rows apart from the
+    -- live one, whose deleted_at is 0
+    UNIQUE INDEX idx_role_assignments_subject_role (tenant_id, subject, role, deleted_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
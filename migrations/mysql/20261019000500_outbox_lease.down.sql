@@ -0,0 +1 @@
+ALTER TABLE outbox_messages DROP COLUMN locked_until;
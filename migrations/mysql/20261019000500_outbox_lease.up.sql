@@ -0,0 +1,3 @@
+-- A relay claims the messages it publishes until locked_until, so the broker calls run
+-- outside the transaction that picked them.
+ALTER TABLE outbox_messages ADD COLUMN locked_until DATETIME(3) NULL;
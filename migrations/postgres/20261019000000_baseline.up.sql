@@ -0,0 +1,53 @@
+-- Schema as previously created by AutoMigrate. IF NOT EXISTS lets databases that were
+-- auto-migrated adopt the versioned migrations without changes.
+
+CREATE TABLE IF NOT EXISTS meta_data_models (
+    id         BIGSERIAL PRIMARY KEY,
+    tenant_id  VARCHAR(64) NOT NULL DEFAULT 'default',
+    deleted_at BIGINT,
+    created_at TIMESTAMPTZ,
+    created_by TEXT,
+    updated_at TIMESTAMPTZ,
+    updated_by TEXT,
+    uuid       UUID,
+    user_id    BIGINT,
+    metadata   JSONB
+);
+CREATE INDEX IF NOT EXISTS idx_meta_data_models_tenant_id ON meta_data_models (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_meta_data_models_deleted_at ON meta_data_models (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_meta_data_models_u_uid ON meta_data_models (uuid);
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id         BIGSERIAL PRIMARY KEY,
+    tenant_id  VARCHAR(64) NOT NULL DEFAULT 'default',
+    entity     TEXT,
+    entity_id  TEXT,
+    action     TEXT,
+    actor      TEXT,
+    before     JSONB,
+    after      JSONB,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_tenant_id ON audit_logs (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_entity ON audit_logs (entity, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor ON audit_logs (actor);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
+
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id              BIGSERIAL PRIMARY KEY,
+    tenant_id       VARCHAR(64) NOT NULL DEFAULT 'default',
+    aggregate_type  VARCHAR(64) NOT NULL,
+    aggregate_id    VARCHAR(128) NOT NULL,
+    event_type      VARCHAR(128) NOT NULL,
+    payload         JSONB,
+    status          VARCHAR(16) NOT NULL,
+    attempts        BIGINT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ,
+    last_error      TEXT,
+    created_at      TIMESTAMPTZ,
+    published_at    TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_tenant_id ON outbox_messages (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_aggregate ON outbox_messages (aggregate_type, aggregate_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_pending ON outbox_messages (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_published_at ON outbox_messages (published_at);
@@ -0,0 +1,2 @@
+ALTER TABLE audit_logs ALTER COLUMN action TYPE TEXT USING action::text;
+DROP TYPE IF EXISTS audit_action;
@@ -0,0 +1,3 @@
+-- audit_logs.action becomes the native audit_action type of audit.Actions.
+CREATE TYPE audit_action AS ENUM ('create', 'update', 'delete');
+ALTER TABLE audit_logs ALTER COLUMN action TYPE audit_action USING action::audit_action;
@@ -0,0 +1,32 @@
+-- Back to a single table. Partitions already detached by the maintainer are left alone.
+SET LOCAL statement_timeout = 0;
+
+DROP TRIGGER meta_data_models_claim_uuid ON meta_data_models;
+DROP FUNCTION meta_data_models_claim_uuid();
+DROP TABLE meta_data_uuids;
+
+CREATE TABLE meta_data_models_unpartitioned (
+    id         BIGINT NOT NULL DEFAULT nextval('meta_data_models_id_seq'),
+    tenant_id  VARCHAR(64) NOT NULL DEFAULT 'default',
+    deleted_at BIGINT,
+    created_at TIMESTAMPTZ,
+    created_by TEXT,
+    updated_at TIMESTAMPTZ,
+    updated_by TEXT,
+    uuid       UUID,
+    user_id    BIGINT,
+    metadata   JSONB
+);
+
+INSERT INTO meta_data_models_unpartitioned
+SELECT id, tenant_id, deleted_at, created_at, created_by, updated_at, updated_by, uuid, user_id, metadata
+FROM meta_data_models;
+
+ALTER SEQUENCE meta_data_models_id_seq OWNED BY meta_data_models_unpartitioned.id;
+DROP TABLE meta_data_models;
+ALTER TABLE meta_data_models_unpartitioned RENAME TO meta_data_models;
+
+ALTER TABLE meta_data_models ADD CONSTRAINT meta_data_models_pkey PRIMARY KEY (id);
+CREATE INDEX idx_meta_data_models_tenant_id ON meta_data_models (tenant_id);
+CREATE INDEX idx_meta_data_models_deleted_at ON meta_data_models (deleted_at);
+CREATE UNIQUE INDEX idx_meta_data_models_u_uid ON meta_data_models (uuid);
@@ -0,0 +1,90 @@
+-- meta_data_models becomes a table range partitioned by created_at, one partition per
+-- UTC month named meta_data_models_YYYY_MM and meta_data_models_default for rows no
+-- partition covers. Postgres wants the partition key in the primary key and in every
+-- unique index, so both gain created_at, which becomes NOT NULL; uuids are UUIDv7 and
+-- carry their creation time anyway. A uuid stays unique across the whole table through
+-- meta_data_uuids, which a trigger keeps in step. From here on the partition maintainer
+-- creates the upcoming months and detaches the expired ones.
+
+-- the copy of the whole table takes as long as it takes
+SET LOCAL statement_timeout = 0;
+
+ALTER TABLE meta_data_models RENAME TO meta_data_models_unpartitioned;
+ALTER TABLE meta_data_models_unpartitioned DROP CONSTRAINT meta_data_models_pkey;
+DROP INDEX IF EXISTS idx_meta_data_models_tenant_id;
+DROP INDEX IF EXISTS idx_meta_data_models_deleted_at;
+DROP INDEX IF EXISTS idx_meta_data_models_u_uid;
+
+CREATE TABLE meta_data_models (
+    id         BIGINT NOT NULL DEFAULT nextval('meta_data_models_id_seq'),
+    tenant_id  VARCHAR(64) NOT NULL DEFAULT 'default',
+    deleted_at BIGINT,
+    created_at TIMESTAMPTZ NOT NULL,
+    created_by TEXT,
+    updated_at TIMESTAMPTZ,
+    updated_by TEXT,
+    uuid       UUID,
+    user_id    BIGINT,
+    metadata   JSONB,
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+ALTER SEQUENCE meta_data_models_id_seq OWNED BY meta_data_models.id;
+
+CREATE INDEX idx_meta_data_models_tenant_id ON meta_data_models (tenant_id);
+CREATE INDEX idx_meta_data_models_deleted_at ON meta_data_models (deleted_at);
+CREATE UNIQUE INDEX idx_meta_data_models_u_uid ON meta_data_models (uuid, created_at);
+
+CREATE TABLE meta_data_models_default PARTITION OF meta_data_models DEFAULT;
+
+-- a partition for every month with rows up to two months ahead, the maintainer takes it
+-- from there
+DO $$
+DECLARE
+    month DATE;
+    last  DATE := (date_trunc('month', now() AT TIME ZONE 'UTC') + INTERVAL '2 months')::date;
+BEGIN
+    SELECT date_trunc('month', COALESCE(MIN(created_at), now()) AT TIME ZONE 'UTC')::date
+    INTO month
+    FROM meta_data_models_unpartitioned;
+
+    WHILE month <= last LOOP
+        EXECUTE format(
+            'CREATE TABLE %I PARTITION OF meta_data_models FOR VALUES FROM (%L) TO (%L)',
+            'meta_data_models_' || to_char(month, 'YYYY_MM'),
+            to_char(month, 'YYYY-MM-DD') || ' 00:00:00+00',
+            to_char(month + INTERVAL '1 month', 'YYYY-MM-DD') || ' 00:00:00+00'
+        );
+        month := (month + INTERVAL '1 month')::date;
+    END LOOP;
+END $$;
+
+INSERT INTO meta_data_models (id, tenant_id, deleted_at, created_at, created_by, updated_at, updated_by, uuid, user_id, metadata)
+SELECT id, tenant_id, deleted_at, COALESCE(created_at, updated_at, now()), created_by, updated_at, updated_by, uuid, user_id, metadata
+FROM meta_data_models_unpartitioned;
+
+DROP TABLE meta_data_models_unpartitioned;
+
+-- the uuids of detached partitions stay claimed, so a partition can be attached back
+CREATE TABLE meta_data_uuids (
+    uuid       UUID PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+INSERT INTO meta_data_uuids (uuid, created_at)
+SELECT uuid, created_at FROM meta_data_models WHERE uuid IS NOT NULL;
+
+CREATE FUNCTION meta_data_models_claim_uuid() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_OP <> 'INSERT' AND OLD.uuid IS NOT NULL THEN
+        DELETE FROM meta_data_uuids WHERE uuid = OLD.uuid;
+    END IF;
+    -- a uuid claimed twice fails with unique_violation, like the index it replaces
+    IF TG_OP <> 'DELETE' AND NEW.uuid IS NOT NULL THEN
+        INSERT INTO meta_data_uuids (uuid, created_at) VALUES (NEW.uuid, NEW.created_at);
+    END IF;
+    RETURN NULL;
+END $$;
+
+CREATE TRIGGER meta_data_models_claim_uuid
+    AFTER INSERT OR DELETE OR UPDATE OF uuid ON meta_data_models
+    FOR EACH ROW EXECUTE FUNCTION meta_data_models_claim_uuid();
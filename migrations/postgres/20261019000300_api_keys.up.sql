@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           BIGSERIAL PRIMARY KEY,
+    tenant_id    VARCHAR(64) NOT NULL DEFAULT 'default',
+    deleted_at   BIGINT,
+    created_at   TIMESTAMPTZ,
+    created_by   TEXT,
+    updated_at   TIMESTAMPTZ,
+    updated_by   TEXT,
+    name         VARCHAR(128) NOT NULL,
+    prefix       VARCHAR(16) NOT NULL,
+    hash         VARCHAR(64) NOT NULL,
+    scopes       TEXT,
+    expires_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ,
+    replaced_by  VARCHAR(16)
+);
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant_id ON api_keys (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_api_keys_deleted_at ON api_keys (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_prefix ON api_keys (prefix);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS outbox_messages;
+DROP TABLE IF EXISTS audit_logs;
+DROP TABLE IF EXISTS meta_data_models;
@@ -0,0 +1,53 @@
+-- Schema of the models at the time of the Postgres baseline, in the types GORM maps
+-- them to on SQLite.
+
+CREATE TABLE IF NOT EXISTS meta_data_models (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    tenant_id  text NOT NULL DEFAULT 'default',
+    deleted_at integer,
+    created_at datetime,
+    created_by text,
+    updated_at datetime,
+    updated_by text,
+    uuid       text,
+    user_id    integer,
+    metadata   json
+);
+CREATE INDEX IF NOT EXISTS idx_meta_data_models_tenant_id ON meta_data_models (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_meta_data_models_deleted_at ON meta_data_models (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_meta_data_models_u_uid ON meta_data_models (uuid);
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    tenant_id  text NOT NULL DEFAULT 'default',
+    entity     text,
+    entity_id  text,
+    action     text,
+    actor      text,
+    before     json,
+    after      json,
+    created_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_tenant_id ON audit_logs (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_entity ON audit_logs (entity, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor ON audit_logs (actor);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
+
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id              integer PRIMARY KEY AUTOINCREMENT,
+    tenant_id       text NOT NULL DEFAULT 'default',
+    aggregate_type  text NOT NULL,
+    aggregate_id    text NOT NULL,
+    event_type      text NOT NULL,
+    payload         json,
+    status          text NOT NULL,
+    attempts        integer NOT NULL DEFAULT 0,
+    next_attempt_at datetime,
+    last_error      text,
+    created_at      datetime,
+    published_at    datetime
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_tenant_id ON outbox_messages (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_aggregate ON outbox_messages (aggregate_type, aggregate_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_pending ON outbox_messages (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_published_at ON outbox_messages (published_at);
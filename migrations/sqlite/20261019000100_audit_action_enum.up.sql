@@ -0,0 +1 @@
+-- Native enum types are Postgres only, audit_logs.action stays a text column here.
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           integer PRIMARY KEY AUTOINCREMENT,
+    tenant_id    text NOT NULL DEFAULT 'default',
+    deleted_at   integer,
+    created_at   datetime,
+    created_by   text,
+    updated_at   datetime,
+    updated_by   text,
+    name         text NOT NULL,
+    prefix       text NOT NULL,
+    hash         text NOT NULL,
+    scopes       text,
+    expires_at   datetime,
+    last_used_at datetime,
+    revoked_at   datetime,
+    replaced_by  text
+);
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant_id ON api_keys (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_api_keys_deleted_at ON api_keys (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_prefix ON api_keys (prefix);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS role_assignments (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    tenant_id  text NOT NULL DEFAULT 'default',
+    deleted_at integer,
+    created_at datetime,
+    created_by text,
+    updated_at datetime,
+    updated_by text,
+    subject    text NOT NULL,
+    role       text NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_role_assignments_tenant_id ON role_assignments (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_role_assignments_deleted_at ON role_assignments (deleted_at);
+-- soft deleted rows keep a deleted_at timestamp, live ones 0, so a role can be assigned again
+-- once unassigned
+CREATE UNIQUE INDEX IF NOT EXISTS idx_role_assignments_subject_role ON role_assignments (tenant_id, subject, role) WHERE deleted_at = 0;
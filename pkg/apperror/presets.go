@@ -8,7 +8,10 @@ var (
 	ErrUnauthorized = New("UNAUTHORIZED", "Unauthorized Request", http.StatusUnauthorized)
 	ErrBadRequest   = New("BAD_REQUEST", "Invalid request param/body", http.StatusBadRequest)
 	ErrForbidden    = New("FORBIDDEN", "Forbidden request", http.StatusForbidden)
+	ErrConflict     = New("CONFLICT", "Conflicts with an existing resource", http.StatusConflict)
 	ErrServer       = New("SERVER", "Internal Server error", http.StatusInternalServerError)
+	ErrUnavailable  = New("UNAVAILABLE", "Service temporarily unavailable", http.StatusServiceUnavailable)
+	ErrTimeout      = New("TIMEOUT", "Request timed out", http.StatusGatewayTimeout)
 )
 
 func ResolveError(statusCode int) error {
@@ -23,6 +26,15 @@ func ResolveError(statusCode int) error {
 	if statusCode == http.StatusForbidden {
 		return ErrForbidden
 	}
+	if statusCode == http.StatusConflict {
+		return ErrConflict
+	}
+	if statusCode == http.StatusServiceUnavailable {
+		return ErrUnavailable
+	}
+	if statusCode == http.StatusGatewayTimeout {
+		return ErrTimeout
+	}
 	if statusCode >= http.StatusInternalServerError {
 		return ErrServer
 	}
@@ -0,0 +1,89 @@
+package faker
+
+type City struct {
+	English, Persian               string
+	CountryEnglish, CountryPersian string
+}
+
+// Name returns the city in lang.
+func (c City) Name(lang Lang) string {
+	if lang == Persian {
+		return c.Persian
+	}
+	return c.English
+}
+
+// Location returns "city, country" in lang.
+func (c City) Location(lang Lang) string {
+	if lang == Persian {
+		return c.Persian + "، " + c.CountryPersian
+	}
+	return c.English + ", " + c.CountryEnglish
+}
+
+var cities = []City{
+	{"Tehran", "تهران", "Iran", "ایران"},
+	{"Isfahan", "اصفهان", "Iran", "ایران"},
+	{"Shiraz", "شیراز", "Iran", "ایران"},
+	{"Tabriz", "تبریز", "Iran", "ایران"},
+	{"Mashhad", "مشهد", "Iran", "ایران"},
+	{"Yazd", "یزد", "Iran", "ایران"},
+	{"Rasht", "رشت", "Iran", "ایران"},
+	{"Kish", "کیش", "Iran", "ایران"},
+	{"Kerman", "کرمان", "Iran", "ایران"},
+	{"Hamedan", "همدان", "Iran", "ایران"},
+	{"Istanbul", "استانبول", "Turkey", "ترکیه"},
+	{"Dubai", "دبی", "UAE", "امارات"},
+	{"Berlin", "برلین", "Germany", "آلمان"},
+	{"London", "لندن", "UK", "انگلستان"},
+	{"Toronto", "تورنتو", "Canada", "کانادا"},
+	{"Paris", "پاریس", "France", "فرانسه"},
+}
+
+type words struct {
+	templates  []string
+	nouns      []string
+	adjectives []string
+}
+
+var vocabulary = map[Lang]words{
+	English: {
+		templates: []string{
+			"Just got back from {city}, the {noun} was {adj}.",
+			"Has anyone tried the {noun} near {city}? Looks {adj}.",
+			"Spent the whole afternoon at the {noun}. Honestly {adj}.",
+			"Selling my old camera, pickup in {city} only.",
+			"Weekend plans: {noun}, friends and a lot of tea.",
+			"The {noun} in {city} is {adj} this time of year.",
+			"New photos from the {noun}, let me know what you think!",
+			"Looking for a {adj} {noun} in {city}, any recommendations?",
+		},
+		nouns: []string{
+			"coffee shop", "sunset", "bazaar", "bookstore", "concert", "hike", "museum",
+			"garden", "breakfast", "view", "street food", "park",
+		},
+		adjectives: []string{
+			"amazing", "quiet", "crowded", "beautiful", "unforgettable", "cozy", "overpriced",
+			"colorful",
+		},
+	},
+	Persian: {
+		templates: []string{
+			"تازه از {city} برگشتم، {noun} واقعاً {adj} بود.",
+			"کسی {noun} نزدیک {city} رو امتحان کرده؟ به نظر {adj} میاد.",
+			"کل بعدازظهر توی {noun} بودم. خیلی {adj} بود.",
+			"دوربین قدیمیم رو می‌فروشم، فقط تحویل در {city}.",
+			"برنامه‌ی آخر هفته: {noun}، دوستان و کلی چای.",
+			"{noun} در {city} این موقع سال {adj} است.",
+			"عکس‌های جدید از {noun}، نظرتون چیه؟",
+			"دنبال یک {noun} {adj} در {city} هستم، پیشنهادی دارید؟",
+		},
+		nouns: []string{
+			"کافه", "غروب", "بازار", "کتاب‌فروشی", "کنسرت", "کوه‌پیمایی", "موزه", "باغ",
+			"صبحانه", "منظره", "غذای خیابانی", "پارک",
+		},
+		adjectives: []string{
+			"فوق‌العاده", "آرام", "شلوغ", "زیبا", "به‌یادماندنی", "دنج", "گران", "رنگارنگ",
+		},
+	},
+}
@@ -0,0 +1,107 @@
+// Package faker generates fake content for development data and load tests. A Faker is
+// deterministic: the same seed and stream always yield the same values.
+package faker
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Lang string
+
+const (
+	English Lang = "en"
+	Persian Lang = "fa"
+)
+
+// Faker is not safe for concurrent use; give every goroutine its own stream.
+type Faker struct {
+	rand *rand.Rand
+}
+
+// New returns the Faker of one stream of seed, streams of the same seed are independent
+// so batches can be generated in any order.
+func New(seed int64, stream uint64) *Faker {
+	return &Faker{rand: rand.New(rand.NewPCG(uint64(seed), stream))}
+}
+
+// Rand exposes the source, e.g. for rand.NewZipf.
+func (f *Faker) Rand() *rand.Rand {
+	return f.rand
+}
+
+func (f *Faker) IntN(n int) int {
+	return f.rand.IntN(n)
+}
+
+// Between returns an int in [min, max].
+func (f *Faker) Between(min, max int) int {
+	return min + f.rand.IntN(max-min+1)
+}
+
+// Lang picks Persian with the probability persianShare, English otherwise.
+func (f *Faker) Lang(persianShare float64) Lang {
+	if f.rand.Float64() < persianShare {
+		return Persian
+	}
+	return English
+}
+
+// City returns one of the known cities.
+func (f *Faker) City() City {
+	return cities[f.rand.IntN(len(cities))]
+}
+
+// Sentence fills a random template of lang with places, things and adjectives.
+func (f *Faker) Sentence(lang Lang, city City) string {
+	words := vocabulary[lang]
+	template := words.templates[f.rand.IntN(len(words.templates))]
+	return strings.NewReplacer(
+		"{city}", city.Name(lang),
+		"{noun}", words.nouns[f.rand.IntN(len(words.nouns))],
+		"{adj}", words.adjectives[f.rand.IntN(len(words.adjectives))],
+	).Replace(template)
+}
+
+// Paragraph joins one to three sentences.
+func (f *Faker) Paragraph(lang Lang, city City) string {
+	sentences := make([]string, f.Between(1, 3))
+	for i := range sentences {
+		sentences[i] = f.Sentence(lang, city)
+	}
+	return strings.Join(sentences, " ")
+}
+
+var imageSizes = [][2]int{{1080, 1080}, {1080, 1350}, {1920, 1080}, {800, 600}}
+
+// ImageURL points at a placeholder photo that is stable for the generated seed.
+func (f *Faker) ImageURL() string {
+	size := imageSizes[f.rand.IntN(len(imageSizes))]
+	return fmt.Sprintf("https://picsum.photos/seed/%016x/%d/%d", f.rand.Uint64(), size[0], size[1])
+}
+
+// TimeBetween returns a time in [from, to), truncated to the millisecond like the
+// timestamps databases store.
+func (f *Faker) TimeBetween(from, to time.Time) time.Time {
+	span := to.Sub(from)
+	if span <= 0 {
+		return from
+	}
+	return from.Add(time.Duration(f.rand.Int64N(int64(span)))).Truncate(time.Millisecond)
+}
+
+// UUIDv7 returns a version 7 UUID with the timestamp at and random bits from the stream,
+// so ids sort by creation time and are still reproducible.
+func (f *Faker) UUIDv7(at time.Time) uuid.UUID {
+	var id uuid.UUID
+	binary.BigEndian.PutUint64(id[8:], f.rand.Uint64())
+	binary.BigEndian.PutUint64(id[:8], uint64(at.UnixMilli())<<16|f.rand.Uint64()&0x0fff)
+	id[6] = id[6]&0x0f | 0x70 // version 7
+	id[8] = id[8]&0x3f | 0x80 // RFC 4122 variant
+	return id
+}
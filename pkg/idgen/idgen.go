@@ -0,0 +1,110 @@
+package idgen
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oklog/ulid/v2"
+)
+
+var ErrMalformedID = errors.New("malformed id")
+
+type Strategy string
+
+const (
+	StrategyUUIDv7 Strategy = "uuidv7"
+	StrategyULID   Strategy = "ulid"
+
+	DefaultStrategy = StrategyUUIDv7
+)
+
+// Generator produces time-ordered 128-bit IDs that fit a native uuid column.
+type Generator interface {
+	NewID() (uuid.UUID, error)
+}
+
+// Strategist is implemented by models that pick their own ID strategy.
+type Strategist interface {
+	IDStrategy() Strategy
+}
+
+type uuidV7Generator struct{}
+
+func (uuidV7Generator) NewID() (uuid.UUID, error) {
+	return uuid.NewV7()
+}
+
+// ulidGenerator is monotonic within the same millisecond, so IDs sort in creation order.
+type ulidGenerator struct {
+	mu      sync.Mutex
+	entropy *ulid.MonotonicEntropy
+}
+
+func (g *ulidGenerator) NewID() (uuid.UUID, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	id, err := ulid.New(ulid.Timestamp(time.Now()), g.entropy)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return uuid.UUID(id), nil
+}
+
+var generators = map[Strategy]Generator{
+	StrategyUUIDv7: uuidV7Generator{},
+	StrategyULID:   &ulidGenerator{entropy: ulid.Monotonic(rand.Reader, 0)},
+}
+
+// For returns the generator of strategy.
+func For(strategy Strategy) (Generator, error) {
+	generator, ok := generators[strategy]
+	if !ok {
+		return nil, fmt.Errorf("unknown id strategy %q", strategy)
+	}
+	return generator, nil
+}
+
+// NewFor generates an ID with the strategy of model, or DefaultStrategy.
+func NewFor(model interface{}) (uuid.UUID, error) {
+	strategy := DefaultStrategy
+	if s, ok := model.(Strategist); ok {
+		strategy = s.IDStrategy()
+	}
+
+	generator, err := For(strategy)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return generator.NewID()
+}
+
+// Parse accepts the canonical UUID form or the 26 character ULID form.
+func Parse(s string) (uuid.UUID, error) {
+	if len(s) == ulid.EncodedSize {
+		id, err := ulid.ParseStrict(s)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("%w: %v", ErrMalformedID, err)
+		}
+		return uuid.UUID(id), nil
+	}
+
+	id, err := uuid.Parse(s)
+	if err != nil || len(s) != 36 {
+		return uuid.Nil, fmt.Errorf("%w: %q", ErrMalformedID, s)
+	}
+	return id, nil
+}
+
+// Time returns the creation time a UUIDv7 carries, to the millisecond; ok is false for
+// other versions, which carry none.
+func Time(id uuid.UUID) (t time.Time, ok bool) {
+	if id.Version() != 7 {
+		return time.Time{}, false
+	}
+	return time.Unix(id.Time().UnixTime()), true
+}
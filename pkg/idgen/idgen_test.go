@@ -0,0 +1,24 @@
+package idgen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestTime(t *testing.T) {
+	before := time.Now().Truncate(time.Millisecond)
+	id, err := NewFor(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at, ok := Time(id)
+	if !ok || at.Before(before) || at.After(time.Now()) {
+		t.Fatalf("a UUIDv7 generated after %s carries %s, %v", before, at, ok)
+	}
+
+	if _, ok := Time(uuid.New()); ok {
+		t.Fatal("a UUIDv4 carries a time")
+	}
+}
@@ -0,0 +1,96 @@
+package jwt
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-json-experiment/json/v1"
+)
+
+// Claims are the registered claims of RFC 7519 plus the roles and scopes the API
+// authorizes with.
+type Claims struct {
+	Issuer    string       `json:"iss,omitempty"`
+	Subject   string       `json:"sub,omitempty"`
+	Audience  Audience     `json:"aud,omitempty"`
+	ExpiresAt *NumericDate `json:"exp,omitempty"`
+	NotBefore *NumericDate `json:"nbf,omitempty"`
+	IssuedAt  *NumericDate `json:"iat,omitempty"`
+	ID        string       `json:"jti,omitempty"`
+	Roles     []string     `json:"roles,omitempty"`
+	// Scopes is the OAuth 2 "scope" claim, a space separated string; an array is
+	// accepted too.
+	Scopes Scopes `json:"scope,omitempty"`
+	// Tenant is the tenant the token is bound to.
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// NumericDate is a JSON number of seconds since the epoch, fractions allowed.
+type NumericDate struct {
+	time.Time
+}
+
+func NewNumericDate(t time.Time) *NumericDate {
+	return &NumericDate{Time: t.Truncate(time.Second)}
+}
+
+func (d NumericDate) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(d.Unix(), 10)), nil
+}
+
+func (d *NumericDate) UnmarshalJSON(data []byte) error {
+	seconds, err := strconv.ParseFloat(string(data), 64)
+	if err != nil || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return ErrMalformed
+	}
+	whole, fraction := math.Modf(seconds)
+	d.Time = time.Unix(int64(whole), int64(fraction*1e9)).UTC()
+	return nil
+}
+
+// Audience is a single string or an array of strings.
+type Audience []string
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return ErrMalformed
+	}
+	*a = many
+	return nil
+}
+
+// Scopes is a space separated string or an array of strings.
+type Scopes []string
+
+func (s Scopes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strings.Join(s, " "))
+}
+
+func (s *Scopes) UnmarshalJSON(data []byte) error {
+	var joined string
+	if err := json.Unmarshal(data, &joined); err == nil {
+		*s = strings.Fields(joined)
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return ErrMalformed
+	}
+	*s = many
+	return nil
+}
@@ -0,0 +1,210 @@
+// Package jwt signs and verifies compact JWS tokens (RFC 7515, 7519) with HS256, RS256
+// and EdDSA (Ed25519) on the standard library alone.
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-json-experiment/json/v1"
+	"github.com/samber/lo"
+)
+
+var (
+	ErrMalformed     = errors.New("malformed token")
+	ErrAlgorithm     = errors.New("unsupported signing algorithm")
+	ErrUnknownKey    = errors.New("unknown signing key")
+	ErrSignature     = errors.New("invalid signature")
+	ErrMissingExpiry = errors.New("token has no expiry")
+	ErrExpired       = errors.New("token is expired")
+	ErrNotYetValid   = errors.New("token is not valid yet")
+	ErrIssuer        = errors.New("unexpected issuer")
+	ErrAudience      = errors.New("unexpected audience")
+)
+
+type header struct {
+	Algorithm string   `json:"alg"`
+	KeyID     string   `json:"kid,omitempty"`
+	Type      string   `json:"typ,omitempty"`
+	Critical  []string `json:"crit,omitempty"`
+}
+
+type VerifierOptions struct {
+	Keys []*Key
+	// Issuer, when set, is the only iss accepted.
+	Issuer string
+	// Audience, when set, must share a value with the aud of the token.
+	Audience []string
+	// ClockSkew is the tolerance of the exp, nbf and iat checks.
+	ClockSkew time.Duration
+}
+
+// Verifier checks the signature and the claims of tokens. Every token must carry an
+// exp; a kid picks the key, without one every key of the token's algorithm is tried.
+type Verifier struct {
+	opts VerifierOptions
+	now  func() time.Time
+}
+
+func NewVerifier(opts VerifierOptions) (*Verifier, error) {
+	if len(opts.Keys) == 0 {
+		return nil, errors.New("jwt verifier needs at least one key")
+	}
+	return &Verifier{opts: opts, now: time.Now}, nil
+}
+
+// Verify returns the claims of a valid token; errors wrap one of the Err values.
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+	var head header
+	if err := decodeSegment(parts[0], &head); err != nil {
+		return nil, err
+	}
+	if len(head.Critical) > 0 {
+		return nil, fmt.Errorf("%w: critical extensions %v", ErrMalformed, head.Critical)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	if err := v.verifySignature(head, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if err := v.validate(&claims); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+func (v *Verifier) verifySignature(head header, input string, signature []byte) error {
+	switch head.Algorithm {
+	case HS256, RS256, EdDSA:
+	default:
+		return fmt.Errorf("%w: %q", ErrAlgorithm, head.Algorithm)
+	}
+
+	candidates := lo.Filter(v.opts.Keys, func(key *Key, _ int) bool {
+		return key.Algorithm == head.Algorithm && (head.KeyID == "" || key.ID == head.KeyID)
+	})
+	if len(candidates) == 0 {
+		return fmt.Errorf("%w: %s kid %q", ErrUnknownKey, head.Algorithm, head.KeyID)
+	}
+	for _, key := range candidates {
+		if key.verify([]byte(input), signature) {
+			return nil
+		}
+	}
+	return ErrSignature
+}
+
+func (v *Verifier) validate(claims *Claims) error {
+	now := v.now()
+	skew := v.opts.ClockSkew
+
+	if claims.ExpiresAt == nil {
+		return ErrMissingExpiry
+	}
+	if !now.Before(claims.ExpiresAt.Add(skew)) {
+		return ErrExpired
+	}
+	if claims.NotBefore != nil && now.Add(skew).Before(claims.NotBefore.Time) {
+		return ErrNotYetValid
+	}
+	if claims.IssuedAt != nil && now.Add(skew).Before(claims.IssuedAt.Time) {
+		return ErrNotYetValid
+	}
+	if v.opts.Issuer != "" && claims.Issuer != v.opts.Issuer {
+		return fmt.Errorf("%w: %q", ErrIssuer, claims.Issuer)
+	}
+	if len(v.opts.Audience) > 0 && len(lo.Intersect(v.opts.Audience, []string(claims.Audience))) == 0 {
+		return fmt.Errorf("%w: %v", ErrAudience, []string(claims.Audience))
+	}
+	return nil
+}
+
+func (k *Key) verify(input, signature []byte) bool {
+	switch k.Algorithm {
+	case HS256:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write(input)
+		return hmac.Equal(signature, mac.Sum(nil))
+	case RS256:
+		digest := sha256.Sum256(input)
+		return rsa.VerifyPKCS1v15(k.public.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) == nil
+	case EdDSA:
+		return ed25519.Verify(k.public.(ed25519.PublicKey), input, signature)
+	}
+	return false
+}
+
+// Sign encodes claims as a token signed with key: a []byte secret for HS256, an
+// *rsa.PrivateKey for RS256 or an ed25519.PrivateKey for EdDSA.
+func Sign(claims interface{}, kid string, key interface{}) (string, error) {
+	head := header{KeyID: kid, Type: "JWT"}
+	switch key.(type) {
+	case []byte:
+		head.Algorithm = HS256
+	case *rsa.PrivateKey:
+		head.Algorithm = RS256
+	case ed25519.PrivateKey:
+		head.Algorithm = EdDSA
+	default:
+		return "", fmt.Errorf("%w: key type %T", ErrAlgorithm, key)
+	}
+
+	rawHeader, err := json.Marshal(head)
+	if err != nil {
+		return "", err
+	}
+	rawClaims, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	input := base64.RawURLEncoding.EncodeToString(rawHeader) + "." + base64.RawURLEncoding.EncodeToString(rawClaims)
+
+	var signature []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(input))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(input))
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(k, []byte(input))
+	}
+	if err != nil {
+		return "", err
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrMalformed
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return nil
+}
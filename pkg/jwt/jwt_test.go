@@ -0,0 +1,304 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testSecret = "jwt-test-secret-at-least-32-bytes!"
+
+var now = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+var testRSAKey = sync.OnceValue(func() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return key
+})
+
+type signer struct {
+	name    string
+	private interface{}
+	key     *Key
+}
+
+// signers signs with one key of every algorithm, each verified by key.
+func signers(t *testing.T) []signer {
+	t.Helper()
+	hmacKey, err := NewHMACKey("hs", []byte(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := NewPublicKey("rs", &testRSAKey().PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edKey, err := NewPublicKey("ed", edPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []signer{
+		{name: HS256, private: []byte(testSecret), key: hmacKey},
+		{name: RS256, private: testRSAKey(), key: rsaKey},
+		{name: EdDSA, private: edPrivate, key: edKey},
+	}
+}
+
+func newTestVerifier(t *testing.T, opts VerifierOptions) *Verifier {
+	t.Helper()
+	verifier, err := NewVerifier(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier.now = func() time.Time { return now }
+	return verifier
+}
+
+func validClaims() Claims {
+	return Claims{
+		Subject:   "7",
+		IssuedAt:  NewNumericDate(now),
+		ExpiresAt: NewNumericDate(now.Add(time.Minute)),
+	}
+}
+
+func sign(t *testing.T, claims interface{}, kid string, key interface{}) string {
+	t.Helper()
+	token, err := Sign(claims, kid, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// forge assembles a token from a raw header, raw claims and a signature.
+func forge(head, claims string, signature []byte) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(head)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(claims)) + "." +
+		base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestVerifierVerifiesEverySigningAlgorithm(t *testing.T) {
+	all := signers(t)
+	keys := []*Key{all[0].key, all[1].key, all[2].key}
+	verifier := newTestVerifier(t, VerifierOptions{Keys: keys})
+	_, otherEdPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range all {
+		t.Run(s.name, func(t *testing.T) {
+			token := sign(t, validClaims(), s.key.ID, s.private)
+			claims, err := verifier.Verify(token)
+			if err != nil || claims.Subject != "7" {
+				t.Fatalf("claims %+v: %v", claims, err)
+			}
+
+			// without a kid every key of the algorithm is tried
+			if _, err := verifier.Verify(sign(t, validClaims(), "", s.private)); err != nil {
+				t.Fatalf("a token without kid was refused: %v", err)
+			}
+			if _, err := verifier.Verify(sign(t, validClaims(), "missing", s.private)); !errors.Is(err, ErrUnknownKey) {
+				t.Fatalf("expected ErrUnknownKey, got %v", err)
+			}
+
+			parts := strings.Split(token, ".")
+			tampered := sign(t, Claims{Subject: "8", ExpiresAt: NewNumericDate(now.Add(time.Minute))}, s.key.ID, s.private)
+			forged := parts[0] + "." + strings.Split(tampered, ".")[1] + "." + parts[2]
+			if _, err := verifier.Verify(forged); !errors.Is(err, ErrSignature) {
+				t.Fatalf("expected ErrSignature for changed claims, got %v", err)
+			}
+		})
+	}
+
+	wrongKeys := map[string]interface{}{
+		HS256: []byte("another-secret-of-at-least-32-bytes"),
+		EdDSA: otherEdPrivate,
+	}
+	for alg, private := range wrongKeys {
+		kid := map[string]string{HS256: "hs", EdDSA: "ed"}[alg]
+		if _, err := verifier.Verify(sign(t, validClaims(), kid, private)); !errors.Is(err, ErrSignature) {
+			t.Fatalf("%s signed with another key: expected ErrSignature, got %v", alg, err)
+		}
+	}
+}
+
+func TestVerifierChecksTheTimesWithClockSkew(t *testing.T) {
+	skew := 30 * time.Second
+	verifier := newTestVerifier(t, VerifierOptions{Keys: []*Key{signers(t)[0].key}, ClockSkew: skew})
+	at := func(d time.Duration) *NumericDate { return NewNumericDate(now.Add(d)) }
+
+	tests := []struct {
+		name   string
+		claims Claims
+		want   error
+	}{
+		{"valid", Claims{ExpiresAt: at(time.Minute)}, nil},
+		{"no expiry", Claims{}, ErrMissingExpiry},
+		{"expired within the skew", Claims{ExpiresAt: at(-20 * time.Second)}, nil},
+		{"expired past the skew", Claims{ExpiresAt: at(-30 * time.Second)}, ErrExpired},
+		{"not before within the skew", Claims{ExpiresAt: at(time.Minute), NotBefore: at(20 * time.Second)}, nil},
+		{"not before past the skew", Claims{ExpiresAt: at(time.Minute), NotBefore: at(time.Minute)}, ErrNotYetValid},
+		{"issued in the future", Claims{ExpiresAt: at(2 * time.Minute), IssuedAt: at(time.Minute)}, ErrNotYetValid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.Verify(sign(t, tt.claims, "hs", []byte(testSecret)))
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifierChecksIssuerAndAudience(t *testing.T) {
+	verifier := newTestVerifier(t, VerifierOptions{
+		Keys:     []*Key{signers(t)[0].key},
+		Issuer:   "https://issuer.example",
+		Audience: []string{"api", "admin"},
+	})
+	claims := func(iss string, aud ...string) Claims {
+		c := validClaims()
+		c.Issuer, c.Audience = iss, aud
+		return c
+	}
+
+	tests := []struct {
+		name   string
+		claims Claims
+		want   error
+	}{
+		{"matching", claims("https://issuer.example", "api"), nil},
+		{"one audience of many", claims("https://issuer.example", "web", "admin"), nil},
+		{"other issuer", claims("https://evil.example", "api"), ErrIssuer},
+		{"no issuer", claims("", "api"), ErrIssuer},
+		{"other audience", claims("https://issuer.example", "web"), ErrAudience},
+		{"no audience", claims("https://issuer.example"), ErrAudience},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.Verify(sign(t, tt.claims, "hs", []byte(testSecret)))
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifierRejectsUnsafeAndMalformedTokens(t *testing.T) {
+	all := signers(t)
+	verifier := newTestVerifier(t, VerifierOptions{Keys: []*Key{all[1].key}})
+	claims := fmt.Sprintf(`{"sub":"7","exp":%d}`, now.Add(time.Minute).Unix())
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: mustMarshalPKIX(t, &testRSAKey().PublicKey)})
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"alg none", forge(`{"alg":"none"}`, claims, nil), ErrAlgorithm},
+		{"alg None", forge(`{"alg":"None","kid":"rs"}`, claims, nil), ErrAlgorithm},
+		{"unsupported alg", forge(`{"alg":"RS512","kid":"rs"}`, claims, []byte("sig")), ErrAlgorithm},
+		// the RSA public key is public, an HS256 token signed with it must not verify
+		{"algorithm confusion", sign(t, validClaims(), "rs", publicPEM), ErrUnknownKey},
+		{"algorithm confusion without kid", sign(t, validClaims(), "", publicPEM), ErrUnknownKey},
+		{"two segments", "a.b", ErrMalformed},
+		{"bad base64", "!!." + strings.SplitN(sign(t, validClaims(), "rs", testRSAKey()), ".", 2)[1], ErrMalformed},
+		{"critical extension", forge(`{"alg":"RS256","kid":"rs","crit":["exp"]}`, claims, []byte("sig")), ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := verifier.Verify(tt.token); !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func mustMarshalPKIX(t *testing.T, public interface{}) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func TestNewKeysRefuseWeakKeys(t *testing.T) {
+	if _, err := NewHMACKey("short", []byte("too short")); err == nil {
+		t.Error("a short HMAC secret was accepted")
+	}
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewPublicKey("small", &small.PublicKey); err == nil {
+		t.Error("a 1024 bit RSA key was accepted")
+	}
+	if _, err := NewPublicKey("ed", ed25519.PublicKey("short")); err == nil {
+		t.Error("a truncated Ed25519 key was accepted")
+	}
+}
+
+func TestParseJWKSLoadsEveryKeyType(t *testing.T) {
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b64 := base64.RawURLEncoding.EncodeToString
+	rsaPublic := testRSAKey().PublicKey
+	set := fmt.Sprintf(`{"keys":[
+		{"kty":"oct","kid":"hs","alg":"HS256","k":%q},
+		{"kty":"RSA","kid":"rs","alg":"RS256","use":"sig","n":%q,"e":%q},
+		{"kty":"OKP","kid":"ed","crv":"Ed25519","x":%q},
+		{"kty":"RSA","kid":"enc","use":"enc","n":"AQAB","e":"AQAB"}
+	]}`, b64([]byte(testSecret)), b64(rsaPublic.N.Bytes()), b64(big.NewInt(int64(rsaPublic.E)).Bytes()), b64(edPublic))
+
+	keys, err := ParseJWKS([]byte(set))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 3 {
+		t.Fatalf("%d keys loaded, the encryption key must be skipped", len(keys))
+	}
+	verifier := newTestVerifier(t, VerifierOptions{Keys: keys})
+	for kid, private := range map[string]interface{}{"hs": []byte(testSecret), "rs": testRSAKey(), "ed": edPrivate} {
+		if _, err := verifier.Verify(sign(t, validClaims(), kid, private)); err != nil {
+			t.Errorf("the %s key of the set doesn't verify: %v", kid, err)
+		}
+	}
+}
+
+func TestParseJWKSRejectsInvalidKeys(t *testing.T) {
+	tests := map[string]string{
+		"not json":          `{"keys":`,
+		"alg mismatch":      `{"keys":[{"kty":"OKP","crv":"Ed25519","alg":"RS256","x":"` + strings.Repeat("A", 43) + `"}]}`,
+		"unknown kty":       `{"keys":[{"kty":"EC","crv":"P-256"}]}`,
+		"unknown curve":     `{"keys":[{"kty":"OKP","crv":"X25519","x":"AA"}]}`,
+		"short secret":      `{"keys":[{"kty":"oct","k":"c2hvcnQ"}]}`,
+		"invalid exponent":  `{"keys":[{"kty":"RSA","n":"AQAB","e":""}]}`,
+		"small rsa modulus": `{"keys":[{"kty":"RSA","n":"AQAB","e":"AQAB"}]}`,
+	}
+	for name, set := range tests {
+		if _, err := ParseJWKS([]byte(set)); err == nil {
+			t.Errorf("%s: the set was accepted", name)
+		}
+	}
+}
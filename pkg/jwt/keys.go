@@ -0,0 +1,156 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+
+	"github.com/go-json-experiment/json/v1"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+// minRSABits is the smallest RSA modulus accepted, as recommended by RFC 7518.
+const minRSABits = 2048
+
+// Key verifies the tokens signed with one algorithm. The algorithm is bound to the key,
+// never taken from the token alone, so a token can't make an RSA public key be used as
+// an HMAC secret.
+type Key struct {
+	ID        string
+	Algorithm string
+	secret    []byte
+	public    crypto.PublicKey
+}
+
+// NewHMACKey is a shared HS256 secret; it needs at least 32 bytes.
+func NewHMACKey(id string, secret []byte) (*Key, error) {
+	if len(secret) < 32 {
+		return nil, fmt.Errorf("HS256 key %q: the secret needs at least 32 bytes", id)
+	}
+	return &Key{ID: id, Algorithm: HS256, secret: secret}, nil
+}
+
+// NewPublicKey wraps an *rsa.PublicKey for RS256 or an ed25519.PublicKey for EdDSA.
+func NewPublicKey(id string, public crypto.PublicKey) (*Key, error) {
+	switch k := public.(type) {
+	case *rsa.PublicKey:
+		if k.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RS256 key %q: %d bits, at least %d needed", id, k.N.BitLen(), minRSABits)
+		}
+		return &Key{ID: id, Algorithm: RS256, public: k}, nil
+	case ed25519.PublicKey:
+		if len(k) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("EdDSA key %q: invalid size", id)
+		}
+		return &Key{ID: id, Algorithm: EdDSA, public: k}, nil
+	default:
+		return nil, fmt.Errorf("key %q: unsupported public key type %T", id, public)
+	}
+}
+
+// ParsePublicKeyPEM reads a PKIX "PUBLIC KEY" block, or a PKCS #1 "RSA PUBLIC KEY" one.
+func ParsePublicKeyPEM(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %q: no PEM block found", id)
+	}
+	var public crypto.PublicKey
+	var err error
+	switch block.Type {
+	case "PUBLIC KEY":
+		public, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		public, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("key %q: unexpected PEM block %q", id, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", id, err)
+	}
+	return NewPublicKey(id, public)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// oct
+	K string `json:"k"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// OKP
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+}
+
+// ParseJWKS reads a JSON Web Key Set with oct (HS256), RSA (RS256) and OKP Ed25519
+// (EdDSA) keys. Keys for encryption only are skipped, other key types are an error.
+func ParseJWKS(data []byte) ([]*Key, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	keys := make([]*Key, 0, len(set.Keys))
+	for i, raw := range set.Keys {
+		if raw.Use == "enc" {
+			continue
+		}
+		key, err := raw.key()
+		if err != nil {
+			return nil, fmt.Errorf("JWKS key %d: %w", i, err)
+		}
+		if raw.Alg != "" && raw.Alg != key.Algorithm {
+			return nil, fmt.Errorf("JWKS key %d: alg %s doesn't match a %s key", i, raw.Alg, raw.Kty)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (k jwk) key() (*Key, error) {
+	switch k.Kty {
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil {
+			return nil, fmt.Errorf("invalid k: %w", err)
+		}
+		return NewHMACKey(k.Kid, secret)
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid n: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid e")
+		}
+		exponent := int(new(big.Int).SetBytes(e).Int64())
+		return NewPublicKey(k.Kid, &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exponent})
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x: %w", err)
+		}
+		return NewPublicKey(k.Kid, ed25519.PublicKey(x))
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
@@ -78,12 +78,23 @@ func (aw *AsyncWriter) Close() error {
 type AppLogger struct {
 	cfg    *config.Config
 	logger zerolog.Logger
+	out    io.Writer
 	writer *AsyncWriter
 }
 
-// NewAppLogger creates new AppLogger with config
+// NewAppLogger creates new AppLogger with config, writing to stdout
 func NewAppLogger(cfg *config.Config) *AppLogger {
-	return &AppLogger{cfg: cfg}
+	return NewAppLoggerTo(cfg, os.Stdout)
+}
+
+// NewAppLoggerTo creates new AppLogger writing to out, for commands whose stdout carries data
+func NewAppLoggerTo(cfg *config.Config, out io.Writer) *AppLogger {
+	return &AppLogger{cfg: cfg, out: out}
+}
+
+// Output is where the logs go, and the progress of the process with them
+func (l *AppLogger) Output() io.Writer {
+	return l.out
 }
 
 func (l *AppLogger) InitLogger() {
@@ -95,7 +106,7 @@ func (l *AppLogger) InitLogger() {
 		fmt.Println("invalid log level:", err)
 	}
 
-	asyncWriter := NewAsyncWriter(os.Stdout, 1000)
+	asyncWriter := NewAsyncWriter(l.out, 1000)
 	l.writer = asyncWriter
 
 	logger := zerolog.New(asyncWriter).
@@ -183,6 +194,7 @@ func (l *AppLogger) WithScope(instance any) *AppLogger {
 	return &AppLogger{
 		cfg:    l.cfg,
 		logger: newLogger,
+		out:    l.out,
 		writer: l.writer,
 	}
 }
@@ -193,6 +205,7 @@ func (l *AppLogger) With(key string, value interface{}) *AppLogger {
 	return &AppLogger{
 		cfg:    l.cfg,
 		logger: newLogger,
+		out:    l.out,
 		writer: l.writer,
 	}
 }
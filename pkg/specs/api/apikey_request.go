@@ -0,0 +1,21 @@
+package api
+
+import "time"
+
+type APIKeyIssueRequest struct {
+	Name   string   `json:"name" validate:"required,max=128"`
+	Scopes []string `json:"scopes"`
+	// ExpiresAt defaults to now plus the configured TTL.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type APIKeyListRequest struct {
+	IncludeRevoked bool `query:"include_revoked"`
+	Page           int  `query:"page"`
+	Size           int  `query:"size"`
+}
+
+// APIKeyIDAwareRequest names a key by its prefix, the public part of the key.
+type APIKeyIDAwareRequest struct {
+	ID string `param:"id"`
+}
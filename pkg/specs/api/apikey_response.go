@@ -0,0 +1,22 @@
+package api
+
+import "time"
+
+type APIKeyItemResponse struct {
+	// ID is the prefix of the key, the part after "gsk_".
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	ReplacedBy string     `json:"replaced_by,omitempty"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APIKeyIssueResponse carries the key itself, shown this once and never again.
+type APIKeyIssueResponse struct {
+	APIKeyItemResponse
+	Key string `json:"key"`
+}
@@ -0,0 +1,10 @@
+package api
+
+type AuditLogListRequest struct {
+	Entity   string `query:"entity"`
+	EntityID string `query:"entity_id"`
+	Actor    string `query:"actor"`
+	Action   string `query:"action" enum:"audit.Action"`
+	Page     int    `query:"page"`
+	Size     int    `query:"size"`
+}
@@ -0,0 +1,14 @@
+package api
+
+import "time"
+
+type AuditLogItemResponse struct {
+	ID        uint64      `json:"id"`
+	Entity    string      `json:"entity"`
+	EntityID  string      `json:"entity_id"`
+	Action    string      `json:"action" enum:"audit.Action"`
+	Actor     string      `json:"actor"`
+	Before    *JSONObject `json:"before,omitempty"`
+	After     *JSONObject `json:"after,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+}
@@ -0,0 +1,14 @@
+package api
+
+type DatabaseDiagnosticsRequest struct {
+	// LongRunningThreshold is a Go duration such as "5s"; queries running longer are listed.
+	LongRunningThreshold string `query:"long_running_threshold"`
+}
+
+type SlowQueriesRequest struct {
+	// Limit is the number of fingerprints returned, 10 by default and at most 100.
+	Limit int    `query:"limit"`
+	Sort  string `query:"sort" validate:"omitempty,oneof=total p95 max count"`
+	// IncludeFast also lists fingerprints that had no slow execution in the window.
+	IncludeFast bool `query:"include_fast"`
+}
@@ -0,0 +1,55 @@
+package api
+
+import "time"
+
+type DatabaseDiagnosticsResponse struct {
+	Pools       []PoolStatsResponse       `json:"pools"`
+	Sessions    []DatabaseSessionResponse `json:"sessions"`
+	LongRunning []DatabaseSessionResponse `json:"long_running"`
+}
+
+type SlowQueriesResponse struct {
+	SlowThresholdMs float64             `json:"slow_threshold_ms"`
+	WindowSeconds   int64               `json:"window_seconds"`
+	Queries         []SlowQueryResponse `json:"queries"`
+}
+
+type SlowQueryResponse struct {
+	Fingerprint string    `json:"fingerprint"`
+	Count       int64     `json:"count"`
+	SlowCount   int64     `json:"slow_count"`
+	Rows        int64     `json:"rows"`
+	TotalMs     float64   `json:"total_ms"`
+	MeanMs      float64   `json:"mean_ms"`
+	P50Ms       float64   `json:"p50_ms"`
+	P95Ms       float64   `json:"p95_ms"`
+	MaxMs       float64   `json:"max_ms"`
+	LastSeen    time.Time `json:"last_seen"`
+}
+
+type PoolStatsResponse struct {
+	Name              string `json:"name"`
+	MaxOpen           int    `json:"max_open"`
+	Open              int    `json:"open"`
+	InUse             int    `json:"in_use"`
+	Idle              int    `json:"idle"`
+	WaitCount         int64  `json:"wait_count"`
+	WaitDurationMs    int64  `json:"wait_duration_ms"`
+	MaxIdleClosed     int64  `json:"max_idle_closed"`
+	MaxIdleTimeClosed int64  `json:"max_idle_time_closed"`
+	MaxLifetimeClosed int64  `json:"max_lifetime_closed"`
+}
+
+type DatabaseSessionResponse struct {
+	PID           int64      `json:"pid"`
+	User          string     `json:"user"`
+	ClientAddr    string     `json:"client_addr,omitempty"`
+	State         string     `json:"state"`
+	WaitEventType string     `json:"wait_event_type,omitempty"`
+	WaitEvent     string     `json:"wait_event,omitempty"`
+	BackendStart  *time.Time `json:"backend_start,omitempty"`
+	XactStart     *time.Time `json:"xact_start,omitempty"`
+	QueryStart    *time.Time `json:"query_start,omitempty"`
+	DurationMs    int64      `json:"duration_ms"`
+	Query         string     `json:"query"`
+}
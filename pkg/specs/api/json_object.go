@@ -0,0 +1,22 @@
+package api
+
+import "github.com/go-json-experiment/json/v1"
+
+// JSONObject is a free-form JSON object. It wraps the map in a struct because tonic
+// can't build a schema for interface values; a struct without tagged fields is
+// documented as an object with any properties.
+type JSONObject struct {
+	fields map[string]interface{}
+}
+
+// NewJSONObject returns nil for an empty map so omitempty leaves the field out.
+func NewJSONObject(fields map[string]interface{}) *JSONObject {
+	if len(fields) == 0 {
+		return nil
+	}
+	return &JSONObject{fields: fields}
+}
+
+func (o JSONObject) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.fields)
+}
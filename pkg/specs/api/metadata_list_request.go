@@ -0,0 +1,13 @@
+package api
+
+import "time"
+
+// MetadataListRequest narrows the list to posts created in [CreatedFrom, CreatedTo);
+// either bound may be left out. Bounds let Postgres skip the monthly partitions outside
+// them. UserID lists another user's posts, which needs metadata:read:any; it defaults to
+// the caller.
+type MetadataListRequest struct {
+	UserID      int64     `query:"user_id"`
+	CreatedFrom time.Time `query:"created_from"`
+	CreatedTo   time.Time `query:"created_to"`
+}
@@ -0,0 +1,6 @@
+package api
+
+type MetadataUpdateRequest struct {
+	ID       string       `param:"id"`
+	MetaData MetaDataBody `json:"meta_data"`
+}
@@ -0,0 +1,16 @@
+package api
+
+type RoleListRequest struct{}
+
+type RoleAssignmentListRequest struct {
+	Subject string `query:"subject"`
+	Role    string `query:"role"`
+	Page    int    `query:"page"`
+	Size    int    `query:"size"`
+}
+
+// RoleAssignmentRequest names the subject, a user id or "apikey:<prefix>", and the role.
+type RoleAssignmentRequest struct {
+	Role    string `param:"role"`
+	Subject string `param:"subject"`
+}
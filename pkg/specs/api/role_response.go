@@ -0,0 +1,15 @@
+package api
+
+import "time"
+
+type RoleResponse struct {
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+}
+
+type RoleAssignmentResponse struct {
+	Subject   string    `json:"subject"`
+	Role      string    `json:"role"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}